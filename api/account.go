@@ -0,0 +1,213 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+// listAccounts returns a page of the accounts of the authenticated user
+func (server *Server) listAccounts(w http.ResponseWriter, r *http.Request) {
+	params, err := pageParams(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := server.store.ListAccountsPage(r.Context(), authPayload(r).Username, params)
+	if err != nil {
+		writeJSON(w, listErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAccountsPageResponse(page))
+}
+
+// listEntries returns a page of the entries of an account of the authenticated user, matching the search filters
+func (server *Server) listEntries(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	params := db.SearchEntriesParams{AccountID: account.ID}
+	params.PageParams, err = pageParams(r)
+	if err == nil {
+		params.Direction, params.MinAmount, params.MaxAmount, params.From, params.To, err = searchFilters(r, account.Currency)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := server.store.SearchEntries(r.Context(), params)
+	if err != nil {
+		writeJSON(w, listErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newEntriesPageResponse(page, account.Currency))
+}
+
+// listTransfers returns a page of the transfers from or to an account of the authenticated user, matching the search filters
+func (server *Server) listTransfers(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	params := db.SearchTransfersParams{
+		AccountID:   account.ID,
+		Reference:   r.URL.Query().Get("reference"),
+		Description: r.URL.Query().Get("description"),
+	}
+	if metadata := r.URL.Query().Get("metadata"); metadata != "" {
+		params.Metadata = json.RawMessage(metadata)
+	}
+	params.PageParams, err = pageParams(r)
+	if err == nil {
+		params.Direction, params.MinAmount, params.MaxAmount, params.From, params.To, err = searchFilters(r, account.Currency)
+	}
+	if err == nil {
+		params.CounterpartyID, err = int64Query(r, "counterparty_id")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	page, err := server.store.SearchTransfers(r.Context(), params)
+	if err != nil {
+		writeJSON(w, listErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTransfersPageResponse(page, account.Currency))
+}
+
+// heldAccount returns the account of the {id} path value and the role of the authenticated user, who must hold it
+func (server *Server) heldAccount(r *http.Request) (db.Account, string, int, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return db.Account{}, "", http.StatusBadRequest, fmt.Errorf("invalid account id %q", r.PathValue("id"))
+	}
+
+	return server.accountRole(r, id)
+}
+
+// accountRole returns the account and the role of the authenticated user, who must hold it
+func (server *Server) accountRole(r *http.Request, id int64) (db.Account, string, int, error) {
+	account, err := server.store.GetAccount(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return account, "", http.StatusNotFound, fmt.Errorf("account %d not found", id)
+	}
+	if err != nil {
+		return account, "", http.StatusInternalServerError, err
+	}
+
+	username := authPayload(r).Username
+	if account.Owner == username {
+		return account, db.RoleOwner, http.StatusOK, nil
+	}
+
+	role, err := server.store.AccountRole(r.Context(), id, username)
+	if errors.Is(err, db.ErrNotAccountHolder) {
+		return account, "", http.StatusForbidden, errors.New("account doesn't belong to the authenticated user")
+	}
+	if err != nil {
+		return account, "", http.StatusInternalServerError, err
+	}
+
+	return account, role.Role, http.StatusOK, nil
+}
+
+// pageParams reads the cursor, limit and order query parameters
+func pageParams(r *http.Request) (db.PageParams, error) {
+	query := r.URL.Query()
+	params := db.PageParams{
+		Cursor: query.Get("cursor"),
+		Order:  db.SortOrder(query.Get("order")),
+	}
+
+	if value := query.Get("limit"); value != "" {
+		limit, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return params, fmt.Errorf("invalid limit %q", value)
+		}
+		params.Limit = int32(limit)
+	}
+
+	return params, nil
+}
+
+// searchFilters reads the direction, min_amount, max_amount, from and to query parameters,
+// the amounts are decimals in the currency of the account and the dates are RFC 3339
+func searchFilters(r *http.Request, currency string) (direction db.Direction, minAmount, maxAmount int64, from, to time.Time, err error) {
+	direction = db.Direction(r.URL.Query().Get("direction"))
+
+	if minAmount, err = parseAmount(r.URL.Query().Get("min_amount"), currency); err != nil {
+		return
+	}
+	if maxAmount, err = parseAmount(r.URL.Query().Get("max_amount"), currency); err != nil {
+		return
+	}
+	if from, err = timeQuery(r, "from"); err != nil {
+		return
+	}
+	to, err = timeQuery(r, "to")
+	return
+}
+
+func int64Query(r *http.Request, key string) (int64, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	number, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return number, nil
+}
+
+func timeQuery(r *http.Request, key string) (time.Time, error) {
+	value := r.URL.Query().Get(key)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid %s %q", key, value)
+	}
+	return date, nil
+}
+
+// listErrorStatus returns the status of an error of a list or a search, the invalid params are a bad request
+func listErrorStatus(err error) int {
+	for _, invalid := range []error{
+		db.ErrInvalidCursor,
+		db.ErrInvalidPageSize,
+		db.ErrInvalidSortOrder,
+		db.ErrSearchNoAccount,
+		db.ErrInvalidDirection,
+		db.ErrInvalidAmountRange,
+		db.ErrInvalidDateRange,
+		db.ErrCounterpartyAccount,
+		db.ErrInvalidMetadata,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakePageStore returns canned pages and records the params it was called with
+type fakePageStore struct {
+	Store
+
+	accounts map[int64]db.Account
+	// roles are the roles of the holders other than the owner, by account and username
+	roles  map[int64]map[string]string
+	owner  string
+	params db.PageParams
+	search db.SearchTransfersParams
+}
+
+func (store *fakePageStore) GetAccount(_ context.Context, id int64) (db.Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (store *fakePageStore) AccountRole(_ context.Context, accountId int64, username string) (db.GetAccountRoleRow, error) {
+	role, ok := store.roles[accountId][username]
+	if !ok {
+		return db.GetAccountRoleRow{}, db.ErrNotAccountHolder
+	}
+	return db.GetAccountRoleRow{Role: role}, nil
+}
+
+func (store *fakePageStore) ListAccountsPage(_ context.Context, owner string, params db.PageParams) (db.AccountsPage, error) {
+	store.owner, store.params = owner, params
+	if params.Cursor == "bad" {
+		return db.AccountsPage{}, db.ErrInvalidCursor
+	}
+
+	var page db.AccountsPage
+	for _, account := range store.accounts {
+		if account.Owner == owner {
+			page.Accounts = append(page.Accounts, account)
+		}
+	}
+	page.NextCursor = "next"
+	return page, nil
+}
+
+func (store *fakePageStore) SearchEntries(_ context.Context, params db.SearchEntriesParams) (db.EntriesPage, error) {
+	store.params = params.PageParams
+	return db.EntriesPage{Entries: []db.Entry{{ID: 7, AccountID: params.AccountID, Amount: 10}}}, nil
+}
+
+func (store *fakePageStore) SearchTransfers(_ context.Context, params db.SearchTransfersParams) (db.TransfersPage, error) {
+	store.search = params
+	if params.MinAmount > params.MaxAmount && params.MaxAmount != 0 {
+		return db.TransfersPage{}, db.ErrInvalidAmountRange
+	}
+	return db.TransfersPage{}, nil
+}
+
+func TestListAccounts(t *testing.T) {
+	store := &fakePageStore{accounts: map[int64]db.Account{
+		1: {ID: 1, Owner: "alice", Currency: "USD"},
+		2: {ID: 2, Owner: "bob", Currency: "USD"},
+	}}
+	server := newTestServer(t, store, nil)
+	token := createTestToken(t, server, "alice")
+
+	testCases := []struct {
+		name   string
+		query  string
+		token  string
+		status int
+	}{
+		{name: "OK", query: "?limit=5&order=desc&cursor=abc", token: token, status: http.StatusOK},
+		{name: "NoAuthorization", status: http.StatusUnauthorized},
+		{name: "InvalidLimit", query: "?limit=many", token: token, status: http.StatusBadRequest},
+		{name: "InvalidCursor", query: "?cursor=bad", token: token, status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/accounts"+tc.query, nil)
+			if tc.token != "" {
+				request.Header.Set(authorizationHeaderKey, "Bearer "+tc.token)
+			}
+			recorder := httptest.NewRecorder()
+
+			server.Handler().ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	require.Equal(t, "alice", store.owner)
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts?limit=5&order=desc&cursor=abc", nil)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+
+	require.Equal(t, db.PageParams{Cursor: "abc", Limit: 5, Order: db.SortDesc}, store.params)
+
+	var page accountsPageResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+	require.Len(t, page.Accounts, 1)
+	require.Equal(t, int64(1), page.Accounts[0].ID)
+	require.Equal(t, page.Accounts[0].Currency, page.Accounts[0].Balance.Currency)
+	require.Equal(t, "next", page.NextCursor)
+}
+
+func TestListEntries(t *testing.T) {
+	store := &fakePageStore{
+		accounts: map[int64]db.Account{
+			1: {ID: 1, Owner: "alice", Currency: "USD"},
+			2: {ID: 2, Owner: "bob", Currency: "USD"},
+			4: {ID: 4, Owner: "carol", Currency: "USD"},
+		},
+		roles: map[int64]map[string]string{4: {"alice": db.RoleViewer}},
+	}
+	server := newTestServer(t, store, nil)
+	token := createTestToken(t, server, "alice")
+
+	testCases := []struct {
+		name   string
+		path   string
+		status int
+	}{
+		{name: "OK", path: "/accounts/1/entries", status: http.StatusOK},
+		{name: "OtherOwner", path: "/accounts/2/entries", status: http.StatusForbidden},
+		{name: "Viewer", path: "/accounts/4/entries", status: http.StatusOK},
+		{name: "NotFound", path: "/accounts/3/entries", status: http.StatusNotFound},
+		{name: "InvalidID", path: "/accounts/abc/entries", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+			recorder := httptest.NewRecorder()
+
+			server.Handler().ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+
+			if tc.status == http.StatusOK {
+				var page entriesPageResponse
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &page))
+				require.Len(t, page.Entries, 1)
+				require.Empty(t, page.NextCursor)
+			}
+		})
+	}
+}
+
+func TestListTransfersFilters(t *testing.T) {
+	store := &fakePageStore{accounts: map[int64]db.Account{
+		1: {ID: 1, Owner: "alice", Currency: "USD"},
+	}}
+	server := newTestServer(t, store, nil)
+	token := createTestToken(t, server, "alice")
+
+	testCases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{
+			name:   "OK",
+			query:  "?direction=outgoing&min_amount=10.50&max_amount=20&from=2023-01-01T00:00:00Z&to=2023-02-01T00:00:00Z&counterparty_id=2&reference=rent&limit=5",
+			status: http.StatusOK,
+		},
+		{name: "InvalidAmount", query: "?min_amount=ten", status: http.StatusBadRequest},
+		{name: "TooManyDecimals", query: "?min_amount=10.505", status: http.StatusBadRequest},
+		{name: "InvalidDate", query: "?from=yesterday", status: http.StatusBadRequest},
+		{name: "InvalidCounterparty", query: "?counterparty_id=x", status: http.StatusBadRequest},
+		{name: "InvalidRange", query: "?min_amount=20&max_amount=10", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/accounts/1/transfers"+tc.query, nil)
+			request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+			recorder := httptest.NewRecorder()
+
+			server.Handler().ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1/transfers"+testCases[0].query, nil)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+	server.Handler().ServeHTTP(httptest.NewRecorder(), request)
+
+	require.Equal(t, db.SearchTransfersParams{
+		AccountID:      1,
+		Direction:      db.DirectionOutgoing,
+		MinAmount:      1050,
+		MaxAmount:      2000,
+		From:           time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:             time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC),
+		CounterpartyID: 2,
+		Reference:      "rent",
+		PageParams:     db.PageParams{Limit: 5},
+	}, store.search)
+}
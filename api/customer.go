@@ -0,0 +1,186 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+type createCustomerRequest struct {
+	LegalName string `json:"legal_name"`
+	// DateOfBirth is a date like 1990-01-31
+	DateOfBirth string `json:"date_of_birth"`
+	Address     string `json:"address"`
+	NationalID  string `json:"national_id"`
+}
+
+// createCustomer creates a customer, pending KYC until its outcome is recorded
+func (server *Server) createCustomer(w http.ResponseWriter, r *http.Request) {
+	var request createCustomerRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.CreateCustomerParams{
+		LegalName:  strings.TrimSpace(request.LegalName),
+		Address:    strings.TrimSpace(request.Address),
+		NationalID: strings.TrimSpace(request.NationalID),
+	}
+	if arg.LegalName == "" || arg.Address == "" || arg.NationalID == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(errors.New("legal_name, address and national_id are required")))
+		return
+	}
+
+	arg.DateOfBirth, err = time.Parse(time.DateOnly, request.DateOfBirth)
+	if err != nil || arg.DateOfBirth.After(time.Now()) {
+		writeJSON(w, http.StatusBadRequest, errorResponse(fmt.Errorf("invalid date_of_birth %q", request.DateOfBirth)))
+		return
+	}
+
+	customer, err := server.store.CreateCustomer(r.Context(), arg)
+	if errors.Is(err, db.ErrCustomerExists) {
+		writeJSON(w, http.StatusConflict, errorResponse(err))
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, customer)
+}
+
+// getCustomer returns the customer of the {id} path value
+func (server *Server) getCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	customer, err := server.store.GetCustomer(r.Context(), id)
+	if err != nil {
+		writeJSON(w, customerErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customer)
+}
+
+type kycOutcomeRequest struct {
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+// recordKYCOutcome verifies or rejects the customer of the {id} path value, the admin is recorded as reviewer
+func (server *Server) recordKYCOutcome(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var request kycOutcomeRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	customer, err := server.store.RecordKYCOutcome(r.Context(), db.RecordKYCOutcomeParams{
+		CustomerID: id,
+		Status:     request.Status,
+		Reason:     request.Reason,
+		Reviewer:   authPayload(r).Username,
+	})
+	if err != nil {
+		writeJSON(w, customerErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, customer)
+}
+
+type accountCustomerRequest struct {
+	// CustomerID is null to unlink the account
+	CustomerID *int64 `json:"customer_id"`
+}
+
+// setAccountCustomer links the account of the {id} path value to a customer
+func (server *Server) setAccountCustomer(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var request accountCustomerRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.SetAccountCustomerParams{ID: id}
+	if request.CustomerID != nil {
+		arg.CustomerID = sql.NullInt64{Int64: *request.CustomerID, Valid: true}
+
+		_, err = server.store.GetCustomer(r.Context(), *request.CustomerID)
+		if err != nil {
+			writeJSON(w, customerErrorStatus(err), errorResponse(err))
+			return
+		}
+	}
+
+	account, err := server.store.SetAccountCustomer(r.Context(), arg)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusNotFound, errorResponse(fmt.Errorf("account %d not found", id)))
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAccountResponse(account))
+}
+
+// pathID reads the {id} path value
+func pathID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+// readJSON decodes the request body, the unknown fields are rejected
+func readJSON(r *http.Request, body interface{}) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(body); err != nil {
+		return fmt.Errorf("invalid request body: %w", err)
+	}
+	return nil
+}
+
+func customerErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrInvalidKYCOutcome), errors.Is(err, db.ErrNoKYCRejectReason), errors.Is(err, db.ErrNoKYCReviewer):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
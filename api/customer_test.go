@@ -0,0 +1,132 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeCustomerStore keeps the customers in memory
+type fakeCustomerStore struct {
+	Store
+
+	customers map[int64]db.Customer
+	accounts  map[int64]db.Account
+}
+
+func (store *fakeCustomerStore) CreateCustomer(_ context.Context, arg db.CreateCustomerParams) (db.Customer, error) {
+	for _, customer := range store.customers {
+		if customer.NationalID == arg.NationalID {
+			return db.Customer{}, db.ErrCustomerExists
+		}
+	}
+
+	customer := db.Customer{
+		ID:          int64(len(store.customers) + 1),
+		LegalName:   arg.LegalName,
+		DateOfBirth: arg.DateOfBirth,
+		Address:     arg.Address,
+		NationalID:  arg.NationalID,
+		KycStatus:   db.KYCPending,
+	}
+	store.customers[customer.ID] = customer
+	return customer, nil
+}
+
+func (store *fakeCustomerStore) GetCustomer(_ context.Context, id int64) (db.Customer, error) {
+	customer, ok := store.customers[id]
+	if !ok {
+		return db.Customer{}, sql.ErrNoRows
+	}
+	return customer, nil
+}
+
+func (store *fakeCustomerStore) RecordKYCOutcome(ctx context.Context, params db.RecordKYCOutcomeParams) (db.Customer, error) {
+	if params.Status != db.KYCVerified && params.Status != db.KYCRejected {
+		return db.Customer{}, db.ErrInvalidKYCOutcome
+	}
+
+	customer, err := store.GetCustomer(ctx, params.CustomerID)
+	if err != nil {
+		return customer, err
+	}
+
+	customer.KycStatus = params.Status
+	customer.KycReviewedBy = params.Reviewer
+	customer.KycRejectionReason = params.Reason
+	store.customers[customer.ID] = customer
+	return customer, nil
+}
+
+func (store *fakeCustomerStore) SetAccountCustomer(_ context.Context, arg db.SetAccountCustomerParams) (db.Account, error) {
+	account, ok := store.accounts[arg.ID]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+
+	account.CustomerID = arg.CustomerID
+	store.accounts[arg.ID] = account
+	return account, nil
+}
+
+func TestCustomerAdmin(t *testing.T) {
+	store := &fakeCustomerStore{
+		customers: map[int64]db.Customer{},
+		accounts:  map[int64]db.Account{1: {ID: 1, Owner: "alice"}},
+	}
+	server := newTestServer(t, store, nil)
+	adminToken := createTestToken(t, server, "admin")
+	userToken := createTestToken(t, server, "alice")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	customerBody := `{"legal_name": "Alice Smith", "date_of_birth": "1990-01-31", "address": "1 Main St", "national_id": "AB123"}`
+
+	recorder := send(http.MethodPost, "/admin/customers", userToken, customerBody)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = send(http.MethodPost, "/admin/customers", adminToken, customerBody)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var customer db.Customer
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &customer))
+	require.Equal(t, "Alice Smith", customer.LegalName)
+	require.Equal(t, db.KYCPending, customer.KycStatus)
+
+	recorder = send(http.MethodPost, "/admin/customers", adminToken, customerBody)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = send(http.MethodPost, "/admin/customers", adminToken, `{"legal_name": "Bob", "date_of_birth": "31/01/1990", "address": "2 Main St", "national_id": "CD456"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodPost, "/admin/customers/1/kyc", adminToken, `{"status": "approved"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodPost, "/admin/customers/1/kyc", adminToken, `{"status": "verified"}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, db.KYCVerified, store.customers[1].KycStatus)
+	require.Equal(t, "admin", store.customers[1].KycReviewedBy)
+
+	recorder = send(http.MethodGet, "/admin/customers/2", adminToken, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = send(http.MethodPut, "/admin/accounts/1/customer", adminToken, `{"customer_id": 2}`)
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = send(http.MethodPut, "/admin/accounts/1/customer", adminToken, `{"customer_id": 1}`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, sql.NullInt64{Int64: 1, Valid: true}, store.accounts[1].CustomerID)
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+// addFXRate stores the rate of a currency pair, like {"base_currency": "EUR", "quote_currency": "USD", "rate": "1.0845"}.
+// It's effective from now, or from the effective_from of the request.
+func (server *Server) addFXRate(w http.ResponseWriter, r *http.Request) {
+	var request db.CreateFXRateParams
+	err := readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	rate, err := server.store.AddFXRate(r.Context(), request)
+	if err != nil {
+		writeJSON(w, fxErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, rate)
+}
+
+// getFXRate returns the rate of the from currency in the to currency of the query,
+// valid at the at time of the query, like ?from=EUR&to=USD&at=2024-01-02T15:00:00Z, or now
+func (server *Server) getFXRate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, to := query.Get("from"), query.Get("to")
+	if from == "" || to == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(errors.New("from and to are required")))
+		return
+	}
+
+	at := time.Now()
+	if value := query.Get("at"); value != "" {
+		var err error
+		at, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse(fmt.Errorf("invalid at %q", value)))
+			return
+		}
+	}
+
+	quote, err := server.store.FXRate(r.Context(), from, to, at)
+	if err != nil {
+		writeJSON(w, fxErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, quote)
+}
+
+func fxErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, money.ErrUnknownCurrency),
+		errors.Is(err, money.ErrInvalidRate),
+		errors.Is(err, db.ErrSameFXCurrencies),
+		errors.Is(err, db.ErrInvalidFXSource):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrNoFXRate):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrStaleFXRate):
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeFXStore quotes EUR in USD at 1.1 and records the added rates
+type fakeFXStore struct {
+	Store
+
+	added []db.CreateFXRateParams
+	at    time.Time
+}
+
+func (store *fakeFXStore) AddFXRate(_ context.Context, arg db.CreateFXRateParams) (db.FxRate, error) {
+	if arg.BaseCurrency == arg.QuoteCurrency {
+		return db.FxRate{}, db.ErrSameFXCurrencies
+	}
+	store.added = append(store.added, arg)
+	return db.FxRate{BaseCurrency: arg.BaseCurrency, QuoteCurrency: arg.QuoteCurrency, Rate: arg.Rate}, nil
+}
+
+func (store *fakeFXStore) FXRate(_ context.Context, from, to string, at time.Time) (db.FXQuote, error) {
+	store.at = at
+	if from != "EUR" || to != "USD" {
+		return db.FXQuote{}, db.ErrNoFXRate
+	}
+	return db.FXQuote{From: from, To: to, At: at, Rate: "1.1", Derivation: db.FXDirect}, nil
+}
+
+func TestFXRates(t *testing.T) {
+	store := &fakeFXStore{}
+	server := newTestServer(t, store, nil)
+	adminToken := createTestToken(t, server, "admin")
+	userToken := createTestToken(t, server, "alice")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	rateBody := `{"base_currency": "EUR", "quote_currency": "USD", "rate": "1.1", "effective_from": "2024-01-02T00:00:00Z"}`
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{name: "AddNotAdmin", method: http.MethodPost, path: "/admin/fx-rates", token: userToken, body: rateBody, status: http.StatusForbidden},
+		{name: "Add", method: http.MethodPost, path: "/admin/fx-rates", token: adminToken, body: rateBody, status: http.StatusCreated},
+		{
+			name:   "AddSameCurrencies",
+			method: http.MethodPost,
+			path:   "/admin/fx-rates",
+			token:  adminToken,
+			body:   `{"base_currency": "EUR", "quote_currency": "EUR", "rate": "1"}`,
+			status: http.StatusBadRequest,
+		},
+		{name: "Get", method: http.MethodGet, path: "/fx-rates?from=EUR&to=USD", token: userToken, status: http.StatusOK},
+		{name: "GetMissingCurrency", method: http.MethodGet, path: "/fx-rates?from=EUR", token: userToken, status: http.StatusBadRequest},
+		{name: "GetInvalidTime", method: http.MethodGet, path: "/fx-rates?from=EUR&to=USD&at=today", token: userToken, status: http.StatusBadRequest},
+		{name: "GetNoRate", method: http.MethodGet, path: "/fx-rates?from=USD&to=GBP", token: userToken, status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := send(tc.method, tc.path, tc.token, tc.body)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	require.Len(t, store.added, 1)
+	require.Equal(t, time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), store.added[0].EffectiveFrom)
+
+	recorder := send(http.MethodGet, "/fx-rates?from=EUR&to=USD&at=2024-01-02T15:00:00Z", userToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC), store.at)
+
+	var quote db.FXQuote
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &quote))
+	require.Equal(t, "1.1", quote.Rate)
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+// readinessTimeout bounds the database checks of a readiness probe
+const readinessTimeout = 2 * time.Second
+
+// healthz is the liveness probe: the process serves requests
+func (server *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// readyz is the readiness probe: the database answers and its migrations are at db.SchemaVersion.
+// A server shutting down is not ready, so the load balancer stops sending it requests.
+func (server *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	if server.isShuttingDown() {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse(errors.New("the server is shutting down")))
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessTimeout)
+	defer cancel()
+
+	if err := server.store.CheckReady(ctx); err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "ready", "schema_version": db.SchemaVersion})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+	"simple_bank/stream"
+)
+
+// fakeHealthStore is ready unless err is set, and waits for release when it's not nil
+type fakeHealthStore struct {
+	Store
+
+	err      error
+	checking chan struct{}
+	release  chan struct{}
+}
+
+func (store *fakeHealthStore) CheckReady(ctx context.Context) error {
+	if store.release != nil {
+		close(store.checking)
+		<-store.release
+	}
+	return store.err
+}
+
+func TestProbes(t *testing.T) {
+	store := &fakeHealthStore{}
+	server := newTestServer(t, store, nil)
+
+	probe := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder
+	}
+
+	require.Equal(t, http.StatusOK, probe("/healthz").Code)
+
+	recorder := probe("/readyz")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, fmt.Sprintf(`{"status": "ready", "schema_version": %d}`, db.SchemaVersion), recorder.Body.String())
+
+	store.err = fmt.Errorf("%w: 14, expected 15", db.ErrSchemaVersion)
+	recorder = probe("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "expected 15")
+
+	store.err = nil
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.Equal(t, http.StatusServiceUnavailable, probe("/readyz").Code)
+	require.Equal(t, http.StatusOK, probe("/healthz").Code)
+}
+
+func TestShutdownDrainsRequests(t *testing.T) {
+	store := &fakeHealthStore{checking: make(chan struct{}), release: make(chan struct{})}
+	server := newTestServer(t, store, nil)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	started := make(chan error, 1)
+	go func() {
+		started <- server.Start(address)
+	}()
+
+	response := make(chan *http.Response, 1)
+	go func() {
+		for {
+			resp, err := http.Get("http://" + address + "/readyz")
+			if err == nil {
+				response <- resp
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+	<-store.checking
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	select {
+	case <-shutdown:
+		t.Fatal("Shutdown returned with a request in flight")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(store.release)
+	resp := <-response
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, resp.Body.Close())
+	require.NoError(t, <-shutdown)
+	require.NoError(t, <-started)
+
+	_, err = http.Get("http://" + address + "/healthz")
+	require.Error(t, err)
+}
+
+func TestShutdownEndsEventStreams(t *testing.T) {
+	server := newTestServer(t, newFakeStreamStore(), stream.NewBroker())
+	token := createTestToken(t, server, "alice")
+
+	request := httptest.NewRequest(http.MethodGet, "/events", nil)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+
+	done := make(chan struct{})
+	go func() {
+		server.Handler().ServeHTTP(httptest.NewRecorder(), request)
+		close(done)
+	}()
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the event stream is still open")
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	db "simple_bank/db/sqlc"
+)
+
+// listHolders returns the holders of an account of the authenticated user, besides its owner
+func (server *Server) listHolders(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	holders, err := server.store.ListAccountHolders(r.Context(), account.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := []accountHolderResponse{}
+	for _, holder := range holders {
+		response = append(response, newAccountHolderResponse(holder, account.Currency))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+type addHolderRequest struct {
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	// TransferLimit is the maximum amount of a transfer of a signatory, in the currency of the account
+	TransferLimit json.Number `json:"transfer_limit"`
+}
+
+// addHolder invites a user to hold an account of the authenticated user as co-owner, viewer or signatory
+func (server *Server) addHolder(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	var request addHolderRequest
+	err = readJSON(r, &request)
+	if err == nil && request.Username == "" {
+		err = errors.New("username is required")
+	}
+
+	params := db.AddAccountHolderParams{
+		AccountID: account.ID,
+		Username:  request.Username,
+		Role:      request.Role,
+	}
+	if err == nil {
+		params.TransferLimit, err = parseAmount(request.TransferLimit.String(), account.Currency)
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	holder, err := server.store.AddAccountHolder(r.Context(), params)
+	if err != nil {
+		writeJSON(w, holderErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAccountHolderResponse(holder, account.Currency))
+}
+
+// removeHolder removes the {username} holder of an account, a holder can remove itself
+func (server *Server) removeHolder(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	err = server.store.RemoveAccountHolder(r.Context(), account.ID, r.PathValue("username"))
+	if err != nil {
+		writeJSON(w, holderErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func holderErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, db.ErrInvalidHolderRole), errors.Is(err, db.ErrInvalidTransferLimit):
+		return http.StatusBadRequest
+	case errors.Is(err, db.ErrHolderNotAllowed):
+		return http.StatusForbidden
+	case errors.Is(err, db.ErrNotAccountHolder):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrHolderExists):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
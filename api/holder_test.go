@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeHolderStore keeps the holders in the roles of the page store
+type fakeHolderStore struct {
+	fakePageStore
+}
+
+func (store *fakeHolderStore) ListAccountHolders(_ context.Context, accountId int64) ([]db.AccountHolder, error) {
+	var holders []db.AccountHolder
+	for username, role := range store.roles[accountId] {
+		holders = append(holders, db.AccountHolder{AccountID: accountId, Username: username, Role: role})
+	}
+	return holders, nil
+}
+
+func (store *fakeHolderStore) AddAccountHolder(ctx context.Context, params db.AddAccountHolderParams) (db.AccountHolder, error) {
+	if params.Role != db.RoleCoOwner && params.Role != db.RoleViewer && params.Role != db.RoleSignatory {
+		return db.AccountHolder{}, db.ErrInvalidHolderRole
+	}
+	if db.ActorFromContext(ctx) != store.accounts[params.AccountID].Owner {
+		return db.AccountHolder{}, db.ErrHolderNotAllowed
+	}
+	if _, ok := store.roles[params.AccountID][params.Username]; ok {
+		return db.AccountHolder{}, db.ErrHolderExists
+	}
+
+	store.roles[params.AccountID][params.Username] = params.Role
+	return db.AccountHolder{AccountID: params.AccountID, Username: params.Username, Role: params.Role}, nil
+}
+
+func (store *fakeHolderStore) RemoveAccountHolder(_ context.Context, accountId int64, username string) error {
+	if _, ok := store.roles[accountId][username]; !ok {
+		return db.ErrNotAccountHolder
+	}
+
+	delete(store.roles[accountId], username)
+	return nil
+}
+
+func TestAccountHolders(t *testing.T) {
+	store := &fakeHolderStore{fakePageStore{
+		accounts: map[int64]db.Account{1: {ID: 1, Owner: "alice", Currency: "USD"}},
+		roles:    map[int64]map[string]string{1: {}},
+	}}
+	server := newTestServer(t, store, nil)
+	aliceToken := createTestToken(t, server, "alice")
+	bobToken := createTestToken(t, server, "bob")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send(http.MethodGet, "/accounts/1/holders", bobToken, "")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = send(http.MethodPost, "/accounts/1/holders", aliceToken, `{"username": "bob", "role": "admin"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodPost, "/accounts/1/holders", aliceToken, `{"username": "bob", "role": "viewer"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	recorder = send(http.MethodPost, "/accounts/1/holders", aliceToken, `{"username": "bob", "role": "viewer"}`)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	// bob can read the account as viewer, but can't invite
+	recorder = send(http.MethodGet, "/accounts/1/holders", bobToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var holders []db.AccountHolder
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &holders))
+	require.Len(t, holders, 1)
+	require.Equal(t, db.RoleViewer, holders[0].Role)
+
+	recorder = send(http.MethodPost, "/accounts/1/holders", bobToken, `{"username": "carol", "role": "viewer"}`)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = send(http.MethodDelete, "/accounts/1/holders/bob", aliceToken, "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+
+	recorder = send(http.MethodDelete, "/accounts/1/holders/bob", aliceToken, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = send(http.MethodGet, "/accounts/1/holders", bobToken, "")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"simple_bank/internal/httputil"
+	"simple_bank/logging"
+)
+
+const (
+	requestIDHeader = "X-Request-ID"
+	// maxRequestIDLength bounds the request IDs given by the clients, longer ones are replaced
+	maxRequestIDLength = 64
+)
+
+// logMiddleware gives every request an ID, from its X-Request-ID header or a new one, returns it in the response
+// and logs the request once served. The records logged with the context of the request carry its ID.
+func (server *Server) logMiddleware(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		requestID := r.Header.Get(requestIDHeader)
+		if requestID == "" || len(requestID) > maxRequestIDLength {
+			requestID = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, requestID)
+
+		info := &requestInfo{}
+		ctx := logging.WithRequestID(r.Context(), requestID)
+		ctx = context.WithValue(ctx, requestInfoKey, info)
+		recorder := httputil.NewStatusRecorder(w)
+		next(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		if recorder.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		// the query is left out, it can have an access token
+		server.logger.LogAttrs(ctx, level, "request",
+			slog.String("method", r.Method),
+			slog.String("route", route),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.Status()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("username", info.username),
+		)
+	}
+}
+
+// requestInfo is filled while the request is served, for its log
+type requestInfo struct {
+	username string
+}
+
+// setRequestUsername records the authenticated user of the request for its log
+func setRequestUsername(r *http.Request, username string) {
+	if info, ok := r.Context().Value(requestInfoKey).(*requestInfo); ok {
+		info.username = username
+	}
+}
+
+func newRequestID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
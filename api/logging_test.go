@@ -0,0 +1,49 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+	"simple_bank/logging"
+	"simple_bank/util"
+)
+
+func TestRequestLog(t *testing.T) {
+	var out bytes.Buffer
+	store := &fakePageStore{accounts: map[int64]db.Account{1: {ID: 1, Owner: "alice", Currency: "USD"}}}
+	config := util.Config{TokenSymmetricKey: util.RandomString(32)}
+
+	server, err := NewServer(config, store, nil, WithLogger(logging.New(&out, slog.LevelInfo)))
+	require.NoError(t, err)
+	token := createTestToken(t, server, "bob")
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1/entries", nil)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+	request.Header.Set(requestIDHeader, "req-42")
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+	require.Equal(t, "req-42", recorder.Header().Get(requestIDHeader))
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	require.Equal(t, "request", record["msg"])
+	require.Equal(t, "req-42", record["request_id"])
+	require.Equal(t, "GET", record["method"])
+	require.Equal(t, "/accounts/{id}/entries", record["route"])
+	require.Equal(t, "/accounts/1/entries", record["path"])
+	require.Equal(t, float64(recorder.Code), record["status"])
+	require.Equal(t, "bob", record["username"])
+	require.NotContains(t, out.String(), token)
+
+	out.Reset()
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/accounts", nil))
+	require.Len(t, recorder.Header().Get(requestIDHeader), 32)
+	require.Contains(t, out.String(), recorder.Header().Get(requestIDHeader))
+}
@@ -0,0 +1,12 @@
+package api
+
+import (
+	"os"
+	"testing"
+
+	"simple_bank/util"
+)
+
+func TestMain(m *testing.M) {
+	os.Exit(util.RunTests(m))
+}
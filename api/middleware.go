@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+)
+
+const (
+	authorizationHeaderKey  = "Authorization"
+	authorizationTypeBearer = "bearer"
+	// accessTokenQueryKey is only accepted by streamAuthMiddleware, because browsers can't set headers on an EventSource.
+	// Anywhere else the token would end up in the access logs and the Referer headers.
+	accessTokenQueryKey = "access_token"
+)
+
+type contextKey int
+
+const (
+	authorizationPayloadKey contextKey = iota
+	requestInfoKey
+)
+
+// authMiddleware verifies the bearer token and adds its payload to the request context,
+// the mutations made by the request are audited as made by the token owner
+func (server *Server) authMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return server.authenticate(next, false)
+}
+
+// streamAuthMiddleware is authMiddleware also accepting the token in the access_token query parameter
+func (server *Server) streamAuthMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return server.authenticate(next, true)
+}
+
+func (server *Server) authenticate(next http.HandlerFunc, allowQueryToken bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		accessToken, err := accessTokenFromRequest(r, allowQueryToken)
+		if err != nil {
+			writeJSON(w, http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		payload, err := server.tokenMaker.VerifyToken(accessToken)
+		if err != nil {
+			writeJSON(w, http.StatusUnauthorized, errorResponse(err))
+			return
+		}
+
+		setRequestUsername(r, payload.Username)
+		ctx := context.WithValue(r.Context(), authorizationPayloadKey, payload)
+		ctx = db.WithActor(ctx, payload.Username)
+		next(w, r.WithContext(ctx))
+	}
+}
+
+func accessTokenFromRequest(r *http.Request, allowQueryToken bool) (string, error) {
+	authorizationHeader := r.Header.Get(authorizationHeaderKey)
+	if authorizationHeader == "" {
+		if accessToken := r.URL.Query().Get(accessTokenQueryKey); allowQueryToken && accessToken != "" {
+			return accessToken, nil
+		}
+		return "", errors.New("authorization header is not provided")
+	}
+
+	fields := strings.Fields(authorizationHeader)
+	if len(fields) != 2 {
+		return "", errors.New("invalid authorization header format")
+	}
+
+	if strings.ToLower(fields[0]) != authorizationTypeBearer {
+		return "", errors.New("unsupported authorization type " + fields[0])
+	}
+
+	return fields[1], nil
+}
+
+// adminMiddleware only lets the users of the AdminUsernames config through, it must be wrapped by authMiddleware
+func (server *Server) adminMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !slices.Contains(server.config.AdminUsernames, authPayload(r).Username) {
+			writeJSON(w, http.StatusForbidden, errorResponse(errors.New("admin access is required")))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// authPayload returns the token payload added by authMiddleware
+func authPayload(r *http.Request) *token.Payload {
+	return r.Context().Value(authorizationPayloadKey).(*token.Payload)
+}
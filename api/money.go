@@ -0,0 +1,244 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+// The responses replace the amounts in minor units of the db models by money,
+// like {"amount": "12.34", "currency": "EUR"}
+
+type accountResponse struct {
+	db.Account
+	Balance           money.Money  `json:"balance"`
+	ApprovalThreshold *money.Money `json:"approval_threshold"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	response := accountResponse{
+		Account: account,
+		Balance: money.Money{Amount: account.Balance, Currency: account.Currency},
+	}
+	if account.ApprovalThreshold.Valid {
+		response.ApprovalThreshold = &money.Money{Amount: account.ApprovalThreshold.Int64, Currency: account.Currency}
+	}
+	return response
+}
+
+type accountsPageResponse struct {
+	Accounts   []accountResponse `json:"accounts"`
+	NextCursor string            `json:"next_cursor"`
+}
+
+func newAccountsPageResponse(page db.AccountsPage) accountsPageResponse {
+	response := accountsPageResponse{Accounts: []accountResponse{}, NextCursor: page.NextCursor}
+	for _, account := range page.Accounts {
+		response.Accounts = append(response.Accounts, newAccountResponse(account))
+	}
+	return response
+}
+
+type entryResponse struct {
+	db.Entry
+	Amount money.Money `json:"amount"`
+}
+
+func newEntryResponse(entry db.Entry, currency string) entryResponse {
+	return entryResponse{Entry: entry, Amount: money.Money{Amount: entry.Amount, Currency: currency}}
+}
+
+type entriesPageResponse struct {
+	Entries    []entryResponse `json:"entries"`
+	NextCursor string          `json:"next_cursor"`
+}
+
+func newEntriesPageResponse(page db.EntriesPage, currency string) entriesPageResponse {
+	response := entriesPageResponse{Entries: []entryResponse{}, NextCursor: page.NextCursor}
+	for _, entry := range page.Entries {
+		response.Entries = append(response.Entries, newEntryResponse(entry, currency))
+	}
+	return response
+}
+
+type transferResponse struct {
+	db.Transfer
+	Amount money.Money `json:"amount"`
+	Fee    money.Money `json:"fee"`
+}
+
+func newTransferResponse(transfer db.Transfer, currency string) transferResponse {
+	return transferResponse{
+		Transfer: transfer,
+		Amount:   money.Money{Amount: transfer.Amount, Currency: currency},
+		Fee:      money.Money{Amount: transfer.Fee, Currency: currency},
+	}
+}
+
+type transfersPageResponse struct {
+	Transfers  []transferResponse `json:"transfers"`
+	NextCursor string             `json:"next_cursor"`
+}
+
+func newTransfersPageResponse(page db.TransfersPage, currency string) transfersPageResponse {
+	response := transfersPageResponse{Transfers: []transferResponse{}, NextCursor: page.NextCursor}
+	for _, transfer := range page.Transfers {
+		response.Transfers = append(response.Transfers, newTransferResponse(transfer, currency))
+	}
+	return response
+}
+
+// transferResultResponse is the side of the sender of a transfer, the receiver account stays private
+type transferResultResponse struct {
+	Transfer    transferResponse `json:"transfer"`
+	FromAccount accountResponse  `json:"from_account"`
+	FromEntry   entryResponse    `json:"from_entry"`
+	FeeEntry    *entryResponse   `json:"fee_entry,omitempty"`
+}
+
+func newTransferResultResponse(result db.TransferTxResult) transferResultResponse {
+	currency := result.FromAccount.Currency
+	response := transferResultResponse{
+		Transfer:    newTransferResponse(result.Transfer, currency),
+		FromAccount: newAccountResponse(result.FromAccount),
+		FromEntry:   newEntryResponse(result.FromEntry, currency),
+	}
+	if result.FeeEntry.ID != 0 {
+		feeEntry := newEntryResponse(result.FeeEntry, currency)
+		response.FeeEntry = &feeEntry
+	}
+	return response
+}
+
+type transferRequestResponse struct {
+	db.TransferRequest
+	Amount money.Money `json:"amount"`
+}
+
+func newTransferRequestResponse(request db.TransferRequest, currency string) transferRequestResponse {
+	return transferRequestResponse{TransferRequest: request, Amount: money.Money{Amount: request.Amount, Currency: currency}}
+}
+
+type accountHolderResponse struct {
+	db.AccountHolder
+	TransferLimit *money.Money `json:"transfer_limit"`
+}
+
+func newAccountHolderResponse(holder db.AccountHolder, currency string) accountHolderResponse {
+	response := accountHolderResponse{AccountHolder: holder}
+	if holder.TransferLimit.Valid {
+		response.TransferLimit = &money.Money{Amount: holder.TransferLimit.Int64, Currency: currency}
+	}
+	return response
+}
+
+type statementLineResponse struct {
+	db.StatementLine
+	Amount  money.Money `json:"amount"`
+	Balance money.Money `json:"balance"`
+}
+
+type statementResponse struct {
+	db.Statement
+	Account        accountResponse         `json:"account"`
+	OpeningBalance money.Money             `json:"opening_balance"`
+	ClosingBalance money.Money             `json:"closing_balance"`
+	Lines          []statementLineResponse `json:"lines"`
+}
+
+func newStatementResponse(statement db.Statement) statementResponse {
+	currency := statement.Account.Currency
+	response := statementResponse{
+		Statement:      statement,
+		Account:        newAccountResponse(statement.Account),
+		OpeningBalance: money.Money{Amount: statement.OpeningBalance, Currency: currency},
+		ClosingBalance: money.Money{Amount: statement.ClosingBalance, Currency: currency},
+		Lines:          []statementLineResponse{},
+	}
+	for _, line := range statement.Lines {
+		response.Lines = append(response.Lines, statementLineResponse{
+			StatementLine: line,
+			Amount:        money.Money{Amount: line.Amount, Currency: currency},
+			Balance:       money.Money{Amount: line.Balance, Currency: currency},
+		})
+	}
+	return response
+}
+
+type openWalletResponse struct {
+	Wallet   db.Wallet         `json:"wallet"`
+	Accounts []accountResponse `json:"accounts"`
+}
+
+func newOpenWalletResponse(result db.OpenWalletResult) openWalletResponse {
+	response := openWalletResponse{Wallet: result.Wallet, Accounts: []accountResponse{}}
+	for _, account := range result.Accounts {
+		response.Accounts = append(response.Accounts, newAccountResponse(account))
+	}
+	return response
+}
+
+type walletCurrencyBalanceResponse struct {
+	Account   accountResponse `json:"account"`
+	Rate      string          `json:"rate"`
+	Converted money.Money     `json:"converted"`
+}
+
+type walletBalanceResponse struct {
+	Wallet   db.Wallet                       `json:"wallet"`
+	Total    money.Money                     `json:"total"`
+	Balances []walletCurrencyBalanceResponse `json:"balances"`
+}
+
+func newWalletBalanceResponse(balance db.WalletBalance) walletBalanceResponse {
+	response := walletBalanceResponse{
+		Wallet:   balance.Wallet,
+		Total:    money.Money{Amount: balance.Total, Currency: balance.ReportingCurrency},
+		Balances: []walletCurrencyBalanceResponse{},
+	}
+	for _, line := range balance.Balances {
+		response.Balances = append(response.Balances, walletCurrencyBalanceResponse{
+			Account:   newAccountResponse(line.Account),
+			Rate:      line.Rate,
+			Converted: money.Money{Amount: line.Converted, Currency: balance.ReportingCurrency},
+		})
+	}
+	return response
+}
+
+type conversionResponse struct {
+	db.Conversion
+	FromAmount  money.Money     `json:"from_amount"`
+	ToAmount    money.Money     `json:"to_amount"`
+	FromAccount accountResponse `json:"from_account"`
+	ToAccount   accountResponse `json:"to_account"`
+}
+
+func newConversionResponse(result db.ConvertCurrencyResult) conversionResponse {
+	return conversionResponse{
+		Conversion:  result.Conversion,
+		FromAmount:  money.Money{Amount: result.Conversion.FromAmount, Currency: result.FromAccount.Currency},
+		ToAmount:    money.Money{Amount: result.Conversion.ToAmount, Currency: result.ToAccount.Currency},
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount:   newAccountResponse(result.ToAccount),
+	}
+}
+
+// parseAmount parses a decimal amount of the currency, like "12.34", into minor units. The empty amount is zero.
+// The amounts of the requests are json.Number, so clients can send 12.34 or "12.34".
+func parseAmount(amount string, currency string) (int64, error) {
+	if amount == "" {
+		return 0, nil
+	}
+
+	value, err := money.ParseAmount(amount, currency)
+	if errors.Is(err, money.ErrTooManyDecimals) || errors.Is(err, money.ErrOverflow) {
+		return 0, err
+	}
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q", amount)
+	}
+	return value.Amount, nil
+}
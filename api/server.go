@@ -0,0 +1,241 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	db "simple_bank/db/sqlc"
+	"simple_bank/token"
+	"simple_bank/tracing"
+	"simple_bank/util"
+)
+
+// Store is the part of db.Store used by the API
+type Store interface {
+	GetAccount(ctx context.Context, id int64) (db.Account, error)
+	ListAccountIDsByHolder(ctx context.Context, owner string) ([]int64, error)
+	AccountRole(ctx context.Context, accountId int64, username string) (db.GetAccountRoleRow, error)
+	GetOutboxHorizon(ctx context.Context) (int64, error)
+	ListAccountEventsAfter(ctx context.Context, arg db.ListAccountEventsAfterParams) ([]db.Outbox, error)
+	ListAccountsPage(ctx context.Context, owner string, params db.PageParams) (db.AccountsPage, error)
+	SearchEntries(ctx context.Context, params db.SearchEntriesParams) (db.EntriesPage, error)
+	SearchTransfers(ctx context.Context, params db.SearchTransfersParams) (db.TransfersPage, error)
+	AccountStatement(ctx context.Context, accountId int64, periodStart, periodEnd time.Time) (db.Statement, error)
+	CreateCustomer(ctx context.Context, arg db.CreateCustomerParams) (db.Customer, error)
+	GetCustomer(ctx context.Context, id int64) (db.Customer, error)
+	RecordKYCOutcome(ctx context.Context, params db.RecordKYCOutcomeParams) (db.Customer, error)
+	SetAccountCustomer(ctx context.Context, arg db.SetAccountCustomerParams) (db.Account, error)
+	ListAccountHolders(ctx context.Context, accountId int64) ([]db.AccountHolder, error)
+	AddAccountHolder(ctx context.Context, params db.AddAccountHolderParams) (db.AccountHolder, error)
+	RemoveAccountHolder(ctx context.Context, accountId int64, username string) error
+	RequestTransfer(ctx context.Context, params db.TransferTxParams) (db.RequestTransferResult, error)
+	ListPendingTransferRequests(ctx context.Context, fromAccountID int64) ([]db.TransferRequest, error)
+	ApproveTransferRequest(ctx context.Context, id int64) (db.ApproveTransferRequestResult, error)
+	RejectTransferRequest(ctx context.Context, id int64, reason string) (db.TransferRequest, error)
+	SetAccountApprovalThreshold(ctx context.Context, arg db.SetAccountApprovalThresholdParams) (db.Account, error)
+	GetWallet(ctx context.Context, id int64) (db.Wallet, error)
+	OpenWallet(ctx context.Context, params db.OpenWalletParams) (db.OpenWalletResult, error)
+	AddWalletCurrency(ctx context.Context, walletId int64, currency string) (db.Account, error)
+	ConvertCurrency(ctx context.Context, params db.ConvertCurrencyParams) (db.ConvertCurrencyResult, error)
+	ConsolidatedBalance(ctx context.Context, walletId int64, reportingCurrency string) (db.WalletBalance, error)
+	AddFXRate(ctx context.Context, arg db.CreateFXRateParams) (db.FxRate, error)
+	FXRate(ctx context.Context, from, to string, at time.Time) (db.FXQuote, error)
+	CreateWebhookSubscription(ctx context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (db.WebhookSubscription, error)
+	ListWebhookSubscriptions(ctx context.Context, owner string) ([]db.WebhookSubscription, error)
+	DeleteWebhookSubscription(ctx context.Context, id int64) error
+	EnableWebhookSubscription(ctx context.Context, id int64) (db.WebhookSubscription, error)
+	CheckReady(ctx context.Context) error
+}
+
+// EventSource wakes up the streams when outbox events are committed, stream.Broker implements it
+type EventSource interface {
+	Subscribe() (<-chan db.OutboxNotification, func())
+}
+
+// readHeaderTimeout protects the server from the clients sending their headers slowly
+const readHeaderTimeout = 10 * time.Second
+
+// Metrics records the requests of every route and serves the metrics, metrics.Metrics implements it
+type Metrics interface {
+	InstrumentHTTP(route string, next http.HandlerFunc) http.HandlerFunc
+	Handler() http.Handler
+}
+
+// Server serves HTTP requests for our banking service
+type Server struct {
+	config     util.Config
+	store      Store
+	tokenMaker token.Maker
+	events     EventSource
+	metrics    Metrics
+	tracer     trace.TracerProvider
+	logger     *slog.Logger
+	router     *http.ServeMux
+
+	httpServer *http.Server
+	// shuttingDown is closed by Shutdown, it ends the event streams and fails the readiness probe
+	shuttingDown chan struct{}
+	shutdownOnce sync.Once
+}
+
+// ServerOption configures optional behaviour of a Server
+type ServerOption func(server *Server)
+
+// WithMetrics records the requests of every route and serves the metrics at GET /metrics, without authentication
+func WithMetrics(metrics Metrics) ServerOption {
+	return func(server *Server) {
+		server.metrics = metrics
+	}
+}
+
+// WithTracer starts a span for every request, continuing the trace of its traceparent header
+func WithTracer(provider trace.TracerProvider) ServerOption {
+	return func(server *Server) {
+		server.tracer = provider
+	}
+}
+
+// WithLogger logs every request with its request ID, see logging.New
+func WithLogger(logger *slog.Logger) ServerOption {
+	return func(server *Server) {
+		server.logger = logger
+	}
+}
+
+// NewServer creates a new HTTP server and setup routing
+func NewServer(config util.Config, store Store, events EventSource, opts ...ServerOption) (*Server, error) {
+	tokenMaker, err := token.NewHMACMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	server := &Server{
+		config:       config,
+		store:        store,
+		tokenMaker:   tokenMaker,
+		events:       events,
+		shuttingDown: make(chan struct{}),
+	}
+
+	for _, opt := range opts {
+		opt(server)
+	}
+
+	server.setupRouter()
+	server.httpServer = &http.Server{Handler: server.router, ReadHeaderTimeout: readHeaderTimeout}
+	return server, nil
+}
+
+func (server *Server) setupRouter() {
+	router := http.NewServeMux()
+
+	// the probes are neither logged nor measured, they are called every few seconds
+	router.HandleFunc("GET /healthz", server.healthz)
+	router.HandleFunc("GET /readyz", server.readyz)
+
+	handle := func(pattern string, handler http.HandlerFunc) {
+		_, route, _ := strings.Cut(pattern, " ")
+		if server.tracer != nil {
+			handler = tracing.Middleware(server.tracer, route, handler)
+		}
+		if server.logger != nil {
+			handler = server.logMiddleware(route, handler)
+		}
+		if server.metrics != nil {
+			handler = server.metrics.InstrumentHTTP(route, handler)
+		}
+		router.HandleFunc(pattern, handler)
+	}
+
+	handle("GET /accounts", server.authMiddleware(server.listAccounts))
+	handle("GET /accounts/{id}/entries", server.authMiddleware(server.listEntries))
+	handle("GET /accounts/{id}/transfers", server.authMiddleware(server.listTransfers))
+	handle("GET /accounts/{id}/statement", server.authMiddleware(server.getStatement))
+	handle("GET /accounts/{id}/holders", server.authMiddleware(server.listHolders))
+	handle("POST /accounts/{id}/holders", server.authMiddleware(server.addHolder))
+	handle("DELETE /accounts/{id}/holders/{username}", server.authMiddleware(server.removeHolder))
+	handle("PUT /accounts/{id}/approval-threshold", server.authMiddleware(server.setApprovalThreshold))
+	handle("GET /accounts/{id}/transfer-requests", server.authMiddleware(server.listTransferRequests))
+	handle("POST /transfers", server.authMiddleware(server.createTransfer))
+	handle("POST /transfer-requests/{id}/approve", server.authMiddleware(server.approveTransferRequest))
+	handle("POST /transfer-requests/{id}/reject", server.authMiddleware(server.rejectTransferRequest))
+	handle("GET /wallets/{id}/balance", server.authMiddleware(server.getWalletBalance))
+	handle("POST /wallets/{id}/conversions", server.authMiddleware(server.convertCurrency))
+	handle("GET /fx-rates", server.authMiddleware(server.getFXRate))
+	handle("GET /events", server.streamAuthMiddleware(server.streamEvents))
+	handle("GET /webhooks", server.authMiddleware(server.listWebhooks))
+	handle("POST /webhooks", server.authMiddleware(server.createWebhook))
+	handle("DELETE /webhooks/{id}", server.authMiddleware(server.deleteWebhook))
+	handle("POST /webhooks/{id}/enable", server.authMiddleware(server.enableWebhook))
+
+	handle("POST /admin/customers", server.authMiddleware(server.adminMiddleware(server.createCustomer)))
+	handle("GET /admin/customers/{id}", server.authMiddleware(server.adminMiddleware(server.getCustomer)))
+	handle("POST /admin/customers/{id}/kyc", server.authMiddleware(server.adminMiddleware(server.recordKYCOutcome)))
+	handle("PUT /admin/accounts/{id}/customer", server.authMiddleware(server.adminMiddleware(server.setAccountCustomer)))
+	handle("POST /admin/wallets", server.authMiddleware(server.adminMiddleware(server.openWallet)))
+	handle("POST /admin/wallets/{id}/currencies", server.authMiddleware(server.adminMiddleware(server.addWalletCurrency)))
+	handle("POST /admin/fx-rates", server.authMiddleware(server.adminMiddleware(server.addFXRate)))
+
+	if server.metrics != nil {
+		router.Handle("GET /metrics", server.metrics.Handler())
+	}
+
+	server.router = router
+}
+
+// Handler returns the HTTP handler of the server
+func (server *Server) Handler() http.Handler {
+	return server.router
+}
+
+// Start runs the HTTP server on a specific address until Shutdown is called, it then returns nil
+func (server *Server) Start(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	err = server.httpServer.Serve(listener)
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Shutdown stops accepting connections, ends the event streams and waits for the requests in flight,
+// like the transfers being executed, until the context is done
+func (server *Server) Shutdown(ctx context.Context) error {
+	server.shutdownOnce.Do(func() {
+		close(server.shuttingDown)
+	})
+	return server.httpServer.Shutdown(ctx)
+}
+
+func (server *Server) isShuttingDown() bool {
+	select {
+	case <-server.shuttingDown:
+		return true
+	default:
+		return false
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func errorResponse(err error) map[string]string {
+	return map[string]string{"error": err.Error()}
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"simple_bank/metrics"
+	"simple_bank/util"
+)
+
+func newTestServer(t *testing.T, store Store, events EventSource) *Server {
+	config := util.Config{
+		TokenSymmetricKey:   util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		AdminUsernames:      []string{"admin"},
+	}
+
+	server, err := NewServer(config, store, events)
+	require.NoError(t, err)
+
+	return server
+}
+
+func createTestToken(t *testing.T, server *Server, username string) string {
+	accessToken, _, err := server.tokenMaker.CreateToken(username, time.Minute)
+	require.NoError(t, err)
+	return accessToken
+}
+
+func TestMetricsRoute(t *testing.T) {
+	config := util.Config{TokenSymmetricKey: util.RandomString(32)}
+
+	server, err := NewServer(config, nil, nil)
+	require.NoError(t, err)
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	server, err = NewServer(config, nil, nil, WithMetrics(metrics.New(nil)))
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/accounts/7/entries", nil))
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `simple_bank_http_requests_total{code="401",method="GET",route="/accounts/{id}/entries"} 1`)
+}
+
+func TestTracingRoute(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	server, err := NewServer(util.Config{TokenSymmetricKey: util.RandomString(32)}, nil, nil, WithTracer(provider))
+	require.NoError(t, err)
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/7/entries", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	server.Handler().ServeHTTP(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	require.Equal(t, "GET /accounts/{id}/entries", spans[0].Name())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	require.Contains(t, spans[0].Attributes(), attribute.String("http.route", "/accounts/{id}/entries"))
+	require.Contains(t, spans[0].Attributes(), attribute.Int("http.status_code", http.StatusUnauthorized))
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+var statementCSVHeader = []string{
+	"date",
+	"entry_id",
+	"transfer_id",
+	"counterparty_id",
+	"reference",
+	"description",
+	"amount",
+	"balance",
+}
+
+// getStatement returns the statement of an account of the authenticated user between the from and to query parameters,
+// as JSON or as CSV with format=csv
+func (server *Server) getStatement(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	from, to, err := statementPeriod(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	statement, err := server.store.AccountStatement(r.Context(), account.ID, from, to)
+	if err != nil {
+		writeJSON(w, listErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeStatement(w, r.URL.Query().Get("format"), statement)
+}
+
+func statementPeriod(r *http.Request) (from time.Time, to time.Time, err error) {
+	if from, err = timeQuery(r, "from"); err != nil {
+		return
+	}
+	if to, err = timeQuery(r, "to"); err != nil {
+		return
+	}
+
+	if from.IsZero() || to.IsZero() {
+		err = errors.New("from and to are required")
+	}
+	return
+}
+
+func writeStatement(w http.ResponseWriter, format string, statement db.Statement) {
+	if format != "csv" {
+		writeJSON(w, http.StatusOK, newStatementResponse(statement))
+		return
+	}
+
+	filename := fmt.Sprintf("statement-%d-%s.csv", statement.Account.ID, statement.PeriodStart.Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+filename+`"`)
+	w.WriteHeader(http.StatusOK)
+
+	_ = writeStatementCSV(csv.NewWriter(w), statement)
+}
+
+func writeStatementCSV(writer *csv.Writer, statement db.Statement) error {
+	err := writer.Write(statementCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	currency := statement.Account.Currency
+	for _, line := range statement.Lines {
+		transferId, counterpartyId := "", ""
+		if line.TransferID.Valid {
+			transferId = strconv.FormatInt(line.TransferID.Int64, 10)
+			counterpartyId = strconv.FormatInt(line.CounterpartyID, 10)
+		}
+
+		err = writer.Write([]string{
+			line.CreatedAt.UTC().Format(time.RFC3339),
+			strconv.FormatInt(line.ID, 10),
+			transferId,
+			counterpartyId,
+			line.Reference,
+			line.Description,
+			money.Money{Amount: line.Amount, Currency: currency}.FormatAmount(),
+			money.Money{Amount: line.Balance, Currency: currency}.FormatAmount(),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
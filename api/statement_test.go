@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+type fakeStatementStore struct {
+	fakePageStore
+}
+
+func (store *fakeStatementStore) AccountStatement(_ context.Context, accountId int64, periodStart, periodEnd time.Time) (db.Statement, error) {
+	if !periodStart.Before(periodEnd) {
+		return db.Statement{}, db.ErrInvalidDateRange
+	}
+
+	return db.Statement{
+		Account:        store.accounts[accountId],
+		PeriodStart:    periodStart,
+		PeriodEnd:      periodEnd,
+		OpeningBalance: 100,
+		ClosingBalance: 93,
+		Lines: []db.StatementLine{
+			{
+				ListStatementLinesRow: db.ListStatementLinesRow{
+					ID:             1,
+					Amount:         -10,
+					CreatedAt:      time.Date(2023, 3, 1, 9, 0, 0, 0, time.UTC),
+					TransferID:     sql.NullInt64{Int64: 5, Valid: true},
+					Reference:      "INV-7",
+					Description:    "Rent, March",
+					CounterpartyID: 2,
+				},
+				Balance: 90,
+			},
+			{
+				ListStatementLinesRow: db.ListStatementLinesRow{
+					ID:        2,
+					Amount:    3,
+					CreatedAt: time.Date(2023, 3, 2, 9, 0, 0, 0, time.UTC),
+				},
+				Balance: 93,
+			},
+		},
+	}, nil
+}
+
+func TestGetStatement(t *testing.T) {
+	store := &fakeStatementStore{fakePageStore{accounts: map[int64]db.Account{
+		1: {ID: 1, Owner: "alice", Currency: "USD"},
+	}}}
+	server := newTestServer(t, store, nil)
+	token := createTestToken(t, server, "alice")
+
+	testCases := []struct {
+		name   string
+		query  string
+		status int
+	}{
+		{name: "JSON", query: "?from=2023-03-01T00:00:00Z&to=2023-04-01T00:00:00Z", status: http.StatusOK},
+		{name: "MissingPeriod", query: "?from=2023-03-01T00:00:00Z", status: http.StatusBadRequest},
+		{name: "InvalidPeriod", query: "?from=2023-04-01T00:00:00Z&to=2023-03-01T00:00:00Z", status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/accounts/1/statement"+tc.query, nil)
+			request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+			recorder := httptest.NewRecorder()
+
+			server.Handler().ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/1/statement?from=2023-03-01T00:00:00Z&to=2023-04-01T00:00:00Z&format=csv", nil)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "text/csv", recorder.Header().Get("Content-Type"))
+	require.Contains(t, recorder.Header().Get("Content-Disposition"), "statement-1-2023-03-01.csv")
+
+	records, err := csv.NewReader(strings.NewReader(recorder.Body.String())).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		statementCSVHeader,
+		{"2023-03-01T09:00:00Z", "1", "5", "2", "INV-7", "Rent, March", "-0.10", "0.90"},
+		{"2023-03-02T09:00:00Z", "2", "", "", "", "", "0.03", "0.93"},
+	}, records)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+const (
+	lastEventIDHeaderKey = "Last-Event-ID"
+	lastEventIDQueryKey  = "last_event_id"
+	accountIDQueryKey    = "account_id"
+	// maxStreamBatch is the maximum number of events read from the outbox at once
+	maxStreamBatch = 100
+)
+
+// heartbeatInterval keeps idle connections open through proxies, the stream also catches up at every heartbeat
+var heartbeatInterval = 15 * time.Second
+
+// settleInterval is how long the stream waits before reading again a notified event it couldn't read yet,
+// because a transaction started before the event's one is still running. It doubles until the next heartbeat.
+var settleInterval = 100 * time.Millisecond
+
+// eventCursor is the position of an event in the stream: the transaction that created it, then its outbox ID.
+// The outbox IDs are assigned when the events are inserted, not when they commit, so the stream can't follow them:
+// an event of a long transaction would commit after events with higher IDs, behind the cursor of the clients.
+type eventCursor struct {
+	xactID int64
+	id     int64
+}
+
+func (cursor eventCursor) String() string {
+	return fmt.Sprintf("%d-%d", cursor.xactID, cursor.id)
+}
+
+// parseEventCursor parses the ID of an event sent by the stream, like 1234-56
+func parseEventCursor(value string) (eventCursor, error) {
+	xactID, id, ok := strings.Cut(value, "-")
+	if !ok {
+		return eventCursor{}, fmt.Errorf("invalid last event id %q", value)
+	}
+
+	var cursor eventCursor
+	var err1, err2 error
+	cursor.xactID, err1 = strconv.ParseInt(xactID, 10, 64)
+	cursor.id, err2 = strconv.ParseInt(id, 10, 64)
+	if err1 != nil || err2 != nil || cursor.xactID < 0 || cursor.id < 0 {
+		return eventCursor{}, fmt.Errorf("invalid last event id %q", value)
+	}
+	return cursor, nil
+}
+
+// streamEvents streams the AccountCredited and AccountDebited events of the accounts of the authenticated owner
+// as server-sent events. The events are sent in the order their transactions started, once every transaction
+// started before them has ended, so an event can never be sent after the cursor moved past it.
+// The horizon is the oldest transaction running in the whole Postgres cluster: a long transaction, even one that
+// doesn't touch the outbox, delays every stream until it ends. Bound them with idle_in_transaction_session_timeout
+// and statement_timeout on the database.
+// A client reconnecting with the Last-Event-ID header, or the last_event_id query parameter, receives every event it missed.
+// Without a cursor, only the events committed after the connection are sent.
+// The account_id query parameter can be repeated to restrict the stream to some accounts.
+func (server *Server) streamEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(errors.New("streaming unsupported")))
+		return
+	}
+
+	ctx := r.Context()
+	payload := authPayload(r)
+
+	accountIDs, status, err := server.streamAccountIDs(r, payload.Username)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	// subscribe before reading the cursor, so no event committed in between is missed
+	notifications, unsubscribe := server.events.Subscribe()
+	defer unsubscribe()
+
+	cursor, err := server.streamCursor(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	watched := make(map[int64]bool, len(accountIDs))
+	for _, id := range accountIDs {
+		watched[id] = true
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+
+	// pending holds the notified events not sent yet, by the time they were notified
+	pending := make(map[int64]time.Time)
+	var settle <-chan time.Time
+	settleRetries := 0
+
+	catchUp := true
+	for {
+		if catchUp {
+			cursor, err = server.sendEventsAfter(w, r, cursor, accountIDs, pending)
+			if err != nil {
+				return
+			}
+			flusher.Flush()
+
+			// past a heartbeat, the heartbeats catch up on the events still pending
+			for id, notifiedAt := range pending {
+				if time.Since(notifiedAt) > heartbeatInterval {
+					delete(pending, id)
+				}
+			}
+
+			settle = nil
+			if len(pending) == 0 {
+				settleRetries = 0
+			} else {
+				settle = time.After(min(settleInterval<<settleRetries, heartbeatInterval))
+				settleRetries++
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-server.shuttingDown:
+			return
+		case <-heartbeat.C:
+			catchUp = true
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case <-settle:
+			catchUp = true
+		case notification := <-notifications:
+			catchUp = notification.ID == 0 || (watched[notification.AggregateID] && isBalanceEvent(notification.EventType))
+			if notification.ID != 0 && catchUp {
+				pending[notification.ID] = time.Now()
+			}
+		}
+	}
+}
+
+// streamAccountIDs returns the requested accounts, or every account the user owns or holds
+func (server *Server) streamAccountIDs(r *http.Request, username string) ([]int64, int, error) {
+	values := r.URL.Query()[accountIDQueryKey]
+	if len(values) == 0 {
+		ids, err := server.store.ListAccountIDsByHolder(r.Context(), username)
+		if err != nil {
+			return nil, http.StatusInternalServerError, err
+		}
+		return ids, http.StatusOK, nil
+	}
+
+	ids := make([]int64, 0, len(values))
+	for _, value := range values {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || id <= 0 {
+			return nil, http.StatusBadRequest, fmt.Errorf("invalid %s %q", accountIDQueryKey, value)
+		}
+
+		_, _, status, err := server.accountRole(r, id)
+		if err != nil {
+			return nil, status, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, http.StatusOK, nil
+}
+
+// isBalanceEvent reports whether the event is streamed, the aggregate of the other events isn't an account
+func isBalanceEvent(eventType string) bool {
+	return eventType == db.EventAccountCredited || eventType == db.EventAccountDebited
+}
+
+// streamCursor returns the cursor of the last event the client received,
+// or the horizon of the running transactions without one
+func (server *Server) streamCursor(r *http.Request) (eventCursor, error) {
+	value := r.Header.Get(lastEventIDHeaderKey)
+	if value == "" {
+		value = r.URL.Query().Get(lastEventIDQueryKey)
+	}
+
+	if value == "" {
+		// the events of the transactions running now are committed after the connection
+		horizon, err := server.store.GetOutboxHorizon(r.Context())
+		return eventCursor{xactID: horizon}, err
+	}
+
+	return parseEventCursor(value)
+}
+
+// sendEventsAfter writes every event after the cursor, removes them from pending and returns the new cursor
+func (server *Server) sendEventsAfter(
+	w http.ResponseWriter,
+	r *http.Request,
+	cursor eventCursor,
+	accountIDs []int64,
+	pending map[int64]time.Time,
+) (eventCursor, error) {
+	if len(accountIDs) == 0 {
+		return cursor, nil
+	}
+
+	for {
+		events, err := server.store.ListAccountEventsAfter(r.Context(), db.ListAccountEventsAfterParams{
+			AfterXactID: cursor.xactID,
+			AfterID:     cursor.id,
+			AccountIds:  accountIDs,
+			MaxEvents:   maxStreamBatch,
+		})
+		if err != nil {
+			return cursor, err
+		}
+
+		for _, event := range events {
+			next := eventCursor{xactID: event.XactID, id: event.ID}
+			_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", next, event.EventType, event.Payload)
+			if err != nil {
+				return cursor, err
+			}
+			cursor = next
+			delete(pending, event.ID)
+		}
+
+		if len(events) < maxStreamBatch {
+			return cursor, nil
+		}
+	}
+}
@@ -0,0 +1,360 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+	"simple_bank/stream"
+)
+
+// fakeStreamStore keeps accounts and outbox events in memory,
+// the events of a transaction are visible once every transaction started before it ended, as with txid_snapshot_xmin
+type fakeStreamStore struct {
+	Store
+
+	mu       sync.Mutex
+	accounts map[int64]db.Account
+	events   []db.Outbox
+	nextXact int64
+	running  map[int64]bool
+}
+
+func (store *fakeStreamStore) GetAccount(_ context.Context, id int64) (db.Account, error) {
+	account, ok := store.accounts[id]
+	if !ok {
+		return db.Account{}, sql.ErrNoRows
+	}
+	return account, nil
+}
+
+func (store *fakeStreamStore) ListAccountIDsByHolder(_ context.Context, owner string) ([]int64, error) {
+	var ids []int64
+	for id, account := range store.accounts {
+		if account.Owner == owner {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+func (store *fakeStreamStore) AccountRole(_ context.Context, accountId int64, username string) (db.GetAccountRoleRow, error) {
+	return db.GetAccountRoleRow{}, db.ErrNotAccountHolder
+}
+
+func (store *fakeStreamStore) GetOutboxHorizon(context.Context) (int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.horizon(), nil
+}
+
+func (store *fakeStreamStore) horizon() int64 {
+	horizon := store.nextXact
+	for xactID := range store.running {
+		horizon = min(horizon, xactID)
+	}
+	return horizon
+}
+
+func (store *fakeStreamStore) ListAccountEventsAfter(_ context.Context, arg db.ListAccountEventsAfterParams) ([]db.Outbox, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var events []db.Outbox
+	for _, event := range store.events {
+		after := event.XactID > arg.AfterXactID || (event.XactID == arg.AfterXactID && event.ID > arg.AfterID)
+		if !after || event.XactID >= store.horizon() {
+			continue
+		}
+		for _, id := range arg.AccountIds {
+			if event.AggregateID == id {
+				events = append(events, event)
+			}
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		if events[i].XactID != events[j].XactID {
+			return events[i].XactID < events[j].XactID
+		}
+		return events[i].ID < events[j].ID
+	})
+	if len(events) > int(arg.MaxEvents) {
+		events = events[:arg.MaxEvents]
+	}
+	return events, nil
+}
+
+// begin starts a transaction and returns its ID
+func (store *fakeStreamStore) begin() int64 {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	xactID := store.nextXact
+	store.nextXact++
+	store.running[xactID] = true
+	return xactID
+}
+
+func (store *fakeStreamStore) commit(xactID int64) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	delete(store.running, xactID)
+}
+
+// insertEvent inserts an event in a running transaction, the outbox ID is assigned at once
+func (store *fakeStreamStore) insertEvent(xactID int64, accountId int64, amount int64) db.Outbox {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	payload, _ := json.Marshal(db.BalanceChangedPayload{AccountID: accountId, Amount: amount})
+	event := db.Outbox{
+		ID:          int64(len(store.events) + 1),
+		EventType:   db.EventAccountCredited,
+		AggregateID: accountId,
+		Payload:     payload,
+		XactID:      xactID,
+	}
+	store.events = append(store.events, event)
+	return event
+}
+
+// addEvent inserts an event in its own transaction and commits it
+func (store *fakeStreamStore) addEvent(accountId int64, amount int64) db.Outbox {
+	xactID := store.begin()
+	event := store.insertEvent(xactID, accountId, amount)
+	store.commit(xactID)
+	return event
+}
+
+func newFakeStreamStore() *fakeStreamStore {
+	return &fakeStreamStore{
+		accounts: map[int64]db.Account{
+			1: {ID: 1, Owner: "alice"},
+			2: {ID: 2, Owner: "bob"},
+		},
+		nextXact: 1000,
+		running:  make(map[int64]bool),
+	}
+}
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads the next event of the stream, skipping comments
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	var event sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+
+		switch {
+		case line == "" && event.id != "":
+			return event
+		case strings.HasPrefix(line, "id: "):
+			event.id = strings.TrimPrefix(line, "id: ")
+		case strings.HasPrefix(line, "event: "):
+			event.event = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			event.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestStreamEventsReplaysAndStreams(t *testing.T) {
+	store := newFakeStreamStore()
+	store.addEvent(1, 10)
+	store.addEvent(2, 20)
+	store.addEvent(1, 30)
+
+	broker := stream.NewBroker()
+	server := newTestServer(t, store, broker)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, httpServer.URL+"/events", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+createTestToken(t, server, "alice"))
+	request.Header.Set(lastEventIDHeaderKey, "0-0")
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+	require.Equal(t, "text/event-stream", response.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(response.Body)
+
+	// missed events of alice are replayed, bob's are not
+	event := readEvent(t, reader)
+	require.Equal(t, "1000-1", event.id)
+	require.Equal(t, db.EventAccountCredited, event.event)
+	require.Equal(t, "1002-3", readEvent(t, reader).id)
+
+	// new events are streamed when notified
+	store.addEvent(2, 40)
+	broker.Broadcast(db.OutboxNotification{ID: 4, EventType: db.EventAccountCredited, AggregateID: 2})
+	created := store.addEvent(1, 50)
+	broker.Broadcast(db.OutboxNotification{ID: created.ID, EventType: db.EventAccountCredited, AggregateID: 1})
+
+	event = readEvent(t, reader)
+	require.Equal(t, "1004-5", event.id)
+
+	var payload db.BalanceChangedPayload
+	require.NoError(t, json.Unmarshal([]byte(event.data), &payload))
+	require.Equal(t, int64(50), payload.Amount)
+}
+
+// openStream connects to the stream of alice and returns its reader
+func openStream(t *testing.T, ctx context.Context, url string, server *Server, lastEventID string) *bufio.Reader {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/events", nil)
+	require.NoError(t, err)
+	request.Header.Set(authorizationHeaderKey, "Bearer "+createTestToken(t, server, "alice"))
+	if lastEventID != "" {
+		request.Header.Set(lastEventIDHeaderKey, lastEventID)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { response.Body.Close() })
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	return bufio.NewReader(response.Body)
+}
+
+func TestStreamEventsCommittedOutOfIDOrder(t *testing.T) {
+	store := newFakeStreamStore()
+	broker := stream.NewBroker()
+	server := newTestServer(t, store, broker)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reader := openStream(t, ctx, httpServer.URL, server, "")
+
+	// the first transaction inserts its event first but commits after the second one
+	first := store.begin()
+	slow := store.insertEvent(first, 1, 10)
+	second := store.begin()
+	fast := store.insertEvent(second, 1, 20)
+	store.commit(second)
+	broker.Broadcast(db.OutboxNotification{ID: fast.ID, EventType: fast.EventType, AggregateID: 1})
+
+	store.commit(first)
+	broker.Broadcast(db.OutboxNotification{ID: slow.ID, EventType: slow.EventType, AggregateID: 1})
+
+	event := readEvent(t, reader)
+	require.Equal(t, eventCursor{xactID: first, id: slow.ID}.String(), event.id)
+	event = readEvent(t, reader)
+	require.Equal(t, eventCursor{xactID: second, id: fast.ID}.String(), event.id)
+
+	// a client reconnecting after the slow event doesn't miss the fast one
+	reconnected := openStream(t, ctx, httpServer.URL, server, eventCursor{xactID: first, id: slow.ID}.String())
+	require.Equal(t, eventCursor{xactID: second, id: fast.ID}.String(), readEvent(t, reconnected).id)
+}
+
+func TestStreamEventsWaitsForEarlierTransactions(t *testing.T) {
+	store := newFakeStreamStore()
+	broker := stream.NewBroker()
+	server := newTestServer(t, store, broker)
+	httpServer := httptest.NewServer(server.Handler())
+	defer httpServer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	reader := openStream(t, ctx, httpServer.URL, server, "")
+
+	// the notified event is held back by an unrelated transaction started before it, until that one ends
+	unrelated := store.begin()
+	event := store.addEvent(1, 10)
+	broker.Broadcast(db.OutboxNotification{ID: event.ID, EventType: event.EventType, AggregateID: 1})
+	time.Sleep(2 * settleInterval)
+	store.commit(unrelated)
+
+	require.Equal(t, eventCursor{xactID: event.XactID, id: event.ID}.String(), readEvent(t, reader).id)
+}
+
+func TestParseEventCursor(t *testing.T) {
+	cursor, err := parseEventCursor("1234-56")
+	require.NoError(t, err)
+	require.Equal(t, eventCursor{xactID: 1234, id: 56}, cursor)
+	require.Equal(t, "1234-56", cursor.String())
+
+	for _, value := range []string{"56", "abc-1", "1-abc", "-1-2", "1--2"} {
+		_, err := parseEventCursor(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestStreamEventsAuthorization(t *testing.T) {
+	store := newFakeStreamStore()
+	server := newTestServer(t, store, stream.NewBroker())
+
+	testCases := []struct {
+		name   string
+		url    string
+		token  bool
+		status int
+	}{
+		{name: "NoAuthorization", url: "/events", status: http.StatusUnauthorized},
+		{name: "OtherOwnerAccount", url: "/events?account_id=2", token: true, status: http.StatusForbidden},
+		{name: "UnknownAccount", url: "/events?account_id=99", token: true, status: http.StatusNotFound},
+		{name: "InvalidAccount", url: "/events?account_id=abc", token: true, status: http.StatusBadRequest},
+		{name: "InvalidCursor", url: "/events?last_event_id=abc", token: true, status: http.StatusBadRequest},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, tc.url, nil)
+			if tc.token {
+				request.Header.Set(authorizationHeaderKey, "Bearer "+createTestToken(t, server, "alice"))
+			}
+
+			recorder := httptest.NewRecorder()
+			server.Handler().ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+}
+
+func TestStreamEventsAccessTokenQuery(t *testing.T) {
+	store := newFakeStreamStore()
+	server := newTestServer(t, store, stream.NewBroker())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// the request context is already cancelled, so the stream returns right after the headers
+	request := httptest.NewRequest(http.MethodGet, "/events?access_token="+createTestToken(t, server, "alice"), nil)
+	request = request.WithContext(ctx)
+
+	recorder := httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// the other routes only accept the Authorization header
+	request = httptest.NewRequest(http.MethodGet, "/accounts?access_token="+createTestToken(t, server, "alice"), nil)
+	recorder = httptest.NewRecorder()
+	server.Handler().ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "simple_bank/db/sqlc"
+)
+
+type createTransferRequest struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// Amount is a decimal in the currency of the accounts, like 12.34
+	Amount      json.Number     `json:"amount"`
+	Reference   string          `json:"reference"`
+	Description string          `json:"description"`
+	Metadata    json.RawMessage `json:"metadata"`
+}
+
+// createTransfer sends money from an account of the authenticated user. The transfer is executed right away,
+// or stored as a request pending approval when the amount is above the approval threshold of the account.
+func (server *Server) createTransfer(w http.ResponseWriter, r *http.Request) {
+	var request createTransferRequest
+	err := readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	from, _, status, err := server.accountRole(r, request.FromAccountID)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	to, err := server.store.GetAccount(r.Context(), request.ToAccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		writeJSON(w, http.StatusNotFound, errorResponse(fmt.Errorf("account %d not found", request.ToAccountID)))
+		return
+	}
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	if from.Currency != to.Currency {
+		err = fmt.Errorf("account %d currency mismatch: %s vs %s", to.ID, to.Currency, from.Currency)
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	amount, err := parseAmount(request.Amount.String(), from.Currency)
+	if err == nil && amount <= 0 {
+		err = errors.New("amount must be positive")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RequestTransfer(r.Context(), db.TransferTxParams{
+		FromAccountId: from.ID,
+		ToAccountId:   to.ID,
+		Amount:        amount,
+		Reference:     request.Reference,
+		Description:   request.Description,
+		Metadata:      request.Metadata,
+	})
+	if err != nil {
+		writeJSON(w, transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	if result.Request != nil {
+		request := newTransferRequestResponse(*result.Request, from.Currency)
+		writeJSON(w, http.StatusAccepted, createTransferResponse{Request: &request})
+		return
+	}
+
+	transfer := newTransferResultResponse(*result.Transfer)
+	writeJSON(w, http.StatusCreated, createTransferResponse{Transfer: &transfer})
+}
+
+// createTransferResponse has the executed transfer, or the request waiting for its approval
+type createTransferResponse struct {
+	Transfer *transferResultResponse  `json:"transfer,omitempty"`
+	Request  *transferRequestResponse `json:"request,omitempty"`
+}
+
+// listTransferRequests returns the transfer requests of an account of the authenticated user pending approval
+func (server *Server) listTransferRequests(w http.ResponseWriter, r *http.Request) {
+	account, _, status, err := server.heldAccount(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	requests, err := server.store.ListPendingTransferRequests(r.Context(), account.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := []transferRequestResponse{}
+	for _, request := range requests {
+		response = append(response, newTransferRequestResponse(request, account.Currency))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// approveTransferRequest executes a pending transfer request, the authenticated user must not be its requester
+func (server *Server) approveTransferRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ApproveTransferRequest(r.Context(), id)
+	if err != nil {
+		writeJSON(w, transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, approveTransferResponse{
+		Request:  newTransferRequestResponse(result.Request, result.Transfer.FromAccount.Currency),
+		Transfer: newTransferResultResponse(result.Transfer),
+	})
+}
+
+type approveTransferResponse struct {
+	Request  transferRequestResponse `json:"request"`
+	Transfer transferResultResponse  `json:"transfer"`
+}
+
+type rejectTransferRequest struct {
+	Reason string `json:"reason"`
+}
+
+// rejectTransferRequest rejects a pending transfer request, the authenticated user must not be its requester
+func (server *Server) rejectTransferRequest(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var request rejectTransferRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.RejectTransferRequest(r.Context(), id, request.Reason)
+	if err != nil {
+		writeJSON(w, transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(r.Context(), result.FromAccountID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newTransferRequestResponse(result, account.Currency))
+}
+
+type approvalThresholdRequest struct {
+	// Threshold is a decimal in the currency of the account, null to execute every transfer without approval
+	Threshold *json.Number `json:"threshold"`
+}
+
+// setApprovalThreshold sets the amount above which the transfers from an account of the authenticated user need an approval
+func (server *Server) setApprovalThreshold(w http.ResponseWriter, r *http.Request) {
+	account, role, status, err := server.heldAccount(r)
+	if err == nil && role != db.RoleOwner {
+		status, err = http.StatusForbidden, db.ErrNotAccountOwner
+	}
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	var request approvalThresholdRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	arg := db.SetAccountApprovalThresholdParams{ID: account.ID}
+	if request.Threshold != nil {
+		arg.ApprovalThreshold.Valid = true
+		arg.ApprovalThreshold.Int64, err = parseAmount(request.Threshold.String(), account.Currency)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse(err))
+			return
+		}
+	}
+
+	account, err = server.store.SetAccountApprovalThreshold(r.Context(), arg)
+	if err != nil {
+		writeJSON(w, transferErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAccountResponse(account))
+}
+
+// transferErrorStatus returns the status of an error of a transfer or a transfer request
+func transferErrorStatus(err error) int {
+	for _, forbidden := range []error{
+		db.ErrNotAccountHolder,
+		db.ErrViewerCannotTransfer,
+		db.ErrTransferLimitExceeded,
+		db.ErrKYCNotVerified,
+		db.ErrAccountFrozen,
+		db.ErrSelfApproval,
+		db.ErrNoReviewer,
+		db.ErrNotAccountOwner,
+	} {
+		if errors.Is(err, forbidden) {
+			return http.StatusForbidden
+		}
+	}
+
+	for _, invalid := range []error{
+		db.ErrReferenceTooLong,
+		db.ErrDescriptionTooLong,
+		db.ErrInvalidMetadata,
+		db.ErrMetadataTooLarge,
+		db.ErrNoRejectionReason,
+		db.ErrInvalidApprovalThreshold,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrTransferRequestNotPending), errors.Is(err, db.ErrTransferRequestExpired):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeTransferStore needs an approval above a threshold of 100 and keeps the requests in memory
+type fakeTransferStore struct {
+	fakePageStore
+
+	requests map[int64]db.TransferRequest
+}
+
+func (store *fakeTransferStore) RequestTransfer(ctx context.Context, params db.TransferTxParams) (db.RequestTransferResult, error) {
+	if params.Amount <= 100 {
+		return db.RequestTransferResult{Transfer: &db.TransferTxResult{}}, nil
+	}
+
+	request := db.TransferRequest{
+		ID:            int64(len(store.requests) + 1),
+		FromAccountID: params.FromAccountId,
+		ToAccountID:   params.ToAccountId,
+		Amount:        params.Amount,
+		Status:        db.TransferRequestPending,
+		RequestedBy:   db.ActorFromContext(ctx),
+	}
+	store.requests[request.ID] = request
+	return db.RequestTransferResult{Request: &request}, nil
+}
+
+func (store *fakeTransferStore) ApproveTransferRequest(ctx context.Context, id int64) (db.ApproveTransferRequestResult, error) {
+	request := store.requests[id]
+	if request.RequestedBy == db.ActorFromContext(ctx) {
+		return db.ApproveTransferRequestResult{}, db.ErrSelfApproval
+	}
+	if request.Status != db.TransferRequestPending {
+		return db.ApproveTransferRequestResult{}, db.ErrTransferRequestNotPending
+	}
+
+	request.Status = db.TransferRequestApproved
+	store.requests[id] = request
+	return db.ApproveTransferRequestResult{Request: request}, nil
+}
+
+func TestCreateTransferWithApproval(t *testing.T) {
+	store := &fakeTransferStore{
+		fakePageStore: fakePageStore{
+			accounts: map[int64]db.Account{
+				1: {ID: 1, Owner: "alice", Currency: "USD"},
+				2: {ID: 2, Owner: "bob", Currency: "USD"},
+				3: {ID: 3, Owner: "bob", Currency: "EUR"},
+			},
+			roles: map[int64]map[string]string{1: {"carol": db.RoleCoOwner}},
+		},
+		requests: map[int64]db.TransferRequest{},
+	}
+	server := newTestServer(t, store, nil)
+	aliceToken := createTestToken(t, server, "alice")
+	carolToken := createTestToken(t, server, "carol")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	testCases := []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{name: "Executed", body: `{"from_account_id": 1, "to_account_id": 2, "amount": 1}`, status: http.StatusCreated},
+		{name: "PendingApproval", body: `{"from_account_id": 1, "to_account_id": 2, "amount": "1.01"}`, status: http.StatusAccepted},
+		{name: "TooManyDecimals", body: `{"from_account_id": 1, "to_account_id": 2, "amount": 12.345}`, status: http.StatusBadRequest},
+		{name: "InvalidAmount", body: `{"from_account_id": 1, "to_account_id": 2, "amount": "1e3"}`, status: http.StatusBadRequest},
+		{name: "NotHolder", body: `{"from_account_id": 2, "to_account_id": 1, "amount": 10}`, status: http.StatusForbidden},
+		{name: "CurrencyMismatch", body: `{"from_account_id": 1, "to_account_id": 3, "amount": 10}`, status: http.StatusBadRequest},
+		{name: "NegativeAmount", body: `{"from_account_id": 1, "to_account_id": 2, "amount": -10}`, status: http.StatusBadRequest},
+		{name: "UnknownReceiver", body: `{"from_account_id": 1, "to_account_id": 9, "amount": 10}`, status: http.StatusNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := send(http.MethodPost, "/transfers", aliceToken, tc.body)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	require.Len(t, store.requests, 1)
+	require.Equal(t, int64(101), store.requests[1].Amount)
+
+	recorder := send(http.MethodPost, "/transfer-requests/1/approve", aliceToken, "")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+
+	recorder = send(http.MethodPost, "/transfer-requests/1/approve", carolToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, db.TransferRequestApproved, store.requests[1].Status)
+
+	recorder = send(http.MethodPost, "/transfer-requests/1/approve", carolToken, "")
+	require.Equal(t, http.StatusConflict, recorder.Code)
+
+	recorder = send(http.MethodPut, "/accounts/1/approval-threshold", carolToken, `{"threshold": 500}`)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+type openWalletRequest struct {
+	CustomerID int64    `json:"customer_id"`
+	Owner      string   `json:"owner"`
+	Currencies []string `json:"currencies"`
+}
+
+// openWallet opens a wallet of a customer with a balance per currency
+func (server *Server) openWallet(w http.ResponseWriter, r *http.Request) {
+	var request openWalletRequest
+	err := readJSON(r, &request)
+	if err == nil && (request.CustomerID <= 0 || request.Owner == "") {
+		err = errors.New("customer_id and owner are required")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.OpenWallet(r.Context(), db.OpenWalletParams{
+		CustomerID: request.CustomerID,
+		Owner:      request.Owner,
+		Currencies: request.Currencies,
+	})
+	if err != nil {
+		writeJSON(w, walletErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newOpenWalletResponse(result))
+}
+
+type walletCurrencyRequest struct {
+	Currency string `json:"currency"`
+}
+
+// addWalletCurrency opens a balance in a new currency in the wallet of the {id} path value
+func (server *Server) addWalletCurrency(w http.ResponseWriter, r *http.Request) {
+	id, err := pathID(r)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	var request walletCurrencyRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	account, err := server.store.AddWalletCurrency(r.Context(), id, request.Currency)
+	if err != nil {
+		writeJSON(w, walletErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newAccountResponse(account))
+}
+
+// getWalletBalance returns the balances of a wallet of the authenticated user,
+// with their total in the currency of the query, like ?currency=EUR
+func (server *Server) getWalletBalance(w http.ResponseWriter, r *http.Request) {
+	wallet, status, err := server.ownedWallet(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		writeJSON(w, http.StatusBadRequest, errorResponse(errors.New("currency is required")))
+		return
+	}
+
+	balance, err := server.store.ConsolidatedBalance(r.Context(), wallet.ID, currency)
+	if err != nil {
+		writeJSON(w, walletErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newWalletBalanceResponse(balance))
+}
+
+type convertCurrencyRequest struct {
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	// Amount is a decimal in the from currency, like 12.34
+	Amount json.Number `json:"amount"`
+}
+
+// convertCurrency converts money between two balances of a wallet of the authenticated user
+func (server *Server) convertCurrency(w http.ResponseWriter, r *http.Request) {
+	wallet, status, err := server.ownedWallet(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	var request convertCurrencyRequest
+	err = readJSON(r, &request)
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	amount, err := parseAmount(request.Amount.String(), request.FromCurrency)
+	if err == nil && amount <= 0 {
+		err = errors.New("amount must be positive")
+	}
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	result, err := server.store.ConvertCurrency(r.Context(), db.ConvertCurrencyParams{
+		WalletID:     wallet.ID,
+		FromCurrency: request.FromCurrency,
+		ToCurrency:   request.ToCurrency,
+		Amount:       amount,
+	})
+	if err != nil {
+		writeJSON(w, walletErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, newConversionResponse(result))
+}
+
+// ownedWallet returns the wallet of the {id} path value, the authenticated user must own it
+func (server *Server) ownedWallet(r *http.Request) (db.Wallet, int, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return db.Wallet{}, http.StatusBadRequest, err
+	}
+
+	wallet, err := server.store.GetWallet(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return wallet, http.StatusNotFound, fmt.Errorf("wallet %d not found", id)
+	}
+	if err != nil {
+		return wallet, http.StatusInternalServerError, err
+	}
+
+	if wallet.Owner != authPayload(r).Username {
+		return wallet, http.StatusForbidden, errors.New("wallet doesn't belong to the authenticated user")
+	}
+	return wallet, http.StatusOK, nil
+}
+
+func walletErrorStatus(err error) int {
+	for _, invalid := range []error{
+		money.ErrUnknownCurrency,
+		db.ErrNoWalletCurrencies,
+		db.ErrNoWalletCurrency,
+		db.ErrSameCurrencyConversion,
+		db.ErrNonPositiveConversion,
+		db.ErrConversionTooSmall,
+	} {
+		if errors.Is(err, invalid) {
+			return http.StatusBadRequest
+		}
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return http.StatusNotFound
+	case errors.Is(err, db.ErrWalletCurrencyExists):
+		return http.StatusConflict
+	case errors.Is(err, db.ErrNoFXRate), errors.Is(err, db.ErrStaleFXRate), errors.Is(err, db.ErrNoFXAccount):
+		return http.StatusUnprocessableEntity
+	default:
+		// the conversions are checked like the transfers
+		return transferErrorStatus(err)
+	}
+}
@@ -0,0 +1,127 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeWalletStore has the wallet 1 of alice and converts at a rate of 2
+type fakeWalletStore struct {
+	Store
+
+	conversion db.ConvertCurrencyParams
+	actor      string
+}
+
+func (store *fakeWalletStore) GetWallet(_ context.Context, id int64) (db.Wallet, error) {
+	if id != 1 {
+		return db.Wallet{}, sql.ErrNoRows
+	}
+	return db.Wallet{ID: 1, CustomerID: 1, Owner: "alice"}, nil
+}
+
+func (store *fakeWalletStore) ConvertCurrency(ctx context.Context, params db.ConvertCurrencyParams) (db.ConvertCurrencyResult, error) {
+	store.conversion = params
+	store.actor = db.ActorFromContext(ctx)
+
+	return db.ConvertCurrencyResult{
+		Conversion:  db.Conversion{WalletID: params.WalletID, FromAmount: params.Amount, ToAmount: params.Amount * 2, Rate: "2"},
+		FromAccount: db.Account{ID: 1, Currency: params.FromCurrency, Balance: -params.Amount},
+		ToAccount:   db.Account{ID: 2, Currency: params.ToCurrency, Balance: params.Amount * 2},
+	}, nil
+}
+
+func (store *fakeWalletStore) ConsolidatedBalance(_ context.Context, walletId int64, currency string) (db.WalletBalance, error) {
+	if currency != "EUR" {
+		return db.WalletBalance{}, db.ErrNoFXRate
+	}
+
+	return db.WalletBalance{
+		Wallet:            db.Wallet{ID: walletId},
+		ReportingCurrency: currency,
+		Total:             1500,
+		Balances: []db.WalletCurrencyBalance{
+			{Account: db.Account{ID: 1, Currency: "EUR", Balance: 500}, Rate: "1", Converted: 500},
+			{Account: db.Account{ID: 2, Currency: "USD", Balance: 2000}, Rate: "0.5", Converted: 1000},
+		},
+	}, nil
+}
+
+func TestWallet(t *testing.T) {
+	store := &fakeWalletStore{}
+	server := newTestServer(t, store, nil)
+	aliceToken := createTestToken(t, server, "alice")
+	bobToken := createTestToken(t, server, "bob")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	testCases := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		body   string
+		status int
+	}{
+		{name: "Balance", method: http.MethodGet, path: "/wallets/1/balance?currency=EUR", token: aliceToken, status: http.StatusOK},
+		{name: "BalanceNoCurrency", method: http.MethodGet, path: "/wallets/1/balance", token: aliceToken, status: http.StatusBadRequest},
+		{name: "BalanceNoRate", method: http.MethodGet, path: "/wallets/1/balance?currency=GBP", token: aliceToken, status: http.StatusUnprocessableEntity},
+		{name: "BalanceOtherOwner", method: http.MethodGet, path: "/wallets/1/balance?currency=EUR", token: bobToken, status: http.StatusForbidden},
+		{name: "BalanceNotFound", method: http.MethodGet, path: "/wallets/2/balance?currency=EUR", token: aliceToken, status: http.StatusNotFound},
+		{
+			name:   "ConvertTooManyDecimals",
+			method: http.MethodPost,
+			path:   "/wallets/1/conversions",
+			token:  aliceToken,
+			body:   `{"from_currency": "EUR", "to_currency": "USD", "amount": 1.005}`,
+			status: http.StatusBadRequest,
+		},
+		{
+			name:   "ConvertOtherOwner",
+			method: http.MethodPost,
+			path:   "/wallets/1/conversions",
+			token:  bobToken,
+			body:   `{"from_currency": "EUR", "to_currency": "USD", "amount": 10}`,
+			status: http.StatusForbidden,
+		},
+		{name: "OpenWalletNotAdmin", method: http.MethodPost, path: "/admin/wallets", token: aliceToken, body: `{}`, status: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := send(tc.method, tc.path, tc.token, tc.body)
+			require.Equal(t, tc.status, recorder.Code)
+		})
+	}
+
+	recorder := send(http.MethodPost, "/wallets/1/conversions", aliceToken, `{"from_currency": "EUR", "to_currency": "USD", "amount": "12.34"}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+	require.Equal(t, db.ConvertCurrencyParams{WalletID: 1, FromCurrency: "EUR", ToCurrency: "USD", Amount: 1234}, store.conversion)
+	require.Equal(t, "alice", store.actor)
+
+	var conversion conversionResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &conversion))
+	require.Equal(t, "24.68 USD", conversion.ToAmount.String())
+
+	recorder = send(http.MethodGet, "/wallets/1/balance?currency=EUR", aliceToken, "")
+	var balance walletBalanceResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &balance))
+	require.Equal(t, "15.00 EUR", balance.Total.String())
+	require.Len(t, balance.Balances, 2)
+	require.Equal(t, "20.00 USD", balance.Balances[1].Account.Balance.String())
+	require.Equal(t, "10.00 EUR", balance.Balances[1].Converted.String())
+}
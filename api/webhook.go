@@ -0,0 +1,144 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/webhook"
+)
+
+var errWebhookNotFound = errors.New("webhook subscription not found")
+
+type createWebhookRequest struct {
+	URL string `json:"url"`
+	// EventTypes filters the events delivered, empty for every event type
+	EventTypes []string `json:"event_types"`
+}
+
+// webhookResponse leaves the secret out, it's only returned when the subscription is created
+type webhookResponse struct {
+	ID                  int64      `json:"id"`
+	URL                 string     `json:"url"`
+	EventTypes          []string   `json:"event_types"`
+	Active              bool       `json:"active"`
+	ConsecutiveFailures int32      `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at"`
+	CreatedAt           time.Time  `json:"created_at"`
+	Secret              string     `json:"secret,omitempty"`
+}
+
+func newWebhookResponse(subscription db.WebhookSubscription) webhookResponse {
+	response := webhookResponse{
+		ID:                  subscription.ID,
+		URL:                 subscription.Url,
+		EventTypes:          subscription.EventTypes,
+		Active:              subscription.Active,
+		ConsecutiveFailures: subscription.ConsecutiveFailures,
+		CreatedAt:           subscription.CreatedAt,
+	}
+	if response.EventTypes == nil {
+		response.EventTypes = []string{}
+	}
+	if subscription.DisabledAt.Valid {
+		response.DisabledAt = &subscription.DisabledAt.Time
+	}
+	return response
+}
+
+// createWebhook subscribes an URL to the events of the accounts of the authenticated user.
+// The response is the only one with the secret signing the deliveries.
+func (server *Server) createWebhook(w http.ResponseWriter, r *http.Request) {
+	var request createWebhookRequest
+	if err := readJSON(r, &request); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse(err))
+		return
+	}
+
+	subscription, err := webhook.CreateSubscription(r.Context(), server.store, authPayload(r).Username, request.URL, request.EventTypes)
+	if err != nil {
+		writeJSON(w, webhookErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	response := newWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	writeJSON(w, http.StatusCreated, response)
+}
+
+// listWebhooks returns the subscriptions of the authenticated user
+func (server *Server) listWebhooks(w http.ResponseWriter, r *http.Request) {
+	subscriptions, err := server.store.ListWebhookSubscriptions(r.Context(), authPayload(r).Username)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	response := []webhookResponse{}
+	for _, subscription := range subscriptions {
+		response = append(response, newWebhookResponse(subscription))
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// deleteWebhook deletes a subscription of the authenticated user with its pending deliveries
+func (server *Server) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, status, err := server.ownedWebhook(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	if err := server.store.DeleteWebhookSubscription(r.Context(), subscription.ID); err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enableWebhook enables again a subscription disabled after repeated failures,
+// it receives the events from now on, the ones dropped while it was disabled aren't delivered
+func (server *Server) enableWebhook(w http.ResponseWriter, r *http.Request) {
+	subscription, status, err := server.ownedWebhook(r)
+	if err != nil {
+		writeJSON(w, status, errorResponse(err))
+		return
+	}
+
+	subscription, err = server.store.EnableWebhookSubscription(r.Context(), subscription.ID)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse(err))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newWebhookResponse(subscription))
+}
+
+// ownedWebhook returns the {id} subscription, the subscriptions of the other users are not found
+func (server *Server) ownedWebhook(r *http.Request) (db.WebhookSubscription, int, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return db.WebhookSubscription{}, http.StatusBadRequest, err
+	}
+
+	subscription, err := server.store.GetWebhookSubscription(r.Context(), id)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && subscription.Owner != authPayload(r).Username) {
+		return db.WebhookSubscription{}, http.StatusNotFound, errWebhookNotFound
+	}
+	if err != nil {
+		return db.WebhookSubscription{}, http.StatusInternalServerError, err
+	}
+	return subscription, http.StatusOK, nil
+}
+
+func webhookErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, webhook.ErrInvalidURL), errors.Is(err, webhook.ErrInvalidEventType):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
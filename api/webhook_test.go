@@ -0,0 +1,135 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeWebhookStore keeps the subscriptions in memory
+type fakeWebhookStore struct {
+	Store
+	subscriptions map[int64]db.WebhookSubscription
+}
+
+func (store *fakeWebhookStore) CreateWebhookSubscription(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+	subscription := db.WebhookSubscription{
+		ID:         int64(len(store.subscriptions) + 1),
+		Owner:      arg.Owner,
+		Url:        arg.Url,
+		Secret:     arg.Secret,
+		EventTypes: arg.EventTypes,
+		Active:     true,
+	}
+	store.subscriptions[subscription.ID] = subscription
+	return subscription, nil
+}
+
+func (store *fakeWebhookStore) GetWebhookSubscription(_ context.Context, id int64) (db.WebhookSubscription, error) {
+	subscription, ok := store.subscriptions[id]
+	if !ok {
+		return db.WebhookSubscription{}, sql.ErrNoRows
+	}
+	return subscription, nil
+}
+
+func (store *fakeWebhookStore) ListWebhookSubscriptions(_ context.Context, owner string) ([]db.WebhookSubscription, error) {
+	var subscriptions []db.WebhookSubscription
+	for id := int64(1); id <= int64(len(store.subscriptions)); id++ {
+		if subscription, ok := store.subscriptions[id]; ok && subscription.Owner == owner {
+			subscriptions = append(subscriptions, subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (store *fakeWebhookStore) DeleteWebhookSubscription(_ context.Context, id int64) error {
+	delete(store.subscriptions, id)
+	return nil
+}
+
+func (store *fakeWebhookStore) EnableWebhookSubscription(_ context.Context, id int64) (db.WebhookSubscription, error) {
+	subscription := store.subscriptions[id]
+	subscription.Active = true
+	subscription.ConsecutiveFailures = 0
+	subscription.DisabledAt = sql.NullTime{}
+	store.subscriptions[id] = subscription
+	return subscription, nil
+}
+
+func TestWebhookSubscriptions(t *testing.T) {
+	store := &fakeWebhookStore{subscriptions: make(map[int64]db.WebhookSubscription)}
+	server := newTestServer(t, store, nil)
+	aliceToken := createTestToken(t, server, "alice")
+	bobToken := createTestToken(t, server, "bob")
+
+	send := func(method, path, token, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.Header.Set(authorizationHeaderKey, "Bearer "+token)
+		recorder := httptest.NewRecorder()
+		server.Handler().ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send(http.MethodPost, "/webhooks", aliceToken, `{"url": "ftp://example.com"}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodPost, "/webhooks", aliceToken, `{"url": "https://example.com/hook", "event_types": ["AccountClosed"]}`)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	recorder = send(http.MethodPost, "/webhooks", aliceToken, `{"url": "https://example.com/hook", "event_types": ["AccountCredited"]}`)
+	require.Equal(t, http.StatusCreated, recorder.Code)
+
+	var created webhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &created))
+	require.Equal(t, "https://example.com/hook", created.URL)
+	require.Equal(t, []string{db.EventAccountCredited}, created.EventTypes)
+	require.True(t, created.Active)
+	require.NotEmpty(t, created.Secret)
+
+	// the secret is only returned at creation
+	recorder = send(http.MethodGet, "/webhooks", aliceToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var listed []webhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &listed))
+	require.Len(t, listed, 1)
+	require.Equal(t, created.ID, listed[0].ID)
+	require.Empty(t, listed[0].Secret)
+
+	recorder = send(http.MethodGet, "/webhooks", bobToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `[]`, recorder.Body.String())
+
+	// a disabled subscription is enabled again by its owner only
+	subscription := store.subscriptions[created.ID]
+	subscription.Active = false
+	subscription.ConsecutiveFailures = 5
+	store.subscriptions[created.ID] = subscription
+
+	recorder = send(http.MethodPost, "/webhooks/1/enable", bobToken, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = send(http.MethodPost, "/webhooks/1/enable", aliceToken, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var enabled webhookResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &enabled))
+	require.True(t, enabled.Active)
+	require.Zero(t, enabled.ConsecutiveFailures)
+
+	recorder = send(http.MethodDelete, "/webhooks/1", bobToken, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	recorder = send(http.MethodDelete, "/webhooks/1", aliceToken, "")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Empty(t, store.subscriptions)
+
+	recorder = send(http.MethodDelete, "/webhooks/1", aliceToken, "")
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
@@ -0,0 +1,353 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+// errLedgerUnbalanced is returned by ledger check when the check was printed but found a problem
+var errLedgerUnbalanced = errors.New("the ledger is not balanced")
+
+// app is what the commands run with
+type app struct {
+	ctx   context.Context
+	store *db.Store
+	out   output
+	// actor is the operator recorded in the audit log
+	actor string
+}
+
+// command is a subcommand, named by one or two words like "balance" or "account create"
+type command struct {
+	name  string
+	usage string
+	run   func(app *app, args []string) error
+}
+
+var commands = []command{
+	{"account create", "account create -owner name -currency code", createAccount},
+	{"account list", "account list [-limit n] [-offset n]", listAccounts},
+	{"account freeze", "account freeze <account id>", freezeAccount},
+	{"account unfreeze", "account unfreeze <account id>", unfreezeAccount},
+	{"balance", "balance <account id>", showBalance},
+	{"statement", "statement [-from date] [-to date] <account id>", showStatement},
+	{"transfer execute", "transfer execute -from id -to id -amount decimal [-reference text] [-description text]", executeTransfer},
+	{"transfer reverse", "transfer reverse -reason text <transfer id>", reverseTransfer},
+	{"adjust", "adjust -amount decimal -reason text <account id>", adjustBalance},
+	{"ledger check", "ledger check", checkLedger},
+}
+
+// findCommand returns the command named by the first one or two arguments, and the arguments left
+func findCommand(args []string) (command, []string, error) {
+	for _, cmd := range commands {
+		words := strings.Fields(cmd.name)
+		if len(args) >= len(words) && strings.Join(args[:len(words)], " ") == cmd.name {
+			return cmd, args[len(words):], nil
+		}
+	}
+	return command{}, nil, fmt.Errorf("unknown command %q, run bankctl -h for the commands", strings.Join(args, " "))
+}
+
+// parseFlags parses the flags of the command and returns its positional arguments, which must be wanted of them
+func parseFlags(fs *flag.FlagSet, args []string, wanted int) ([]string, error) {
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != wanted {
+		return nil, fmt.Errorf("%s: expected %d argument(s), got %d", fs.Name(), wanted, fs.NArg())
+	}
+	return fs.Args(), nil
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet(name, flag.ContinueOnError)
+}
+
+// parseID parses the ID of an account or a transfer
+func parseID(value string) (int64, error) {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid ID %q", value)
+	}
+	return id, nil
+}
+
+// parseDate parses a date like 2024-01-31, at midnight UTC, or a time like 2024-01-31T12:00:00Z
+func parseDate(value string) (time.Time, error) {
+	if date, err := time.Parse(time.DateOnly, value); err == nil {
+		return date, nil
+	}
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected 2006-01-02 or RFC 3339", value)
+	}
+	return date, nil
+}
+
+// statementPeriod returns the period of a statement, from the start of the month of to by default
+func statementPeriod(from, to string, now time.Time) (time.Time, time.Time, error) {
+	end := now
+	if to != "" {
+		var err error
+		if end, err = parseDate(to); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+
+	start := time.Date(end.Year(), end.Month(), 1, 0, 0, 0, 0, end.Location())
+	if from != "" {
+		var err error
+		if start, err = parseDate(from); err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+	}
+	return start, end, nil
+}
+
+// withActor returns the context of the mutations made by the operator
+func (app *app) withActor() context.Context {
+	return db.WithActor(app.ctx, app.actor)
+}
+
+// asOperator returns the context of the mutations the operator makes as the bank,
+// they are audited as made by the operator but the checks of the users don't apply
+func (app *app) asOperator() context.Context {
+	return db.WithOperator(app.ctx, app.actor)
+}
+
+func createAccount(app *app, args []string) error {
+	fs := newFlagSet("account create")
+	owner := fs.String("owner", "", "owner of the account, required")
+	currency := fs.String("currency", "", "currency code of the account, required")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *owner == "" {
+		return errors.New("account create: -owner is required")
+	}
+	if _, err := money.LookupCurrency(*currency); err != nil {
+		return fmt.Errorf("account create: %w", err)
+	}
+
+	account, err := app.store.CreateAccount(app.withActor(), db.CreateAccountParams{
+		Owner:    *owner,
+		Currency: *currency,
+	})
+	if err != nil {
+		return err
+	}
+	return app.out.account(account)
+}
+
+func listAccounts(app *app, args []string) error {
+	fs := newFlagSet("account list")
+	limit := fs.Int("limit", 50, "maximum number of accounts")
+	offset := fs.Int("offset", 0, "number of accounts to skip")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	accounts, err := app.store.ListAccounts(app.ctx, db.ListAccountsParams{
+		Limit:  int32(*limit),
+		Offset: int32(*offset),
+	})
+	if err != nil {
+		return err
+	}
+	return app.out.accounts(accounts)
+}
+
+func freezeAccount(app *app, args []string) error {
+	return setAccountFrozen(app, "account freeze", args, app.store.FreezeAccount)
+}
+
+func unfreezeAccount(app *app, args []string) error {
+	return setAccountFrozen(app, "account unfreeze", args, app.store.UnfreezeAccount)
+}
+
+func setAccountFrozen(app *app, name string, args []string, set func(context.Context, int64) (db.Account, error)) error {
+	positional, err := parseFlags(newFlagSet(name), args, 1)
+	if err != nil {
+		return err
+	}
+	accountId, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	account, err := set(app.withActor(), accountId)
+	if err != nil {
+		return err
+	}
+	return app.out.account(account)
+}
+
+func showBalance(app *app, args []string) error {
+	positional, err := parseFlags(newFlagSet("balance"), args, 1)
+	if err != nil {
+		return err
+	}
+	accountId, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	account, err := app.store.GetAccount(app.ctx, accountId)
+	if err != nil {
+		return err
+	}
+	return app.out.balance(account)
+}
+
+func showStatement(app *app, args []string) error {
+	fs := newFlagSet("statement")
+	from := fs.String("from", "", "start of the period, included, defaults to the start of the month of -to")
+	to := fs.String("to", "", "end of the period, excluded, defaults to now")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	accountId, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	start, end, err := statementPeriod(*from, *to, time.Now())
+	if err != nil {
+		return err
+	}
+
+	statement, err := app.store.AccountStatement(app.ctx, accountId, start, end)
+	if err != nil {
+		return err
+	}
+	return app.out.statement(statement)
+}
+
+// executeTransfer transfers as the bank itself, so the roles of the holders and the approval thresholds don't apply
+func executeTransfer(app *app, args []string) error {
+	fs := newFlagSet("transfer execute")
+	from := fs.Int64("from", 0, "sender account ID, required")
+	to := fs.Int64("to", 0, "receiver account ID, required")
+	amount := fs.String("amount", "", "decimal amount in the currency of the sender, like 12.34, required")
+	reference := fs.String("reference", "", "reference of the transfer")
+	description := fs.String("description", "", "description of the transfer")
+	if _, err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+
+	if *from < 1 || *to < 1 {
+		return errors.New("transfer execute: -from and -to are required")
+	}
+
+	sender, err := app.store.GetAccount(app.ctx, *from)
+	if err != nil {
+		return fmt.Errorf("sender account %d: %w", *from, err)
+	}
+	receiver, err := app.store.GetAccount(app.ctx, *to)
+	if err != nil {
+		return fmt.Errorf("receiver account %d: %w", *to, err)
+	}
+	if sender.Currency != receiver.Currency {
+		return fmt.Errorf("account %d currency mismatch: %s vs %s", *to, receiver.Currency, sender.Currency)
+	}
+
+	value, err := money.ParseAmount(*amount, sender.Currency)
+	if err != nil {
+		return fmt.Errorf("transfer execute: %w", err)
+	}
+	if value.Amount <= 0 {
+		return fmt.Errorf("transfer execute: amount must be positive, got %s", value)
+	}
+
+	result, err := app.store.TransferTX(app.asOperator(), db.TransferTxParams{
+		FromAccountId: *from,
+		ToAccountId:   *to,
+		Amount:        value.Amount,
+		Reference:     *reference,
+		Description:   *description,
+	})
+	if err != nil {
+		return err
+	}
+	return app.out.transfer(result, sender.Currency)
+}
+
+func reverseTransfer(app *app, args []string) error {
+	fs := newFlagSet("transfer reverse")
+	reason := fs.String("reason", "", "reason of the reversal, required")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	transferId, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	result, err := app.store.ReverseTransfer(app.ctx, transferId, *reason, app.actor)
+	if err != nil {
+		return err
+	}
+	return app.out.reversal(result)
+}
+
+// adjustBalance corrects the balance of an account against the suspense account of its currency,
+// a negative amount debits the account
+func adjustBalance(app *app, args []string) error {
+	fs := newFlagSet("adjust")
+	amount := fs.String("amount", "", "signed decimal amount in the currency of the account, like -12.34, required")
+	reason := fs.String("reason", "", "reason of the adjustment, required")
+	positional, err := parseFlags(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	accountId, err := parseID(positional[0])
+	if err != nil {
+		return err
+	}
+
+	account, err := app.store.GetAccount(app.ctx, accountId)
+	if err != nil {
+		return fmt.Errorf("account %d: %w", accountId, err)
+	}
+
+	value, err := money.ParseAmount(*amount, account.Currency)
+	if err != nil {
+		return fmt.Errorf("adjust: %w", err)
+	}
+
+	result, err := app.store.AdjustBalance(app.ctx, accountId, value.Amount, *reason, app.actor)
+	if err != nil {
+		return err
+	}
+	return app.out.adjustment(result)
+}
+
+func checkLedger(app *app, args []string) error {
+	if _, err := parseFlags(newFlagSet("ledger check"), args, 0); err != nil {
+		return err
+	}
+
+	check, err := app.store.CheckLedger(app.ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := app.out.ledgerCheck(check); err != nil {
+		return err
+	}
+	if !check.Balanced() {
+		return errLedgerUnbalanced
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCommand(t *testing.T) {
+	cmd, args, err := findCommand([]string{"account", "freeze", "12"})
+	require.NoError(t, err)
+	require.Equal(t, "account freeze", cmd.name)
+	require.Equal(t, []string{"12"}, args)
+
+	cmd, args, err = findCommand([]string{"balance", "3"})
+	require.NoError(t, err)
+	require.Equal(t, "balance", cmd.name)
+	require.Equal(t, []string{"3"}, args)
+
+	_, _, err = findCommand([]string{"account"})
+	require.ErrorContains(t, err, "unknown command")
+
+	_, _, err = findCommand([]string{"account", "delete", "1"})
+	require.ErrorContains(t, err, "unknown command")
+}
+
+func TestParseID(t *testing.T) {
+	id, err := parseID("42")
+	require.NoError(t, err)
+	require.Equal(t, int64(42), id)
+
+	for _, invalid := range []string{"", "0", "-1", "abc"} {
+		_, err := parseID(invalid)
+		require.Error(t, err, invalid)
+	}
+}
+
+func TestStatementPeriod(t *testing.T) {
+	now := time.Date(2024, 3, 15, 10, 0, 0, 0, time.UTC)
+
+	start, end, err := statementPeriod("", "", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, now, end)
+
+	start, end, err = statementPeriod("2024-01-01", "2024-02-01T12:00:00Z", now)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), start)
+	require.Equal(t, time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC), end)
+
+	_, _, err = statementPeriod("yesterday", "", now)
+	require.ErrorContains(t, err, "invalid date")
+}
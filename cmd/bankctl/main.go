@@ -0,0 +1,111 @@
+// bankctl is the command line of the bank operators. Its commands go through db.Store like the API,
+// so the mutations are checked and written to the audit log, as made by the -actor.
+//
+//	bankctl [-db source] [-output table|json] [-actor name] <command> [arguments]
+//
+// The commands are:
+//
+//	account create -owner name -currency code
+//	account list [-limit n] [-offset n]
+//	account freeze <account id>
+//	account unfreeze <account id>
+//	balance <account id>
+//	statement [-from date] [-to date] <account id>
+//	transfer execute -from id -to id -amount decimal [-reference text] [-description text]
+//	transfer reverse -reason text <transfer id>
+//	adjust -amount decimal -reason text <account id>
+//	ledger check
+//
+// ledger check exits with status 2 when the ledger is not balanced.
+// The database, the fee schedule and the accounts of the bank are configured like the server,
+// by app.env and the environment, -db overrides DB_SOURCE.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("bankctl: cannot load config: ", err)
+	}
+
+	source := flag.String("db", config.DBSource, "database connection string")
+	format := flag.String("output", formatTable, "table or json")
+	actor := flag.String("actor", os.Getenv("USER"), "operator recorded in the audit log")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(2)
+	}
+
+	config.DBSource = *source
+	err = run(config, *format, *actor, flag.Args())
+	if errors.Is(err, errLedgerUnbalanced) {
+		os.Exit(2)
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		log.Fatal("bankctl: ", err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(flag.CommandLine.Output(), "usage: bankctl [flags] <command> [arguments]\n\nflags:")
+	flag.PrintDefaults()
+	fmt.Fprintln(flag.CommandLine.Output(), "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(flag.CommandLine.Output(), "  %s\n", cmd.usage)
+	}
+}
+
+func run(config util.Config, format, actor string, args []string) error {
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("unknown output %q", format)
+	}
+
+	cmd, args, err := findCommand(args)
+	if err != nil {
+		return err
+	}
+
+	feeSchedule, err := db.NewFeeSchedule(config.TransferFees, config.FeeRevenueAccounts)
+	if err != nil {
+		return fmt.Errorf("invalid fee schedule: %w", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn,
+		db.WithFeeSchedule(feeSchedule),
+		db.WithInternalAccounts(config.InternalAccounts),
+		db.WithFXAccounts(config.FXAccounts),
+		db.WithSuspenseAccounts(config.SuspenseAccounts),
+	)
+
+	return cmd.run(&app{
+		ctx:   context.Background(),
+		store: store,
+		out:   output{w: os.Stdout, format: format},
+		actor: actor,
+	}, args)
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+// Output formats
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// output prints the results of the commands as aligned tables or as indented JSON.
+// The tables format the amounts in their currency, the JSON keeps the store values with their minor units.
+type output struct {
+	w      io.Writer
+	format string
+}
+
+// print writes the value as JSON, or the header and the rows as a table
+func (out output) print(value interface{}, header []string, rows [][]string) error {
+	if out.format == formatJSON {
+		encoder := json.NewEncoder(out.w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	tw := tabwriter.NewWriter(out.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+var accountHeader = []string{"ID", "OWNER", "CURRENCY", "BALANCE", "FROZEN AT", "CREATED AT"}
+
+func accountRow(account db.Account) []string {
+	return []string{
+		strconv.FormatInt(account.ID, 10),
+		account.Owner,
+		account.Currency,
+		formatAmount(account.Balance, account.Currency),
+		formatNullTime(account.FrozenAt),
+		formatTime(account.CreatedAt),
+	}
+}
+
+// account prints a single account, as a JSON object
+func (out output) account(account db.Account) error {
+	return out.print(account, accountHeader, [][]string{accountRow(account)})
+}
+
+// accounts prints a list of accounts, as a JSON array
+func (out output) accounts(accounts []db.Account) error {
+	rows := make([][]string, len(accounts))
+	for i, account := range accounts {
+		rows[i] = accountRow(account)
+	}
+	if accounts == nil {
+		accounts = []db.Account{}
+	}
+	return out.print(accounts, accountHeader, rows)
+}
+
+type balanceView struct {
+	AccountID int64       `json:"account_id"`
+	Balance   money.Money `json:"balance"`
+	Frozen    bool        `json:"frozen"`
+}
+
+func (out output) balance(account db.Account) error {
+	view := balanceView{
+		AccountID: account.ID,
+		Balance:   money.Money{Amount: account.Balance, Currency: account.Currency},
+		Frozen:    account.FrozenAt.Valid,
+	}
+	return out.print(view, []string{"ID", "BALANCE", "FROZEN"}, [][]string{{
+		strconv.FormatInt(view.AccountID, 10),
+		view.Balance.String(),
+		strconv.FormatBool(view.Frozen),
+	}})
+}
+
+func (out output) statement(statement db.Statement) error {
+	currency := statement.Account.Currency
+
+	rows := [][]string{{formatTime(statement.PeriodStart), "", "", "", "opening balance", "", formatAmount(statement.OpeningBalance, currency)}}
+	for _, line := range statement.Lines {
+		transfer, counterparty := "", ""
+		if line.TransferID.Valid {
+			transfer = strconv.FormatInt(line.TransferID.Int64, 10)
+			counterparty = strconv.FormatInt(line.CounterpartyID, 10)
+		}
+		rows = append(rows, []string{
+			formatTime(line.CreatedAt),
+			strconv.FormatInt(line.ID, 10),
+			transfer,
+			counterparty,
+			line.Reference,
+			formatAmount(line.Amount, currency),
+			formatAmount(line.Balance, currency),
+		})
+	}
+	rows = append(rows, []string{formatTime(statement.PeriodEnd), "", "", "", "closing balance", "", formatAmount(statement.ClosingBalance, currency)})
+
+	return out.print(statement, []string{"DATE", "ENTRY", "TRANSFER", "COUNTERPARTY", "REFERENCE", "AMOUNT", "BALANCE"}, rows)
+}
+
+// transfer prints the transfer of the result, its amount and fee are in the currency of the sender
+func (out output) transfer(result db.TransferTxResult, currency string) error {
+	transfer := result.Transfer
+	return out.print(result, []string{"ID", "FROM", "TO", "AMOUNT", "FEE", "REFERENCE", "CREATED AT"}, [][]string{{
+		strconv.FormatInt(transfer.ID, 10),
+		strconv.FormatInt(transfer.FromAccountID, 10),
+		strconv.FormatInt(transfer.ToAccountID, 10),
+		formatAmount(transfer.Amount, currency),
+		formatAmount(transfer.Fee, currency),
+		transfer.Reference,
+		formatTime(transfer.CreatedAt),
+	}})
+}
+
+func (out output) reversal(result db.ReverseTransferResult) error {
+	transfer := result.Transfer.Transfer
+	currency := result.Transfer.FromAccount.Currency
+	return out.print(result, []string{"ID", "REVERSES", "FROM", "TO", "AMOUNT", "REASON", "ACTOR"}, [][]string{{
+		strconv.FormatInt(transfer.ID, 10),
+		strconv.FormatInt(result.Reversal.TransferID, 10),
+		strconv.FormatInt(transfer.FromAccountID, 10),
+		strconv.FormatInt(transfer.ToAccountID, 10),
+		formatAmount(transfer.Amount, currency),
+		result.Reversal.Reason,
+		result.Reversal.Actor,
+	}})
+}
+
+func (out output) adjustment(result db.AdjustBalanceResult) error {
+	adjustment := result.Adjustment
+	currency := result.Account.Currency
+	return out.print(result, []string{"ID", "ACCOUNT", "SUSPENSE", "AMOUNT", "BALANCE", "REASON", "ACTOR"}, [][]string{{
+		strconv.FormatInt(adjustment.ID, 10),
+		strconv.FormatInt(adjustment.AccountID, 10),
+		strconv.FormatInt(adjustment.SuspenseAccountID, 10),
+		formatAmount(adjustment.Amount, currency),
+		formatAmount(result.Account.Balance, currency),
+		adjustment.Reason,
+		adjustment.Actor,
+	}})
+}
+
+// ledgerCheck prints a row for every problem found, the transfers have no currency so all the amounts are in minor units
+func (out output) ledgerCheck(check db.LedgerCheck) error {
+	if out.format == formatTable && check.Balanced() {
+		_, err := fmt.Fprintln(out.w, "the ledger is balanced")
+		return err
+	}
+
+	var rows [][]string
+	for _, account := range check.Accounts {
+		rows = append(rows, []string{"account", strconv.FormatInt(account.ID, 10), account.Currency,
+			strconv.FormatInt(account.Balance, 10), strconv.FormatInt(account.EntriesTotal, 10)})
+	}
+	for _, transfer := range check.Transfers {
+		rows = append(rows, []string{"transfer", strconv.FormatInt(transfer.TransferID, 10), "",
+			"0", strconv.FormatInt(transfer.EntriesTotal, 10)})
+	}
+	for _, currency := range check.Currencies {
+		rows = append(rows, []string{"currency", currency.Currency, currency.Currency,
+			"0", strconv.FormatInt(currency.EntriesTotal, 10)})
+	}
+
+	if check.Accounts == nil {
+		check.Accounts = []db.ListUnbalancedAccountsRow{}
+	}
+	if check.Transfers == nil {
+		check.Transfers = []db.ListUnbalancedTransfersRow{}
+	}
+	if check.Currencies == nil {
+		check.Currencies = []db.ListUnbalancedCurrenciesRow{}
+	}
+	return out.print(check, []string{"CHECK", "SUBJECT", "CURRENCY", "EXPECTED", "ENTRIES TOTAL"}, rows)
+}
+
+func formatAmount(amount int64, currency string) string {
+	return money.Money{Amount: amount, Currency: currency}.FormatAmount()
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatNullTime(t sql.NullTime) string {
+	if !t.Valid {
+		return "-"
+	}
+	return formatTime(t.Time)
+}
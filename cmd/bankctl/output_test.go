@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+var createdAt = time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+
+func TestOutputAccounts(t *testing.T) {
+	accounts := []db.Account{
+		{ID: 1, Owner: "alice", Balance: 12345, Currency: "EUR", CreatedAt: createdAt},
+		{ID: 22, Owner: "bob", Balance: -500, Currency: "JPY", CreatedAt: createdAt, FrozenAt: sql.NullTime{Time: createdAt, Valid: true}},
+	}
+
+	var table bytes.Buffer
+	require.NoError(t, output{w: &table, format: formatTable}.accounts(accounts))
+	require.Equal(t, `ID  OWNER  CURRENCY  BALANCE  FROZEN AT             CREATED AT
+1   alice  EUR       123.45   -                     2024-03-01T09:30:00Z
+22  bob    JPY       -500     2024-03-01T09:30:00Z  2024-03-01T09:30:00Z
+`, table.String())
+
+	var empty bytes.Buffer
+	require.NoError(t, output{w: &empty, format: formatJSON}.accounts(nil))
+	require.Equal(t, "[]\n", empty.String())
+}
+
+func TestOutputBalance(t *testing.T) {
+	account := db.Account{ID: 7, Balance: 1050, Currency: "USD"}
+
+	var table bytes.Buffer
+	require.NoError(t, output{w: &table, format: formatTable}.balance(account))
+	require.Equal(t, "ID  BALANCE    FROZEN\n7   10.50 USD  false\n", table.String())
+
+	var json bytes.Buffer
+	require.NoError(t, output{w: &json, format: formatJSON}.balance(account))
+	require.JSONEq(t, `{"account_id": 7, "balance": {"amount": "10.50", "currency": "USD"}, "frozen": false}`, json.String())
+}
+
+func TestOutputLedgerCheck(t *testing.T) {
+	var balanced bytes.Buffer
+	require.NoError(t, output{w: &balanced, format: formatTable}.ledgerCheck(db.LedgerCheck{}))
+	require.Equal(t, "the ledger is balanced\n", balanced.String())
+
+	var json bytes.Buffer
+	require.NoError(t, output{w: &json, format: formatJSON}.ledgerCheck(db.LedgerCheck{}))
+	require.JSONEq(t, `{"accounts": [], "transfers": [], "currencies": []}`, json.String())
+
+	check := db.LedgerCheck{
+		Accounts:   []db.ListUnbalancedAccountsRow{{ID: 3, Currency: "EUR", Balance: 100, EntriesTotal: 90}},
+		Transfers:  []db.ListUnbalancedTransfersRow{{TransferID: 8, EntriesTotal: 10}},
+		Currencies: []db.ListUnbalancedCurrenciesRow{{Currency: "EUR", EntriesTotal: 10}},
+	}
+	var table bytes.Buffer
+	require.NoError(t, output{w: &table, format: formatTable}.ledgerCheck(check))
+	require.Equal(t, `CHECK     SUBJECT  CURRENCY  EXPECTED  ENTRIES TOTAL
+account   3        EUR       100       90
+transfer  8                  0         10
+currency  EUR      EUR       0         10
+`, table.String())
+}
+
+func TestOutputAdjustment(t *testing.T) {
+	result := db.AdjustBalanceResult{
+		Adjustment: db.BalanceAdjustment{ID: 4, AccountID: 9, SuspenseAccountID: 2, Amount: -1234, Reason: "duplicate", Actor: "ops"},
+		Account:    db.Account{ID: 9, Balance: 8766, Currency: "EUR"},
+	}
+
+	var table bytes.Buffer
+	require.NoError(t, output{w: &table, format: formatTable}.adjustment(result))
+	require.Equal(t, `ID  ACCOUNT  SUSPENSE  AMOUNT  BALANCE  REASON     ACTOR
+4   9        2         -12.34  87.66    duplicate  ops
+`, table.String())
+}
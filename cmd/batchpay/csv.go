@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	db "simple_bank/db/sqlc"
+)
+
+var inputHeader = []string{"from_account_id", "to_account_id", "amount", "reference"}
+
+var outputHeader = []string{"row", "from_account_id", "to_account_id", "amount", "reference", "status", "transfer_id", "error"}
+
+// readTransfers reads the transfers of a batch CSV.
+// Rows that can't be parsed are reported in the returned map, keyed by their index in the returned slice.
+func readTransfers(r io.Reader) ([]db.BatchTransfer, map[int]error, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(records) > 0 && isHeader(records[0]) {
+		records = records[1:]
+	}
+
+	transfers := make([]db.BatchTransfer, len(records))
+	parseErrors := make(map[int]error)
+
+	for i, record := range records {
+		transfer, err := parseTransfer(record)
+		if err != nil {
+			parseErrors[i] = err
+		}
+		transfers[i] = transfer
+	}
+
+	return transfers, parseErrors, nil
+}
+
+func isHeader(record []string) bool {
+	return len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), inputHeader[0])
+}
+
+func parseTransfer(record []string) (transfer db.BatchTransfer, err error) {
+	if len(record) < 3 || len(record) > 4 {
+		return transfer, fmt.Errorf("expected 3 or 4 columns, got %d", len(record))
+	}
+
+	transfer.FromAccountId, err = strconv.ParseInt(strings.TrimSpace(record[0]), 10, 64)
+	if err != nil {
+		return transfer, fmt.Errorf("invalid from_account_id %q", record[0])
+	}
+
+	transfer.ToAccountId, err = strconv.ParseInt(strings.TrimSpace(record[1]), 10, 64)
+	if err != nil {
+		return transfer, fmt.Errorf("invalid to_account_id %q", record[1])
+	}
+
+	transfer.Amount, err = strconv.ParseInt(strings.TrimSpace(record[2]), 10, 64)
+	if err != nil {
+		return transfer, fmt.Errorf("invalid amount %q", record[2])
+	}
+
+	if len(record) == 4 {
+		transfer.Reference = strings.TrimSpace(record[3])
+	}
+
+	return transfer, nil
+}
+
+// writeResults writes one CSV row per transfer with its transfer ID or its error
+func writeResults(w io.Writer, results []db.BatchTransferResult) error {
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(outputHeader); err != nil {
+		return err
+	}
+
+	for i, result := range results {
+		status, transferId, errMessage := "ok", strconv.FormatInt(result.Transfer.ID, 10), ""
+		if result.Err != nil {
+			status, transferId, errMessage = "failed", "", result.Err.Error()
+		}
+
+		err := writer.Write([]string{
+			strconv.Itoa(i + 1),
+			strconv.FormatInt(result.FromAccountId, 10),
+			strconv.FormatInt(result.ToAccountId, 10),
+			strconv.FormatInt(result.Amount, 10),
+			result.Reference,
+			status,
+			transferId,
+			errMessage,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
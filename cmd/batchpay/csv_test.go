@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+func TestReadTransfers(t *testing.T) {
+	input := `from_account_id,to_account_id,amount,reference
+1,2,100,salary march
+3, 4, 250
+x,4,10,bad
+5,6
+`
+
+	transfers, parseErrors, err := readTransfers(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, transfers, 4)
+
+	require.Equal(t, db.BatchTransfer{FromAccountId: 1, ToAccountId: 2, Amount: 100, Reference: "salary march"}, transfers[0])
+	require.Equal(t, db.BatchTransfer{FromAccountId: 3, ToAccountId: 4, Amount: 250}, transfers[1])
+
+	require.Len(t, parseErrors, 2)
+	require.Contains(t, parseErrors[2].Error(), "from_account_id")
+	require.Contains(t, parseErrors[3].Error(), "columns")
+}
+
+func TestReadTransfersWithoutHeader(t *testing.T) {
+	transfers, parseErrors, err := readTransfers(strings.NewReader("1,2,100,ref\n"))
+	require.NoError(t, err)
+	require.Empty(t, parseErrors)
+	require.Len(t, transfers, 1)
+}
+
+func TestWriteResults(t *testing.T) {
+	results := []db.BatchTransferResult{
+		{
+			BatchTransfer: db.BatchTransfer{FromAccountId: 1, ToAccountId: 2, Amount: 100, Reference: "a"},
+			Transfer:      db.Transfer{ID: 42},
+		},
+		{
+			BatchTransfer: db.BatchTransfer{FromAccountId: 3, ToAccountId: 3, Amount: 5, Reference: "b"},
+			Err:           errors.New("from and to accounts must be different"),
+		},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, results))
+
+	require.Equal(t, `row,from_account_id,to_account_id,amount,reference,status,transfer_id,error
+1,1,2,100,a,ok,42,
+2,3,3,5,b,failed,,from and to accounts must be different
+`, buf.String())
+}
+
+func TestMergeResults(t *testing.T) {
+	transfers := []db.BatchTransfer{{FromAccountId: 1}, {FromAccountId: 2}, {FromAccountId: 3}}
+	parseErrors := map[int]error{1: errors.New("invalid amount")}
+
+	valid, index := withoutParseErrors(transfers, parseErrors)
+	require.Len(t, valid, 2)
+	require.Equal(t, []int{0, 2}, index)
+
+	validResult := db.ExecuteBatchResult{
+		Results: []db.BatchTransferResult{
+			{BatchTransfer: valid[0], Transfer: db.Transfer{ID: 10}},
+			{BatchTransfer: valid[1], Transfer: db.Transfer{ID: 11}},
+		},
+		Succeeded: 2,
+	}
+
+	result := mergeResults(transfers, parseErrors, validResult, index)
+	require.Equal(t, 2, result.Succeeded)
+	require.Equal(t, 1, result.Failed)
+	require.Equal(t, int64(10), result.Results[0].Transfer.ID)
+	require.Error(t, result.Results[1].Err)
+	require.Equal(t, int64(11), result.Results[2].Transfer.ID)
+}
@@ -0,0 +1,190 @@
+// batchpay executes a batch of transfers read from a CSV file of
+// from_account_id,to_account_id,amount,reference rows and writes a result CSV
+// with the transfer ID or the error of every row.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	_ "github.com/lib/pq"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("batchpay: cannot load config: ", err)
+	}
+
+	source := flag.String("db", config.DBSource, "database connection string")
+	in := flag.String("in", "", "input CSV file, defaults to stdin")
+	out := flag.String("out", "", "result CSV file, defaults to stdout")
+	mode := flag.String("mode", string(db.BatchAllOrNothing), "all-or-nothing or best-effort")
+	flag.Parse()
+
+	config.DBSource = *source
+	err = run(config, *in, *out, db.BatchMode(*mode))
+	if errors.Is(err, errRowsFailed) {
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatal("batchpay: ", err)
+	}
+}
+
+// errRowsFailed is returned by run when the results were written but some rows failed
+var errRowsFailed = errors.New("some rows failed")
+
+func run(config util.Config, in, out string, mode db.BatchMode) error {
+	input, err := openInput(in)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	transfers, parseErrors, err := readTransfers(input)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", in, err)
+	}
+
+	feeSchedule, err := db.NewFeeSchedule(config.TransferFees, config.FeeRevenueAccounts)
+	if err != nil {
+		return fmt.Errorf("invalid fee schedule: %w", err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn,
+		db.WithFeeSchedule(feeSchedule),
+		db.WithInternalAccounts(config.InternalAccounts),
+		db.WithFXAccounts(config.FXAccounts),
+		db.WithSuspenseAccounts(config.SuspenseAccounts),
+	)
+	ctx := context.Background()
+
+	var result db.ExecuteBatchResult
+	var batchErr error
+
+	if len(parseErrors) > 0 && mode == db.BatchAllOrNothing {
+		result = unparsedBatchResult(transfers, parseErrors)
+		batchErr = db.ErrBatchInvalid
+	} else {
+		valid, index := withoutParseErrors(transfers, parseErrors)
+
+		var validResult db.ExecuteBatchResult
+		validResult, batchErr = store.ExecuteBatch(ctx, db.ExecuteBatchParams{
+			Transfers: valid,
+			Mode:      mode,
+		})
+		if validResult.Results == nil && batchErr != nil {
+			return batchErr
+		}
+
+		result = mergeResults(transfers, parseErrors, validResult, index)
+	}
+
+	output, err := openOutput(out)
+	if err != nil {
+		return err
+	}
+	defer output.Close()
+
+	if err := writeResults(output, result.Results); err != nil {
+		return fmt.Errorf("write results: %w", err)
+	}
+
+	log.Printf("batchpay: %d succeeded, %d failed", result.Succeeded, result.Failed)
+
+	if batchErr != nil && !errors.Is(batchErr, db.ErrBatchInvalid) && !errors.Is(batchErr, db.ErrBatchFailed) {
+		return batchErr
+	}
+	if result.Failed > 0 {
+		return errRowsFailed
+	}
+	return nil
+}
+
+// unparsedBatchResult reports the parse errors without executing anything
+func unparsedBatchResult(transfers []db.BatchTransfer, parseErrors map[int]error) db.ExecuteBatchResult {
+	result := db.ExecuteBatchResult{Results: make([]db.BatchTransferResult, len(transfers))}
+
+	for i, transfer := range transfers {
+		result.Results[i].BatchTransfer = transfer
+		result.Results[i].Err = parseErrors[i]
+		if result.Results[i].Err == nil {
+			result.Results[i].Err = db.ErrNotExecuted
+		}
+	}
+
+	result.Failed = len(transfers)
+	return result
+}
+
+// withoutParseErrors returns the parsed transfers and, for each of them, its index in the input
+func withoutParseErrors(transfers []db.BatchTransfer, parseErrors map[int]error) ([]db.BatchTransfer, []int) {
+	valid := make([]db.BatchTransfer, 0, len(transfers))
+	index := make([]int, 0, len(transfers))
+
+	for i, transfer := range transfers {
+		if parseErrors[i] == nil {
+			valid = append(valid, transfer)
+			index = append(index, i)
+		}
+	}
+
+	return valid, index
+}
+
+func mergeResults(
+	transfers []db.BatchTransfer,
+	parseErrors map[int]error,
+	validResult db.ExecuteBatchResult,
+	index []int,
+) db.ExecuteBatchResult {
+	result := db.ExecuteBatchResult{Results: make([]db.BatchTransferResult, len(transfers))}
+
+	for i, transfer := range transfers {
+		result.Results[i].BatchTransfer = transfer
+		result.Results[i].Err = parseErrors[i]
+	}
+
+	for i, r := range validResult.Results {
+		result.Results[index[i]] = r
+	}
+
+	result.Succeeded = validResult.Succeeded
+	result.Failed = validResult.Failed + len(parseErrors)
+	return result
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+	if path == "" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
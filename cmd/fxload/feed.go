@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+)
+
+var csvHeader = []string{"base_currency", "quote_currency", "rate", "effective_from"}
+
+// readCSV reads the rates of a CSV of base_currency,quote_currency,rate,effective_from rows.
+// effective_from is a RFC 3339 time or a date, a date is effective from its start in UTC.
+func readCSV(r io.Reader) ([]db.CreateFXRateParams, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = len(csvHeader)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(records) > 0 && strings.EqualFold(strings.TrimSpace(records[0][0]), csvHeader[0]) {
+		records = records[1:]
+	}
+
+	rates := make([]db.CreateFXRateParams, len(records))
+	for i, record := range records {
+		rates[i] = db.CreateFXRateParams{
+			BaseCurrency:  strings.TrimSpace(record[0]),
+			QuoteCurrency: strings.TrimSpace(record[1]),
+			Rate:          strings.TrimSpace(record[2]),
+			Source:        db.FXSourceCSV,
+		}
+
+		rates[i].EffectiveFrom, err = parseEffectiveFrom(strings.TrimSpace(record[3]))
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", i+1, err)
+		}
+	}
+
+	return rates, nil
+}
+
+func parseEffectiveFrom(value string) (time.Time, error) {
+	if effectiveFrom, err := time.Parse(time.RFC3339, value); err == nil {
+		return effectiveFrom, nil
+	}
+	if effectiveFrom, err := time.Parse(time.DateOnly, value); err == nil {
+		return effectiveFrom, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid effective_from %q", value)
+}
+
+// ecbEnvelope is the eurofxref XML of the ECB reference rates, a Cube per day with a Cube per currency
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// readECB reads the rates of an ECB eurofxref XML, daily or historical. The rates are the units of a currency for one euro,
+// effective from the start of their day in UTC. The currencies missing from the money registry are skipped and returned.
+func readECB(r io.Reader) (rates []db.CreateFXRateParams, skipped []string, err error) {
+	var envelope ecbEnvelope
+	if err = xml.NewDecoder(r).Decode(&envelope); err != nil {
+		return nil, nil, err
+	}
+
+	unsupported := make(map[string]bool)
+	for _, day := range envelope.Days {
+		effectiveFrom, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid day %q", day.Time)
+		}
+
+		for _, rate := range day.Rates {
+			if !money.IsSupported(rate.Currency) {
+				unsupported[rate.Currency] = true
+				continue
+			}
+
+			rates = append(rates, db.CreateFXRateParams{
+				BaseCurrency:  "EUR",
+				QuoteCurrency: rate.Currency,
+				Rate:          rate.Rate,
+				EffectiveFrom: effectiveFrom,
+				Source:        db.FXSourceECB,
+			})
+		}
+	}
+
+	for currency := range unsupported {
+		skipped = append(skipped, currency)
+	}
+	sort.Strings(skipped)
+	return rates, skipped, nil
+}
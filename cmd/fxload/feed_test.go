@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+func TestReadCSV(t *testing.T) {
+	input := `base_currency,quote_currency,rate,effective_from
+EUR,USD,1.0956,2024-01-02
+GBP, JPY, 180.12, 2024-01-02T15:00:00Z
+`
+
+	rates, err := readCSV(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, []db.CreateFXRateParams{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.0956", EffectiveFrom: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Source: db.FXSourceCSV},
+		{BaseCurrency: "GBP", QuoteCurrency: "JPY", Rate: "180.12", EffectiveFrom: time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC), Source: db.FXSourceCSV},
+	}, rates)
+
+	_, err = readCSV(strings.NewReader("EUR,USD,1.0956,yesterday\n"))
+	require.ErrorContains(t, err, "row 1")
+
+	_, err = readCSV(strings.NewReader("EUR,USD,1.0956\n"))
+	require.Error(t, err)
+}
+
+func TestReadECB(t *testing.T) {
+	input := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-01-03">
+			<Cube currency="USD" rate="1.0919"/>
+			<Cube currency="XDR" rate="0.8"/>
+		</Cube>
+		<Cube time="2024-01-02">
+			<Cube currency="USD" rate="1.0956"/>
+			<Cube currency="JPY" rate="155.52"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+	rates, skipped, err := readECB(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Equal(t, []string{"XDR"}, skipped)
+	require.Equal(t, []db.CreateFXRateParams{
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.0919", EffectiveFrom: time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC), Source: db.FXSourceECB},
+		{BaseCurrency: "EUR", QuoteCurrency: "USD", Rate: "1.0956", EffectiveFrom: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Source: db.FXSourceECB},
+		{BaseCurrency: "EUR", QuoteCurrency: "JPY", Rate: "155.52", EffectiveFrom: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Source: db.FXSourceECB},
+	}, rates)
+}
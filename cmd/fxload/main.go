@@ -0,0 +1,89 @@
+// fxload imports FX rates from a local file into the fx_rates table: a CSV of
+// base_currency,quote_currency,rate,effective_from rows or an ECB eurofxref XML
+// (https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml). Loading a file again replaces its rates.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "github.com/lib/pq"
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("fxload: cannot load config: ", err)
+	}
+
+	source := flag.String("db", config.DBSource, "database connection string")
+	in := flag.String("in", "", "rates file, required")
+	format := flag.String("format", "", "csv or ecb, defaults to ecb for .xml files and csv otherwise")
+	flag.Parse()
+
+	if *in == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	config.DBSource = *source
+	err = run(config, *in, *format)
+	if err != nil {
+		log.Fatal("fxload: ", err)
+	}
+}
+
+func run(config util.Config, in, format string) error {
+	if format == "" {
+		format = "csv"
+		if strings.EqualFold(filepath.Ext(in), ".xml") {
+			format = "ecb"
+		}
+	}
+
+	input, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var rates []db.CreateFXRateParams
+	switch format {
+	case "csv":
+		rates, err = readCSV(input)
+	case "ecb":
+		var skipped []string
+		rates, skipped, err = readECB(input)
+		if len(skipped) > 0 {
+			log.Printf("fxload: skipped the currencies missing from the registry: %s", strings.Join(skipped, ", "))
+		}
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+	if err != nil {
+		return fmt.Errorf("read %s: %w", in, err)
+	}
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	store := db.NewStore(conn, db.WithFXAccounts(config.FXAccounts))
+	imported, err := store.ImportFXRates(context.Background(), rates)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("fxload: imported %d rates", imported)
+	return nil
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// history moves the seeded rows to their time. The store has no query to rewrite the timestamps,
+// the server must never backdate a row, so the seed runs its own statements on its own connection.
+type history struct {
+	conn *sql.DB
+}
+
+// moveAccount moves the opening of an account to another time
+func (h history) moveAccount(ctx context.Context, accountId int64, at time.Time) error {
+	_, err := h.conn.ExecContext(ctx, `UPDATE accounts SET created_at = $2 WHERE id = $1`, accountId, at)
+	return err
+}
+
+// moveTransfer moves a transfer and its entries to another time
+func (h history) moveTransfer(ctx context.Context, transferId int64, at time.Time) error {
+	_, err := h.conn.ExecContext(ctx, `
+WITH moved_entries AS (
+    UPDATE entries SET created_at = $1 WHERE transfer_id = $2
+)
+UPDATE transfers SET created_at = $1 WHERE id = $2`, at, transferId)
+	return err
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+// loadResult sums up a load run
+type loadResult struct {
+	Elapsed   time.Duration
+	Succeeded int
+	Failed    int
+	// Latencies of the TransferTX calls, succeeded or failed, in ascending order
+	Latencies []time.Duration
+}
+
+// runLoad drives concurrent TransferTX calls between random pairs of the accounts until the context is done.
+// Concurrent transfers touch the same accounts, so it exercises the locks of the store too.
+func runLoad(ctx context.Context, store *db.Store, g *util.Generator, accounts []db.Account, senders []bool, workers int) (loadResult, error) {
+	var result loadResult
+
+	currencies := make([]string, len(accounts))
+	for i, account := range accounts {
+		currencies[i] = account.Currency
+	}
+	picker, err := newPairPicker(g, currencies, senders)
+	if err != nil {
+		return result, err
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	start := time.Now()
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var latencies []time.Duration
+			succeeded, failed := 0, 0
+			for ctx.Err() == nil {
+				from, to := picker.pick()
+				begin := time.Now()
+				_, err := store.TransferTX(ctx, db.TransferTxParams{
+					FromAccountId: accounts[from].ID,
+					ToAccountId:   accounts[to].ID,
+					Amount:        g.RandomMoney() + 1,
+					Reference:     "load test",
+				})
+				if ctx.Err() != nil {
+					// the transfer was cut by the end of the run
+					break
+				}
+				latencies = append(latencies, time.Since(begin))
+				if err != nil {
+					failed++
+				} else {
+					succeeded++
+				}
+			}
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			result.Succeeded += succeeded
+			result.Failed += failed
+			result.Latencies = append(result.Latencies, latencies...)
+		}()
+	}
+
+	wg.Wait()
+	result.Elapsed = time.Since(start)
+	sort.Slice(result.Latencies, func(i, j int) bool { return result.Latencies[i] < result.Latencies[j] })
+	return result, nil
+}
+
+// percentile returns the latency below which the percent of the sorted latencies are, zero without latencies
+func percentile(sorted []time.Duration, percent float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*percent/100+0.5) - 1
+	if index < 0 {
+		index = 0
+	}
+	if index >= len(sorted) {
+		index = len(sorted) - 1
+	}
+	return sorted[index]
+}
+
+func (result loadResult) String() string {
+	total := result.Succeeded + result.Failed
+	rate := float64(total) / result.Elapsed.Seconds()
+	return fmt.Sprintf("%d transfers in %s (%.1f/s), %d failed, latency p50 %s p95 %s p99 %s",
+		total, result.Elapsed.Round(time.Millisecond), rate, result.Failed,
+		percentile(result.Latencies, 50), percentile(result.Latencies, 95), percentile(result.Latencies, 99))
+}
@@ -0,0 +1,153 @@
+// seed fills a database with a reproducible synthetic dataset: customers, most of them KYC verified,
+// their accounts with an opening deposit and a history of transfers spread over a time range.
+// The same -seed and flags always generate the same dataset, seeding it twice fails on the customers already there.
+// With -load, it then drives concurrent transfers between the seeded accounts for the duration and prints
+// the throughput and the latencies. The database is DB_SOURCE of app.env or the environment, -db overrides it.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+	db "simple_bank/db/sqlc"
+	"simple_bank/money"
+	"simple_bank/util"
+)
+
+func main() {
+	dbConfig, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("seed: cannot load config: ", err)
+	}
+
+	source := flag.String("db", dbConfig.DBSource, "database connection string")
+	seed := flag.Int64("seed", 1, "seed of the random source, the same seed generates the same dataset")
+	customers := flag.Int("customers", 100, "number of customers")
+	maxAccounts := flag.Int("max-accounts", 3, "most accounts per customer")
+	transfers := flag.Int("transfers", 1000, "number of transfers of the history")
+	from := flag.String("from", "", "start of the history, like 2024-01-01, defaults to 90 days before -to")
+	to := flag.String("to", "", "end of the history, defaults to today at midnight UTC")
+	currencies := flag.String("currencies", "EUR,USD,GBP", "comma separated currencies of the accounts, empty for all the supported currencies")
+	load := flag.Duration("load", 0, "duration of the load run after seeding, zero disables it")
+	workers := flag.Int("workers", 8, "concurrent transfers of the load run")
+	flag.Parse()
+
+	config := datasetConfig{
+		Customers:   *customers,
+		MaxAccounts: *maxAccounts,
+		Transfers:   *transfers,
+	}
+
+	err = parseRange(&config, *from, *to, time.Now())
+	if err == nil {
+		config.Currencies, err = parseCurrencies(*currencies)
+	}
+	if err == nil {
+		err = run(dbConfig.DBDriver, *source, *seed, config, *load, *workers)
+	}
+	if err != nil {
+		log.Fatal("seed: ", err)
+	}
+}
+
+func run(driver, source string, seed int64, config datasetConfig, load time.Duration, workers int) error {
+	g := util.NewGenerator(seed)
+
+	data, err := planDataset(g, config)
+	if err != nil {
+		return err
+	}
+
+	conn, err := sql.Open(driver, source)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer conn.Close()
+
+	// the history is moved on a connection of its own, outside of the store
+	historyConn, err := sql.Open(driver, source)
+	if err != nil {
+		return fmt.Errorf("cannot connect to db: %w", err)
+	}
+	defer historyConn.Close()
+	past := history{conn: historyConn}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	funding, err := openFundingAccounts(ctx, db.NewStore(conn), past, data, config)
+	if err != nil {
+		return err
+	}
+
+	fundingIds := make([]int64, 0, len(funding))
+	for _, id := range funding {
+		fundingIds = append(fundingIds, id)
+	}
+	store := db.NewStore(conn, db.WithInternalAccounts(fundingIds))
+
+	result, err := seedDataset(ctx, store, past, data, config, funding)
+	if err != nil {
+		return err
+	}
+	log.Printf("seed: seed %d, %d customers, %d accounts, %d transfers from %s to %s",
+		seed, len(data.Customers), len(result.Accounts), result.Transfers,
+		config.From.Format(time.RFC3339), config.To.Format(time.RFC3339))
+
+	if load <= 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, load)
+	defer cancel()
+
+	loaded, err := runLoad(ctx, store, g, result.Accounts, result.Senders, workers)
+	if err != nil {
+		return err
+	}
+	log.Printf("seed: load %s", loaded)
+	return nil
+}
+
+// parseRange sets the time range of the config, to defaults to the midnight UTC before now
+// and from to 90 days before to
+func parseRange(config *datasetConfig, from, to string, now time.Time) (err error) {
+	config.To = now.UTC().Truncate(24 * time.Hour)
+	if to != "" {
+		if config.To, err = time.Parse(time.DateOnly, to); err != nil {
+			return fmt.Errorf("invalid -to %q", to)
+		}
+	}
+
+	config.From = config.To.AddDate(0, 0, -90)
+	if from != "" {
+		if config.From, err = time.Parse(time.DateOnly, from); err != nil {
+			return fmt.Errorf("invalid -from %q", from)
+		}
+	}
+	return nil
+}
+
+// parseCurrencies parses the comma separated currencies, which must be supported
+func parseCurrencies(value string) ([]string, error) {
+	var currencies []string
+	for _, code := range strings.Split(value, ",") {
+		code = strings.TrimSpace(code)
+		if code == "" {
+			continue
+		}
+		if _, err := money.LookupCurrency(code); err != nil {
+			return nil, err
+		}
+		currencies = append(currencies, code)
+	}
+	return currencies, nil
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	db "simple_bank/db/sqlc"
+	"simple_bank/util"
+)
+
+// datasetConfig sizes the generated dataset
+type datasetConfig struct {
+	Customers int
+	// MaxAccounts is the most accounts a customer opens, every customer opens at least one
+	MaxAccounts int
+	Transfers   int
+	// From and To bound the time of the transfers, the accounts are opened at From
+	From time.Time
+	To   time.Time
+	// Currencies are the currencies of the accounts, empty for all the supported currencies
+	Currencies []string
+}
+
+// dataset is the plan of the rows to create, drawn from a single generator,
+// so the same seed and config always plan the same dataset
+type dataset struct {
+	Customers []customerPlan
+	Accounts  []accountPlan
+	// Transfers are in chronological order
+	Transfers []transferPlan
+}
+
+type customerPlan struct {
+	Customer db.CreateCustomerParams
+	Owner    string
+	// Verified customers pass KYC, the others are rejected and never send money
+	Verified bool
+}
+
+type accountPlan struct {
+	// Customer is the index of the owner in dataset.Customers
+	Customer int
+	Currency string
+	// Deposit is credited to the account when it's opened
+	Deposit int64
+}
+
+type transferPlan struct {
+	// From and To are indexes in dataset.Accounts
+	From      int
+	To        int
+	Amount    int64
+	Reference string
+	At        time.Time
+}
+
+var errNoTransferPairs = errors.New("no two accounts of a verified sender share a currency, add customers or reduce the currencies")
+
+var references = []string{"rent", "groceries", "invoice", "salary", "refund", "dinner", "utilities", "gift"}
+
+// planDataset draws the customers, their accounts and the transfers between them
+func planDataset(g *util.Generator, config datasetConfig) (dataset, error) {
+	var data dataset
+
+	switch {
+	case config.Customers < 1:
+		return data, errors.New("at least one customer is needed")
+	case config.MaxAccounts < 1:
+		return data, errors.New("customers need at least one account")
+	case !config.From.Before(config.To):
+		return data, fmt.Errorf("the time range %s - %s is empty", config.From.Format(time.RFC3339), config.To.Format(time.RFC3339))
+	}
+
+	for i := 0; i < config.Customers; i++ {
+		customer := randomCustomer(g)
+		data.Customers = append(data.Customers, customer)
+
+		opened := int(g.RandomInt(1, int64(config.MaxAccounts)))
+		for j := 0; j < opened; j++ {
+			data.Accounts = append(data.Accounts, accountPlan{
+				Customer: i,
+				Currency: randomCurrency(g, config.Currencies),
+				// up to 10 000.00, far above the transfers so most balances stay positive
+				Deposit: (g.RandomMoney() + 1) * 1000,
+			})
+		}
+	}
+
+	if config.Transfers == 0 {
+		return data, nil
+	}
+
+	picker, err := newPairPicker(g, data.accountCurrencies(), data.senders())
+	if err != nil {
+		return data, err
+	}
+
+	times := randomTimes(g, config.Transfers, config.From, config.To)
+	for i, at := range times {
+		from, to := picker.pick()
+		data.Transfers = append(data.Transfers, transferPlan{
+			From:      from,
+			To:        to,
+			Amount:    randomTransferAmount(g),
+			Reference: fmt.Sprintf("%s %d", references[g.RandomInt(0, int64(len(references)-1))], i+1),
+			At:        at,
+		})
+	}
+
+	return data, nil
+}
+
+func randomCustomer(g *util.Generator) customerPlan {
+	owner := g.RandomOwner()
+	birth := time.Date(1940, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(g.RandomInt(0, 65*365)))
+
+	return customerPlan{
+		Customer: db.CreateCustomerParams{
+			LegalName:   title(owner) + " " + title(g.RandomString(8)),
+			DateOfBirth: birth,
+			Address:     fmt.Sprintf("%d %s Street", g.RandomInt(1, 200), title(g.RandomString(7))),
+			NationalID:  g.RandomString(12),
+		},
+		Owner: owner,
+		// 9 customers out of 10 pass KYC
+		Verified: g.RandomInt(1, 10) <= 9,
+	}
+}
+
+func randomCurrency(g *util.Generator, currencies []string) string {
+	if len(currencies) == 0 {
+		return g.RandomCurrency()
+	}
+	return currencies[g.RandomInt(0, int64(len(currencies)-1))]
+}
+
+// randomTransferAmount is mostly small payments, with a few larger ones
+func randomTransferAmount(g *util.Generator) int64 {
+	scale := int64(1)
+	switch g.RandomInt(1, 20) {
+	case 1:
+		scale = 100
+	case 2, 3, 4:
+		scale = 10
+	}
+	return (g.RandomMoney() + 1) * scale
+}
+
+// randomTimes returns n random times of [from, to) in chronological order
+func randomTimes(g *util.Generator, n int, from, to time.Time) []time.Time {
+	span := to.Sub(from).Nanoseconds()
+
+	times := make([]time.Time, n)
+	for i := range times {
+		times[i] = from.Add(time.Duration(g.RandomInt(0, span-1)))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+func title(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
+
+func (data dataset) accountCurrencies() []string {
+	currencies := make([]string, len(data.Accounts))
+	for i, account := range data.Accounts {
+		currencies[i] = account.Currency
+	}
+	return currencies
+}
+
+// senders reports for every account whether its customer can send money
+func (data dataset) senders() []bool {
+	senders := make([]bool, len(data.Accounts))
+	for i, account := range data.Accounts {
+		senders[i] = data.Customers[account.Customer].Verified
+	}
+	return senders
+}
+
+// pairPicker draws a sender and a receiver of the same currency among accounts given by their index
+type pairPicker struct {
+	g       *util.Generator
+	senders []int
+	// byCurrency are the accounts of every currency
+	byCurrency map[string][]int
+	currencies []string
+}
+
+// newPairPicker keeps the senders with at least another account in their currency
+func newPairPicker(g *util.Generator, currencies []string, canSend []bool) (pairPicker, error) {
+	picker := pairPicker{g: g, byCurrency: make(map[string][]int), currencies: currencies}
+	for i, currency := range currencies {
+		picker.byCurrency[currency] = append(picker.byCurrency[currency], i)
+	}
+
+	for i, currency := range currencies {
+		if canSend[i] && len(picker.byCurrency[currency]) > 1 {
+			picker.senders = append(picker.senders, i)
+		}
+	}
+
+	if len(picker.senders) == 0 {
+		return picker, errNoTransferPairs
+	}
+	return picker, nil
+}
+
+func (picker pairPicker) pick() (from, to int) {
+	from = picker.senders[picker.g.RandomInt(0, int64(len(picker.senders)-1))]
+
+	receivers := picker.byCurrency[picker.currencies[from]]
+	// draws one of the receivers but the last, the last one stands in for the sender
+	to = receivers[picker.g.RandomInt(0, int64(len(receivers)-2))]
+	if to == from {
+		to = receivers[len(receivers)-1]
+	}
+	return from, to
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+var testConfig = datasetConfig{
+	Customers:   20,
+	MaxAccounts: 3,
+	Transfers:   200,
+	From:        time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	To:          time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC),
+	Currencies:  []string{"EUR", "USD"},
+}
+
+func TestPlanDatasetIsReproducible(t *testing.T) {
+	data, err := planDataset(util.NewGenerator(42), testConfig)
+	require.NoError(t, err)
+
+	again, err := planDataset(util.NewGenerator(42), testConfig)
+	require.NoError(t, err)
+	require.Equal(t, data, again)
+
+	other, err := planDataset(util.NewGenerator(43), testConfig)
+	require.NoError(t, err)
+	require.NotEqual(t, data, other)
+}
+
+func TestPlanDataset(t *testing.T) {
+	data, err := planDataset(util.NewGenerator(7), testConfig)
+	require.NoError(t, err)
+
+	require.Len(t, data.Customers, testConfig.Customers)
+	require.GreaterOrEqual(t, len(data.Accounts), testConfig.Customers)
+	require.LessOrEqual(t, len(data.Accounts), testConfig.Customers*testConfig.MaxAccounts)
+	for _, account := range data.Accounts {
+		require.Contains(t, testConfig.Currencies, account.Currency)
+		require.Positive(t, account.Deposit)
+	}
+
+	require.Len(t, data.Transfers, testConfig.Transfers)
+	for i, transfer := range data.Transfers {
+		from, to := data.Accounts[transfer.From], data.Accounts[transfer.To]
+		require.NotEqual(t, transfer.From, transfer.To)
+		require.Equal(t, from.Currency, to.Currency)
+		require.True(t, data.Customers[from.Customer].Verified)
+		require.Positive(t, transfer.Amount)
+
+		require.False(t, transfer.At.Before(testConfig.From))
+		require.True(t, transfer.At.Before(testConfig.To))
+		if i > 0 {
+			require.False(t, transfer.At.Before(data.Transfers[i-1].At))
+		}
+	}
+}
+
+func TestPlanDatasetInvalid(t *testing.T) {
+	config := testConfig
+	config.Customers = 0
+	_, err := planDataset(util.NewGenerator(1), config)
+	require.Error(t, err)
+
+	config = testConfig
+	config.To = config.From
+	_, err = planDataset(util.NewGenerator(1), config)
+	require.ErrorContains(t, err, "is empty")
+
+	// a single account has nobody to send to
+	config = testConfig
+	config.Customers, config.MaxAccounts = 1, 1
+	_, err = planDataset(util.NewGenerator(1), config)
+	require.ErrorIs(t, err, errNoTransferPairs)
+}
+
+func TestPairPicker(t *testing.T) {
+	currencies := []string{"EUR", "USD", "EUR", "GBP", "EUR"}
+	canSend := []bool{true, true, false, true, true}
+
+	picker, err := newPairPicker(util.NewGenerator(1), currencies, canSend)
+	require.NoError(t, err)
+	// USD and GBP have a single account
+	require.Equal(t, []int{0, 4}, picker.senders)
+
+	for i := 0; i < 100; i++ {
+		from, to := picker.pick()
+		require.Contains(t, []int{0, 4}, from)
+		require.Contains(t, []int{0, 2, 4}, to)
+		require.NotEqual(t, from, to)
+	}
+}
+
+func TestParseRange(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 30, 0, 0, time.UTC)
+
+	var config datasetConfig
+	require.NoError(t, parseRange(&config, "", "", now))
+	require.Equal(t, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), config.To)
+	require.Equal(t, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC), config.From)
+
+	require.NoError(t, parseRange(&config, "2023-01-01", "2023-07-01", now))
+	require.Equal(t, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), config.From)
+	require.Equal(t, time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC), config.To)
+
+	require.Error(t, parseRange(&config, "last year", "", now))
+}
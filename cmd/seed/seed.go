@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	db "simple_bank/db/sqlc"
+)
+
+// seedOwner owns the funding accounts the opening deposits are made from
+const seedOwner = "seed-funding"
+
+// seeded are the rows created for a dataset
+type seeded struct {
+	// Accounts are in the order of dataset.Accounts
+	Accounts []db.Account
+	// Senders reports for every account whether its customer can send money
+	Senders   []bool
+	Transfers int
+}
+
+// seedDataset creates the customers and their accounts opened at from with their deposits, then executes the transfers
+// and moves them to their time. The deposits come from the funding account of their currency, so the ledger stays balanced.
+func seedDataset(ctx context.Context, store *db.Store, past history, data dataset, config datasetConfig, funding map[string]int64) (seeded, error) {
+	result := seeded{Senders: data.senders()}
+
+	customerIds := make([]int64, len(data.Customers))
+	for i, plan := range data.Customers {
+		customer, err := store.CreateCustomer(ctx, plan.Customer)
+		if err != nil {
+			return result, fmt.Errorf("customer %d: %w", i+1, err)
+		}
+		customerIds[i] = customer.ID
+
+		outcome := db.RecordKYCOutcomeParams{CustomerID: customer.ID, Status: db.KYCVerified, Reviewer: seedOwner}
+		if !plan.Verified {
+			outcome.Status, outcome.Reason = db.KYCRejected, "seeded rejection"
+		}
+		if _, err := store.RecordKYCOutcome(ctx, outcome); err != nil {
+			return result, fmt.Errorf("customer %d: %w", i+1, err)
+		}
+	}
+
+	for i, plan := range data.Accounts {
+		account, err := openAccount(ctx, store, past, plan, data.Customers[plan.Customer].Owner, customerIds[plan.Customer], config)
+		if err != nil {
+			return result, fmt.Errorf("account %d: %w", i+1, err)
+		}
+
+		deposit, err := store.TransferTX(ctx, db.TransferTxParams{
+			FromAccountId: funding[plan.Currency],
+			ToAccountId:   account.ID,
+			Amount:        plan.Deposit,
+			Reference:     "opening deposit",
+		})
+		if err != nil {
+			return result, fmt.Errorf("deposit on account %d: %w", account.ID, err)
+		}
+		if err := past.moveTransfer(ctx, deposit.Transfer.ID, config.From); err != nil {
+			return result, err
+		}
+
+		result.Accounts = append(result.Accounts, deposit.ToAccount)
+	}
+
+	for i, plan := range data.Transfers {
+		transfer, err := store.TransferTX(ctx, db.TransferTxParams{
+			FromAccountId: result.Accounts[plan.From].ID,
+			ToAccountId:   result.Accounts[plan.To].ID,
+			Amount:        plan.Amount,
+			Reference:     plan.Reference,
+		})
+		if err != nil {
+			return result, fmt.Errorf("transfer %d: %w", i+1, err)
+		}
+
+		if err := past.moveTransfer(ctx, transfer.Transfer.ID, plan.At); err != nil {
+			return result, err
+		}
+		result.Transfers++
+	}
+
+	return result, nil
+}
+
+func openAccount(ctx context.Context, store *db.Store, past history, plan accountPlan, owner string, customerId int64, config datasetConfig) (db.Account, error) {
+	account, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: owner, Currency: plan.Currency})
+	if err != nil {
+		return account, err
+	}
+
+	account, err = store.SetAccountCustomer(ctx, db.SetAccountCustomerParams{
+		ID:         account.ID,
+		CustomerID: sql.NullInt64{Int64: customerId, Valid: true},
+	})
+	if err != nil {
+		return account, err
+	}
+
+	return account, past.moveAccount(ctx, account.ID, config.From)
+}
+
+// openFundingAccounts opens an internal account, without customer, in every currency of the accounts of the dataset.
+// The deposits come from them, so the store seeding the dataset must list them with db.WithInternalAccounts.
+func openFundingAccounts(ctx context.Context, store *db.Store, past history, data dataset, config datasetConfig) (map[string]int64, error) {
+	funding := make(map[string]int64)
+	for _, plan := range data.Accounts {
+		if _, ok := funding[plan.Currency]; ok {
+			continue
+		}
+
+		account, err := store.CreateAccount(ctx, db.CreateAccountParams{Owner: seedOwner, Currency: plan.Currency})
+		if err != nil {
+			return nil, fmt.Errorf("funding account in %s: %w", plan.Currency, err)
+		}
+		if err := past.moveAccount(ctx, account.ID, config.From); err != nil {
+			return nil, err
+		}
+		funding[plan.Currency] = account.ID
+	}
+	return funding, nil
+}
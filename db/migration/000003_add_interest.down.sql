@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS interest_postings;
+DROP TABLE IF EXISTS interest_accruals;
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "product_id";
+DROP TABLE IF EXISTS account_products;
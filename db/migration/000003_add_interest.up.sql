@@ -0,0 +1,42 @@
+CREATE TABLE "account_products" (
+    "id" bigserial PRIMARY KEY,
+    "name" varchar UNIQUE NOT NULL,
+    "annual_rate_bps" bigint NOT NULL,
+    "day_count" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CHECK ("annual_rate_bps" >= 0),
+    CHECK ("day_count" IN ('ACT/365', 'ACT/360', '30/360'))
+);
+
+ALTER TABLE "accounts" ADD COLUMN "product_id" bigint;
+
+CREATE TABLE "interest_accruals" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "accrual_date" date NOT NULL,
+    "balance" bigint NOT NULL,
+    "amount_micros" bigint NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("account_id", "accrual_date")
+);
+
+CREATE TABLE "interest_postings" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL,
+    "period" date NOT NULL,
+    "amount" bigint NOT NULL,
+    "entry_id" bigint,
+    "expense_entry_id" bigint,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("account_id", "period")
+);
+
+COMMENT ON COLUMN "account_products"."annual_rate_bps" IS '1 basis point is 0.01%';
+COMMENT ON COLUMN "interest_accruals"."amount_micros" IS 'millionths of the minor unit, accrued but not paid';
+COMMENT ON COLUMN "interest_postings"."period" IS 'first day of the month the interest was accrued';
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("product_id") REFERENCES "account_products" ("id");
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("entry_id") REFERENCES "entries" ("id");
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("expense_entry_id") REFERENCES "entries" ("id");
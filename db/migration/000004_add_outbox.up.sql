@@ -0,0 +1,14 @@
+CREATE TABLE "outbox" (
+    "id" bigserial PRIMARY KEY,
+    "event_type" varchar NOT NULL,
+    "aggregate_id" bigint NOT NULL,
+    "payload" jsonb NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    "delivered_at" timestamptz
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "delivered_at" IS NULL;
+CREATE INDEX ON "outbox" ("aggregate_id");
+
+COMMENT ON COLUMN "outbox"."aggregate_id" IS 'transfer ID or account ID, depending on the event type';
+COMMENT ON COLUMN "outbox"."delivered_at" IS 'null until the relay published the event';
@@ -0,0 +1,31 @@
+CREATE TABLE "webhook_subscriptions" (
+    "id" bigserial PRIMARY KEY,
+    "owner" varchar NOT NULL,
+    "url" varchar NOT NULL,
+    "secret" varchar NOT NULL,
+    "event_types" varchar[] NOT NULL DEFAULT '{}',
+    "active" boolean NOT NULL DEFAULT true,
+    "consecutive_failures" int NOT NULL DEFAULT 0,
+    "disabled_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+    "id" bigserial PRIMARY KEY,
+    "subscription_id" bigint NOT NULL,
+    "event_id" bigint NOT NULL,
+    "attempt" int NOT NULL,
+    "status_code" int,
+    "error" varchar NOT NULL DEFAULT '',
+    "succeeded" boolean NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+CREATE INDEX ON "webhook_deliveries" ("subscription_id");
+
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'empty means every event type';
+COMMENT ON COLUMN "webhook_deliveries"."status_code" IS 'null when no response was received';
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE;
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "outbox" ("id");
@@ -0,0 +1,18 @@
+CREATE TABLE "audit_log" (
+    "id" bigserial PRIMARY KEY,
+    "actor" varchar NOT NULL,
+    "action" varchar NOT NULL,
+    "entity_type" varchar NOT NULL,
+    "entity_id" bigint NOT NULL,
+    "before" text NOT NULL,
+    "after" text NOT NULL,
+    "prev_hash" varchar NOT NULL,
+    "hash" varchar UNIQUE NOT NULL,
+    "created_at" timestamptz NOT NULL
+);
+
+CREATE INDEX ON "audit_log" ("entity_type", "entity_id");
+
+COMMENT ON COLUMN "audit_log"."before" IS 'JSON, stored as text so the hashed bytes are kept as is';
+COMMENT ON COLUMN "audit_log"."after" IS 'JSON, stored as text so the hashed bytes are kept as is';
+COMMENT ON COLUMN "audit_log"."hash" IS 'SHA-256 of the previous hash and the fields of the row';
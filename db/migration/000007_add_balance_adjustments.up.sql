@@ -0,0 +1,15 @@
+CREATE TABLE "balance_adjustments" (
+    "id" bigserial PRIMARY KEY,
+    "account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "suspense_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "amount" bigint NOT NULL,
+    "reason" varchar NOT NULL,
+    "actor" varchar NOT NULL,
+    "entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+    "suspense_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "balance_adjustments" ("account_id");
+
+COMMENT ON COLUMN "balance_adjustments"."amount" IS 'credited to the account and debited from the suspense account, can be negative';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS entries_account_id_amount_idx;
+DROP INDEX IF EXISTS transfers_to_account_id_from_account_id_created_at_idx;
+DROP INDEX IF EXISTS transfers_from_account_id_to_account_id_created_at_idx;
+DROP INDEX IF EXISTS transfers_reference_trgm_idx;
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "reference";
@@ -0,0 +1,9 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+-- case insensitive substring search of the reference
+CREATE INDEX transfers_reference_trgm_idx ON "transfers" USING gin (lower("reference") gin_trgm_ops);
+CREATE INDEX ON "transfers" ("from_account_id", "to_account_id", "created_at");
+CREATE INDEX ON "transfers" ("to_account_id", "from_account_id", "created_at");
+CREATE INDEX ON "entries" ("account_id", "amount");
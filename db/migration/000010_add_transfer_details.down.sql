@@ -0,0 +1,11 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "transfer_id";
+
+DROP INDEX IF EXISTS transfers_metadata_idx;
+DROP INDEX IF EXISTS transfers_description_trgm_idx;
+
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_metadata_object";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_description_length";
+ALTER TABLE "transfers" DROP CONSTRAINT IF EXISTS "transfers_reference_length";
+
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "metadata";
+ALTER TABLE "transfers" DROP COLUMN IF EXISTS "description";
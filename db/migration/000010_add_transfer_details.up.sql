@@ -0,0 +1,16 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_reference_length" CHECK (char_length("reference") <= 140);
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_description_length" CHECK (char_length("description") <= 500);
+ALTER TABLE "transfers" ADD CONSTRAINT "transfers_metadata_object" CHECK (jsonb_typeof("metadata") = 'object');
+
+CREATE INDEX transfers_description_trgm_idx ON "transfers" USING gin (lower("description") gin_trgm_ops);
+CREATE INDEX transfers_metadata_idx ON "transfers" USING gin ("metadata" jsonb_path_ops);
+
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'free form JSON object given by the client';
+COMMENT ON COLUMN "entries"."transfer_id" IS 'null when the entry doesn''t belong to a transfer';
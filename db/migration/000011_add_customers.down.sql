@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "customer_id";
+
+DROP TABLE IF EXISTS customers;
@@ -0,0 +1,22 @@
+CREATE TABLE "customers" (
+    "id" bigserial PRIMARY KEY,
+    "legal_name" varchar NOT NULL,
+    "date_of_birth" date NOT NULL,
+    "address" varchar NOT NULL,
+    "national_id" varchar UNIQUE NOT NULL,
+    "kyc_status" varchar NOT NULL DEFAULT 'pending',
+    "kyc_reviewed_by" varchar NOT NULL DEFAULT '',
+    "kyc_reviewed_at" timestamptz,
+    "kyc_verified_at" timestamptz,
+    "kyc_rejection_reason" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "customers_kyc_status" CHECK ("kyc_status" IN ('pending', 'verified', 'rejected'))
+);
+
+ALTER TABLE "accounts" ADD COLUMN "customer_id" bigint REFERENCES "customers" ("id");
+
+CREATE INDEX ON "accounts" ("customer_id");
+
+COMMENT ON COLUMN "customers"."kyc_status" IS 'pending, verified or rejected';
+COMMENT ON COLUMN "customers"."kyc_verified_at" IS 'set when the customer is verified, cleared when it is rejected';
+COMMENT ON COLUMN "accounts"."customer_id" IS 'null for the internal accounts of the bank and the accounts opened before customers existed';
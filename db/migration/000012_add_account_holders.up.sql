@@ -0,0 +1,18 @@
+CREATE TABLE "account_holders" (
+    "account_id" bigint NOT NULL REFERENCES "accounts" ("id") ON DELETE CASCADE,
+    "username" varchar NOT NULL,
+    "role" varchar NOT NULL,
+    "transfer_limit" bigint,
+    "invited_by" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("account_id", "username"),
+    CONSTRAINT "account_holders_role" CHECK ("role" IN ('co_owner', 'viewer', 'signatory')),
+    CONSTRAINT "account_holders_transfer_limit" CHECK (
+        ("role" = 'signatory' AND "transfer_limit" > 0) OR ("role" <> 'signatory' AND "transfer_limit" IS NULL)
+    )
+);
+
+CREATE INDEX ON "account_holders" ("username");
+
+COMMENT ON COLUMN "account_holders"."role" IS 'co_owner, viewer or signatory, the owner of the account is accounts.owner';
+COMMENT ON COLUMN "account_holders"."transfer_limit" IS 'maximum amount of a single transfer made by a signatory';
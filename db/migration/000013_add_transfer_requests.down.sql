@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transfer_requests;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "approval_threshold";
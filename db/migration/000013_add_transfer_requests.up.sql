@@ -0,0 +1,28 @@
+ALTER TABLE "accounts" ADD COLUMN "approval_threshold" bigint CHECK ("approval_threshold" > 0);
+
+CREATE TABLE "transfer_requests" (
+    "id" bigserial PRIMARY KEY,
+    "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "amount" bigint NOT NULL CHECK ("amount" > 0),
+    "reference" varchar NOT NULL DEFAULT '',
+    "description" varchar NOT NULL DEFAULT '',
+    "metadata" jsonb NOT NULL DEFAULT '{}',
+    "status" varchar NOT NULL DEFAULT 'pending_approval',
+    "requested_by" varchar NOT NULL,
+    "reviewed_by" varchar NOT NULL DEFAULT '',
+    "reviewed_at" timestamptz,
+    "rejection_reason" varchar NOT NULL DEFAULT '',
+    "transfer_id" bigint REFERENCES "transfers" ("id"),
+    "expires_at" timestamptz NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    CONSTRAINT "transfer_requests_status" CHECK ("status" IN ('pending_approval', 'approved', 'rejected', 'expired'))
+);
+
+CREATE INDEX ON "transfer_requests" ("from_account_id", "created_at");
+
+CREATE INDEX ON "transfer_requests" ("expires_at") WHERE "status" = 'pending_approval';
+
+COMMENT ON COLUMN "accounts"."approval_threshold" IS 'transfers above this amount need the approval of a second holder, null when no approval is needed';
+COMMENT ON COLUMN "transfer_requests"."status" IS 'pending_approval, approved, rejected or expired';
+COMMENT ON COLUMN "transfer_requests"."transfer_id" IS 'the transfer executed when the request was approved';
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS conversions;
+DROP TABLE IF EXISTS fx_rates;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "wallet_id";
+
+DROP TABLE IF EXISTS wallets;
@@ -0,0 +1,40 @@
+CREATE TABLE "wallets" (
+    "id" bigserial PRIMARY KEY,
+    "customer_id" bigint NOT NULL REFERENCES "customers" ("id"),
+    "owner" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "wallets" ("customer_id");
+
+ALTER TABLE "accounts" ADD COLUMN "wallet_id" bigint REFERENCES "wallets" ("id");
+
+CREATE UNIQUE INDEX "accounts_wallet_currency_key" ON "accounts" ("wallet_id", "currency");
+
+CREATE TABLE "fx_rates" (
+    "base_currency" varchar NOT NULL,
+    "quote_currency" varchar NOT NULL,
+    "rate" numeric NOT NULL CHECK ("rate" > 0),
+    "updated_at" timestamptz NOT NULL DEFAULT (now()),
+    PRIMARY KEY ("base_currency", "quote_currency")
+);
+
+CREATE TABLE "conversions" (
+    "id" bigserial PRIMARY KEY,
+    "wallet_id" bigint NOT NULL REFERENCES "wallets" ("id"),
+    "from_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "to_account_id" bigint NOT NULL REFERENCES "accounts" ("id"),
+    "from_amount" bigint NOT NULL CHECK ("from_amount" > 0),
+    "to_amount" bigint NOT NULL CHECK ("to_amount" > 0),
+    "rate" numeric NOT NULL,
+    "actor" varchar NOT NULL,
+    "from_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+    "to_entry_id" bigint NOT NULL REFERENCES "entries" ("id"),
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "conversions" ("wallet_id", "id");
+
+COMMENT ON COLUMN "accounts"."wallet_id" IS 'the wallet the account is the balance of, in its currency';
+COMMENT ON COLUMN "fx_rates"."rate" IS 'units of the quote currency for one unit of the base currency';
+COMMENT ON COLUMN "conversions"."rate" IS 'units of the to currency for one unit of the from currency';
@@ -0,0 +1,15 @@
+DELETE FROM "fx_rates" AS "older"
+USING "fx_rates" AS "newer"
+WHERE "newer"."base_currency" = "older"."base_currency"
+    AND "newer"."quote_currency" = "older"."quote_currency"
+    AND "newer"."effective_from" > "older"."effective_from";
+
+ALTER TABLE "fx_rates" DROP CONSTRAINT "fx_rates_pkey";
+
+ALTER TABLE "fx_rates" ADD PRIMARY KEY ("base_currency", "quote_currency");
+
+ALTER TABLE "fx_rates" DROP COLUMN IF EXISTS "created_at";
+
+ALTER TABLE "fx_rates" DROP COLUMN IF EXISTS "source";
+
+ALTER TABLE "fx_rates" RENAME COLUMN "effective_from" TO "updated_at";
@@ -0,0 +1,12 @@
+ALTER TABLE "fx_rates" RENAME COLUMN "updated_at" TO "effective_from";
+
+ALTER TABLE "fx_rates" ADD COLUMN "source" varchar NOT NULL DEFAULT 'manual';
+
+ALTER TABLE "fx_rates" ADD COLUMN "created_at" timestamptz NOT NULL DEFAULT (now());
+
+ALTER TABLE "fx_rates" DROP CONSTRAINT "fx_rates_pkey";
+
+ALTER TABLE "fx_rates" ADD PRIMARY KEY ("base_currency", "quote_currency", "effective_from");
+
+COMMENT ON COLUMN "fx_rates"."effective_from" IS 'the rate is valid from this time until the next rate of the pair';
+COMMENT ON COLUMN "fx_rates"."source" IS 'manual, csv or ecb';
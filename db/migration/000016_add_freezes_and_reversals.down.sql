@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS transfer_reversals;
+
+ALTER TABLE "accounts" DROP COLUMN IF EXISTS "frozen_at";
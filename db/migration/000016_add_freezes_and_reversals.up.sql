@@ -0,0 +1,14 @@
+ALTER TABLE "accounts" ADD COLUMN "frozen_at" timestamptz;
+
+COMMENT ON COLUMN "accounts"."frozen_at" IS 'no money moves in or out of a frozen account, null when the account is not frozen';
+
+CREATE TABLE "transfer_reversals" (
+    "id" bigserial PRIMARY KEY,
+    "transfer_id" bigint UNIQUE NOT NULL REFERENCES "transfers" ("id"),
+    "reversal_id" bigint UNIQUE NOT NULL REFERENCES "transfers" ("id"),
+    "reason" varchar NOT NULL,
+    "actor" varchar NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "transfer_reversals"."reversal_id" IS 'the transfer moving the amount back, the fee of the reversed transfer is not refunded';
@@ -0,0 +1,3 @@
+ALTER TABLE "outbox" ADD COLUMN "claimed_until" timestamptz;
+
+COMMENT ON COLUMN "outbox"."claimed_until" IS 'a relay is publishing the event until then, null when no relay claimed it';
@@ -0,0 +1,15 @@
+CREATE TABLE "webhook_jobs" (
+    "id" bigserial PRIMARY KEY,
+    "subscription_id" bigint NOT NULL REFERENCES "webhook_subscriptions" ("id") ON DELETE CASCADE,
+    "event_id" bigint NOT NULL REFERENCES "outbox" ("id"),
+    "attempts" int NOT NULL DEFAULT 0,
+    "next_attempt_at" timestamptz NOT NULL DEFAULT (now()),
+    "claimed_until" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now()),
+    UNIQUE ("subscription_id", "event_id")
+);
+
+CREATE INDEX ON "webhook_jobs" ("next_attempt_at");
+
+COMMENT ON COLUMN "webhook_jobs"."attempts" IS 'failed delivery attempts so far';
+COMMENT ON COLUMN "webhook_jobs"."claimed_until" IS 'a dispatcher is delivering the event until then, null when no dispatcher claimed it';
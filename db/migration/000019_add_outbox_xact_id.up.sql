@@ -0,0 +1,5 @@
+ALTER TABLE "outbox" ADD COLUMN "xact_id" bigint NOT NULL DEFAULT txid_current();
+
+CREATE INDEX ON "outbox" ("xact_id", "id");
+
+COMMENT ON COLUMN "outbox"."xact_id" IS 'the transaction that created the event, the events are streamed in its order once it ended';
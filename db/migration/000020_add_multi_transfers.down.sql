@@ -0,0 +1,3 @@
+ALTER TABLE "entries" DROP COLUMN IF EXISTS "multi_transfer_id";
+
+DROP TABLE IF EXISTS multi_transfers;
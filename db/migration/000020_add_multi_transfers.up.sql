@@ -0,0 +1,14 @@
+CREATE TABLE "multi_transfers" (
+    "id" bigserial PRIMARY KEY,
+    "reverses_id" bigint UNIQUE REFERENCES "multi_transfers" ("id"),
+    "reason" varchar NOT NULL DEFAULT '',
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "entries" ADD COLUMN "multi_transfer_id" bigint REFERENCES "multi_transfers" ("id");
+
+CREATE INDEX ON "entries" ("multi_transfer_id");
+
+COMMENT ON COLUMN "multi_transfers"."reverses_id" IS 'the multi transfer this one moves back, null when it is not a reversal';
+COMMENT ON COLUMN "multi_transfers"."reason" IS 'reason of the reversal, empty when it is not a reversal';
+COMMENT ON COLUMN "entries"."multi_transfer_id" IS 'null when the entry doesn''t belong to a multi transfer';
@@ -16,11 +16,11 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
--- name: AddAccountBalance :one
-UPDATE accounts
-SET balance = balance + sqlc.arg(amount)
-WHERE id = sqlc.arg(id)
-    RETURNING *;
+-- The accounts the user owns or holds
+-- name: ListAccountIDsByHolder :many
+SELECT id FROM accounts
+WHERE owner = $1 OR id IN (SELECT account_id FROM account_holders WHERE username = $1)
+ORDER BY id;
 
 -- OFFSET is for skip this many rows before starting to return the result (for pagination)
 -- name: ListAccounts :many
@@ -29,12 +29,27 @@ ORDER BY id
 LIMIT $1
 OFFSET $2;
 
--- name: UpdateAccount :one
-UPDATE accounts
-SET balance = $2
-WHERE id = $1
-RETURNING *;
-
 -- :exec is to just execute without any return.
 -- name: DeleteAccount :exec
 DELETE FROM accounts WHERE id = $1;
+
+-- Keyset pagination of the accounts a user owns or holds, resuming after the cursor
+-- name: ListAccountsAsc :many
+SELECT * FROM accounts
+WHERE (owner = sqlc.arg(holder) OR id IN (SELECT account_id FROM account_holders WHERE username = sqlc.arg(holder)))
+    AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListAccountsDesc :many
+SELECT * FROM accounts
+WHERE (owner = sqlc.arg(holder) OR id IN (SELECT account_id FROM account_holders WHERE username = sqlc.arg(holder)))
+    AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: SetAccountFrozenAt :one
+UPDATE accounts
+SET frozen_at = $2
+WHERE id = $1
+RETURNING *;
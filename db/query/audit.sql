@@ -0,0 +1,34 @@
+-- The lock serializes the appends, so every row is chained to the row committed right before it
+-- name: LockAuditLog :exec
+SELECT pg_advisory_xact_lock(72530001);
+
+-- name: GetLastAuditHash :one
+SELECT hash FROM audit_log
+ORDER BY id DESC
+LIMIT 1;
+
+-- name: CreateAuditLog :one
+INSERT INTO audit_log (
+    actor,
+    action,
+    entity_type,
+    entity_id,
+    "before",
+    "after",
+    prev_hash,
+    hash,
+    created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
+
+-- name: ListAuditLog :many
+SELECT * FROM audit_log
+WHERE id > sqlc.arg(after_id)
+ORDER BY id
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListAuditLogByEntity :many
+SELECT * FROM audit_log
+WHERE entity_type = $1 AND entity_id = $2
+ORDER BY id;
@@ -0,0 +1,40 @@
+-- name: CreateCustomer :one
+INSERT INTO customers (
+    legal_name,
+    date_of_birth,
+    address,
+    national_id
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetCustomer :one
+SELECT * FROM customers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetCustomerForUpdate :one
+SELECT * FROM customers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: UpdateCustomerKYC :one
+UPDATE customers
+SET kyc_status = sqlc.arg(kyc_status),
+    kyc_reviewed_by = sqlc.arg(kyc_reviewed_by),
+    kyc_reviewed_at = now(),
+    kyc_verified_at = CASE WHEN sqlc.arg(kyc_status)::varchar = 'verified' THEN now() END,
+    kyc_rejection_reason = sqlc.arg(kyc_rejection_reason)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- The KYC status is null when the account has no customer
+-- name: GetAccountKYCStatus :one
+SELECT customers.kyc_status FROM accounts
+LEFT JOIN customers ON customers.id = accounts.customer_id
+WHERE accounts.id = $1;
+
+-- name: SetAccountCustomer :one
+UPDATE accounts
+SET customer_id = $2
+WHERE id = $1
+RETURNING *;
@@ -1,9 +1,11 @@
 -- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id,
+    multi_transfer_id
 ) VALUES (
-             $1, $2
+             $1, $2, $3, $4
          ) RETURNING *;
 
 -- name: GetEntry :one
@@ -15,4 +17,38 @@ SELECT * FROM entries
 WHERE account_id = $1
 ORDER BY id
     LIMIT $2
-OFFSET $3;
\ No newline at end of file
+OFFSET $3;
+
+-- Keyset pagination of the entries of an account, resuming after the cursor
+-- name: ListEntriesAsc :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+    AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListEntriesDesc :many
+SELECT * FROM entries
+WHERE account_id = sqlc.arg(account_id)
+    AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListStatementLines :many
+SELECT e.id,
+    e.amount,
+    e.created_at,
+    e.transfer_id,
+    COALESCE(t.reference, '')::varchar AS reference,
+    COALESCE(t.description, '')::varchar AS description,
+    COALESCE(CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END, 0)::bigint AS counterparty_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = sqlc.arg(account_id)
+    AND e.created_at >= sqlc.arg(period_start)
+    AND e.created_at < sqlc.arg(period_end)
+ORDER BY e.created_at, e.id;
+
+-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at >= $2;
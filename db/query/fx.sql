@@ -0,0 +1,21 @@
+-- Importing the same rate twice replaces it, so a feed can be loaded again
+-- name: CreateFXRate :one
+INSERT INTO fx_rates (
+    base_currency,
+    quote_currency,
+    rate,
+    effective_from,
+    source
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (base_currency, quote_currency, effective_from) DO UPDATE
+SET rate = EXCLUDED.rate,
+    source = EXCLUDED.source
+RETURNING *;
+
+-- The rate valid at a time is the latest one effective from before it
+-- name: GetFXRateAt :one
+SELECT * FROM fx_rates
+WHERE base_currency = $1 AND quote_currency = $2 AND effective_from <= $3
+ORDER BY effective_from DESC
+LIMIT 1;
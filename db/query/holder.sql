@@ -0,0 +1,31 @@
+-- name: CreateAccountHolder :one
+INSERT INTO account_holders (
+    account_id,
+    username,
+    role,
+    transfer_limit,
+    invited_by
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetAccountHolder :one
+SELECT * FROM account_holders
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountHolders :many
+SELECT * FROM account_holders
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: DeleteAccountHolder :exec
+DELETE FROM account_holders
+WHERE account_id = $1 AND username = $2;
+
+-- The role is owner for the owner of the account and empty when the user doesn't hold the account
+-- name: GetAccountRole :one
+SELECT (CASE WHEN accounts.owner = sqlc.arg(username) THEN 'owner' ELSE COALESCE(account_holders.role, '') END)::varchar AS role,
+    account_holders.transfer_limit
+FROM accounts
+LEFT JOIN account_holders ON account_holders.account_id = accounts.id AND account_holders.username = sqlc.arg(username)
+WHERE accounts.id = sqlc.arg(account_id);
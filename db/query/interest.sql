@@ -0,0 +1,70 @@
+-- name: CreateAccountProduct :one
+INSERT INTO account_products (
+    name,
+    annual_rate_bps,
+    day_count
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetAccountProduct :one
+SELECT * FROM account_products
+WHERE id = $1 LIMIT 1;
+
+-- name: SetAccountProduct :one
+UPDATE accounts
+SET product_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ListAccountsForAccrual :many
+SELECT accounts.id, accounts.balance, products.annual_rate_bps, products.day_count
+FROM accounts
+JOIN account_products AS products ON products.id = accounts.product_id
+WHERE accounts.balance > 0
+ORDER BY accounts.id;
+
+-- ON CONFLICT makes the accrual idempotent per account and date, no row is affected when it already exists
+-- name: CreateInterestAccrual :execrows
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    amount_micros
+) VALUES (
+    $1, $2, $3, $4
+) ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- GetLastAccrualDate returns an invalid date when nothing was accrued yet
+-- name: GetLastAccrualDate :one
+SELECT MAX(accrual_date)::date AS last_accrual_date FROM interest_accruals;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date;
+
+-- name: ListAccruedInterest :many
+SELECT accruals.account_id, accounts.currency, SUM(accruals.amount_micros)::bigint AS total_micros
+FROM interest_accruals AS accruals
+JOIN accounts ON accounts.id = accruals.account_id
+WHERE accruals.accrual_date >= sqlc.arg(period_start) AND accruals.accrual_date < sqlc.arg(period_end)
+GROUP BY accruals.account_id, accounts.currency
+ORDER BY accruals.account_id;
+
+-- ON CONFLICT makes the posting idempotent per account and period, sql.ErrNoRows is returned when it already exists
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+    account_id,
+    period,
+    amount,
+    entry_id,
+    expense_entry_id
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id, period) DO NOTHING
+RETURNING *;
+
+-- name: GetInterestPosting :one
+SELECT * FROM interest_postings
+WHERE account_id = $1 AND period = $2 LIMIT 1;
@@ -0,0 +1,26 @@
+-- Accounts whose balance is not the sum of their entries
+-- name: ListUnbalancedAccounts :many
+SELECT a.id, a.currency, a.balance, COALESCE(SUM(e.amount), 0)::bigint AS entries_total
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- Transfers whose entries don't sum to zero
+-- name: ListUnbalancedTransfers :many
+SELECT transfer_id::bigint AS transfer_id, SUM(amount)::bigint AS entries_total
+FROM entries
+WHERE transfer_id IS NOT NULL
+GROUP BY transfer_id
+HAVING SUM(amount) <> 0
+ORDER BY transfer_id;
+
+-- Currencies whose entries don't sum to zero, every movement of money has a counterpart in its currency
+-- name: ListUnbalancedCurrencies :many
+SELECT a.currency, SUM(e.amount)::bigint AS entries_total
+FROM entries e
+JOIN accounts a ON a.id = e.account_id
+GROUP BY a.currency
+HAVING SUM(e.amount) <> 0
+ORDER BY a.currency;
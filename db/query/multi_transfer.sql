@@ -0,0 +1,27 @@
+-- name: CreateMultiTransfer :one
+INSERT INTO multi_transfers (
+    reverses_id,
+    reason
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetMultiTransfer :one
+SELECT * FROM multi_transfers
+WHERE id = $1 LIMIT 1;
+
+-- GetMultiTransferReversal returns the multi transfer reversing the given one
+-- name: GetMultiTransferReversal :one
+SELECT * FROM multi_transfers
+WHERE reverses_id = $1 LIMIT 1;
+
+-- name: ListMultiTransfers :many
+SELECT * FROM multi_transfers
+ORDER BY id
+LIMIT $1
+OFFSET $2;
+
+-- name: ListMultiTransferEntries :many
+SELECT * FROM entries
+WHERE multi_transfer_id = $1
+ORDER BY id;
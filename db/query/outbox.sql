@@ -0,0 +1,60 @@
+-- name: CreateOutboxEvent :one
+INSERT INTO outbox (
+    event_type,
+    aggregate_id,
+    payload
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- SKIP LOCKED and claimed_until let several relays run at the same time without publishing the same events,
+-- an event whose claim expired before it was delivered is claimed again
+-- name: ClaimOutboxEvents :many
+UPDATE outbox
+SET claimed_until = sqlc.arg(claimed_until)::timestamptz
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE delivered_at IS NULL AND (claimed_until IS NULL OR claimed_until < sqlc.arg(now)::timestamptz)
+    ORDER BY id
+    LIMIT sqlc.arg(max_events)
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: ReleaseOutboxEvents :exec
+UPDATE outbox
+SET claimed_until = NULL
+WHERE id = ANY(sqlc.arg(ids)::bigint[]) AND delivered_at IS NULL;
+
+-- name: GetOutboxEvent :one
+SELECT * FROM outbox
+WHERE id = $1 LIMIT 1;
+
+-- name: MarkOutboxEventDelivered :exec
+UPDATE outbox
+SET delivered_at = now()
+WHERE id = $1;
+
+-- name: ListOutboxEventsByAggregate :many
+SELECT * FROM outbox
+WHERE aggregate_id = $1 AND event_type = ANY(sqlc.arg(event_types)::varchar[])
+ORDER BY id;
+
+-- The notification is delivered to the listeners when the transaction commits
+-- name: NotifyOutboxEvent :exec
+SELECT pg_notify('outbox_events', sqlc.arg(payload)::text);
+
+-- Every transaction before the horizon has ended, the ones from it may still be running
+-- name: GetOutboxHorizon :one
+SELECT txid_snapshot_xmin(txid_current_snapshot()) AS horizon;
+
+-- The events are listed in the order of their transaction and only once it ended,
+-- so an event listed later is always after the events listed before it
+-- name: ListAccountEventsAfter :many
+SELECT * FROM outbox
+WHERE (xact_id, id) > (sqlc.arg(after_xact_id)::bigint, sqlc.arg(after_id)::bigint)
+    AND xact_id < txid_snapshot_xmin(txid_current_snapshot())
+    AND aggregate_id = ANY(sqlc.arg(account_ids)::bigint[])
+    AND event_type IN ('AccountCredited', 'AccountDebited')
+ORDER BY xact_id, id
+LIMIT sqlc.arg(max_events);
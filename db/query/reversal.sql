@@ -0,0 +1,15 @@
+-- name: CreateTransferReversal :one
+INSERT INTO transfer_reversals (
+    transfer_id,
+    reversal_id,
+    reason,
+    actor
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- The reversal the transfer belongs to, as the reversed transfer or as the reversal
+-- name: GetTransferReversal :one
+SELECT * FROM transfer_reversals
+WHERE transfer_id = $1 OR reversal_id = $1
+LIMIT 1;
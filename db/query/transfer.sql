@@ -2,9 +2,13 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    fee,
+    reference,
+    description,
+    metadata
 ) VALUES (
-             $1, $2, $3
+             $1, $2, $3, $4, $5, $6, $7
          ) RETURNING *;
 
 -- name: GetTransfer :one
@@ -18,4 +22,19 @@ WHERE
         to_account_id = $2
 ORDER BY id
     LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- Keyset pagination of the transfers from or to an account, resuming after the cursor
+-- name: ListTransfersAsc :many
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+    AND (created_at, id) > (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT sqlc.arg(max_rows);
+
+-- name: ListTransfersDesc :many
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+    AND (created_at, id) < (sqlc.arg(cursor_created_at)::timestamptz, sqlc.arg(cursor_id)::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_rows);
@@ -0,0 +1,49 @@
+-- name: CreateTransferRequest :one
+INSERT INTO transfer_requests (
+    from_account_id,
+    to_account_id,
+    amount,
+    reference,
+    description,
+    metadata,
+    requested_by,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetTransferRequest :one
+SELECT * FROM transfer_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetTransferRequestForUpdate :one
+SELECT * FROM transfer_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListPendingTransferRequests :many
+SELECT * FROM transfer_requests
+WHERE from_account_id = $1 AND status = 'pending_approval' AND expires_at > now()
+ORDER BY created_at, id;
+
+-- name: ReviewTransferRequest :one
+UPDATE transfer_requests
+SET status = $2,
+    reviewed_by = $3,
+    reviewed_at = now(),
+    rejection_reason = $4,
+    transfer_id = $5
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpireTransferRequests :many
+UPDATE transfer_requests
+SET status = 'expired'
+WHERE status = 'pending_approval' AND expires_at <= $1
+RETURNING *;
+
+-- name: SetAccountApprovalThreshold :one
+UPDATE accounts
+SET approval_threshold = $2
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,46 @@
+-- name: CreateWallet :one
+INSERT INTO wallets (
+    customer_id,
+    owner
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetWallet :one
+SELECT * FROM wallets
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateWalletAccount :one
+INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    customer_id,
+    wallet_id
+) VALUES (
+    $1, 0, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWalletAccount :one
+SELECT * FROM accounts
+WHERE wallet_id = $1 AND currency = $2 LIMIT 1;
+
+-- name: ListWalletAccounts :many
+SELECT * FROM accounts
+WHERE wallet_id = $1
+ORDER BY currency;
+
+-- name: CreateConversion :one
+INSERT INTO conversions (
+    wallet_id,
+    from_account_id,
+    to_account_id,
+    from_amount,
+    to_amount,
+    rate,
+    actor,
+    from_entry_id,
+    to_entry_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+) RETURNING *;
@@ -0,0 +1,109 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    owner,
+    url,
+    secret,
+    event_types
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = $1
+ORDER BY id;
+
+-- An empty event_types subscribes to every event type
+-- name: ListActiveWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE owner = sqlc.arg(owner) AND active
+    AND (cardinality(event_types) = 0 OR sqlc.arg(event_type)::varchar = ANY(event_types))
+ORDER BY id;
+
+-- name: DeleteWebhookSubscription :exec
+DELETE FROM webhook_subscriptions WHERE id = $1;
+
+-- The subscription is disabled when it reaches max_failures consecutive failures
+-- name: RecordWebhookFailure :one
+UPDATE webhook_subscriptions
+SET consecutive_failures = consecutive_failures + 1,
+    active = consecutive_failures + 1 < sqlc.arg(max_failures)::int,
+    disabled_at = CASE WHEN consecutive_failures + 1 >= sqlc.arg(max_failures)::int THEN now() ELSE disabled_at END
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ResetWebhookFailures :exec
+UPDATE webhook_subscriptions
+SET consecutive_failures = 0
+WHERE id = $1;
+
+-- name: EnableWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET active = true, consecutive_failures = 0, disabled_at = NULL
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_id,
+    attempt,
+    status_code,
+    error,
+    succeeded
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- The relay publishes an event at least once, it is queued once per subscription
+-- name: EnqueueWebhookJob :exec
+INSERT INTO webhook_jobs (
+    subscription_id,
+    event_id
+) VALUES (
+    $1, $2
+) ON CONFLICT (subscription_id, event_id) DO NOTHING;
+
+-- name: ListDueWebhookSubscriptions :many
+SELECT DISTINCT webhook_jobs.subscription_id FROM webhook_jobs
+JOIN webhook_subscriptions ON webhook_subscriptions.id = webhook_jobs.subscription_id
+WHERE webhook_subscriptions.active AND webhook_jobs.next_attempt_at <= sqlc.arg(now)::timestamptz
+    AND (webhook_jobs.claimed_until IS NULL OR webhook_jobs.claimed_until < sqlc.arg(now)::timestamptz)
+ORDER BY webhook_jobs.subscription_id
+LIMIT sqlc.arg(max_subscriptions);
+
+-- The oldest due job of the subscription, the other dispatchers skip it until claimed_until
+-- name: ClaimWebhookJob :one
+UPDATE webhook_jobs
+SET claimed_until = sqlc.arg(claimed_until)::timestamptz
+WHERE id = (
+    SELECT id FROM webhook_jobs
+    WHERE subscription_id = sqlc.arg(subscription_id) AND next_attempt_at <= sqlc.arg(now)::timestamptz
+        AND (claimed_until IS NULL OR claimed_until < sqlc.arg(now)::timestamptz)
+    ORDER BY id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: RetryWebhookJob :exec
+UPDATE webhook_jobs
+SET attempts = $1, next_attempt_at = $2, claimed_until = NULL
+WHERE id = $3;
+
+-- name: DeleteWebhookJob :exec
+DELETE FROM webhook_jobs WHERE id = $1;
+
+-- name: DeleteWebhookJobsBySubscription :exec
+DELETE FROM webhook_jobs WHERE subscription_id = $1;
@@ -7,34 +7,11 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
+	"time"
 )
 
-const addAccountBalance = `-- name: AddAccountBalance :one
-UPDATE accounts
-SET balance = balance + $1
-WHERE id = $2
-    RETURNING id, owner, balance, currency, created_at
-`
-
-type AddAccountBalanceParams struct {
-	Amount int64 `json:"amount"`
-	ID     int64 `json:"id"`
-}
-
-func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, addAccountBalance, arg.Amount, arg.ID)
-	var i Account
-	err := row.Scan(
-		&i.ID,
-		&i.Owner,
-		&i.Balance,
-		&i.Currency,
-		&i.CreatedAt,
-	)
-	return i, err
-}
-
 const createAccount = `-- name: CreateAccount :one
 INSERT INTO accounts (
     owner,
@@ -42,7 +19,7 @@ INSERT INTO accounts (
     currency
 ) VALUES (
     $1, $2, $3
-) RETURNING id, owner, balance, currency, created_at
+) RETURNING id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at
 `
 
 type CreateAccountParams struct {
@@ -60,6 +37,11 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ProductID,
+		&i.CustomerID,
+		&i.ApprovalThreshold,
+		&i.WalletID,
+		&i.FrozenAt,
 	)
 
 	if err != nil {
@@ -80,7 +62,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -93,12 +75,17 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ProductID,
+		&i.CustomerID,
+		&i.ApprovalThreshold,
+		&i.WalletID,
+		&i.FrozenAt,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -112,12 +99,47 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ProductID,
+		&i.CustomerID,
+		&i.ApprovalThreshold,
+		&i.WalletID,
+		&i.FrozenAt,
 	)
 	return i, err
 }
 
+const listAccountIDsByHolder = `-- name: ListAccountIDsByHolder :many
+SELECT id FROM accounts
+WHERE owner = $1 OR id IN (SELECT account_id FROM account_holders WHERE username = $1)
+ORDER BY id
+`
+
+// The accounts the user owns or holds
+func (q *Queries) ListAccountIDsByHolder(ctx context.Context, owner string) ([]int64, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountIDsByHolder, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int64
+	for rows.Next() {
+		var id int64
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at FROM accounts
 ORDER BY id
 LIMIT $1
 OFFSET $2
@@ -144,6 +166,120 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.ProductID,
+			&i.CustomerID,
+			&i.ApprovalThreshold,
+			&i.WalletID,
+			&i.FrozenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsAsc = `-- name: ListAccountsAsc :many
+SELECT id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at FROM accounts
+WHERE (owner = $1 OR id IN (SELECT account_id FROM account_holders WHERE username = $1))
+    AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListAccountsAscParams struct {
+	Holder          string    `json:"holder"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+// Keyset pagination of the accounts a user owns or holds, resuming after the cursor
+func (q *Queries) ListAccountsAsc(ctx context.Context, arg ListAccountsAscParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsAsc,
+		arg.Holder,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.ProductID,
+			&i.CustomerID,
+			&i.ApprovalThreshold,
+			&i.WalletID,
+			&i.FrozenAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsDesc = `-- name: ListAccountsDesc :many
+SELECT id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at FROM accounts
+WHERE (owner = $1 OR id IN (SELECT account_id FROM account_holders WHERE username = $1))
+    AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListAccountsDescParams struct {
+	Holder          string    `json:"holder"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+func (q *Queries) ListAccountsDesc(ctx context.Context, arg ListAccountsDescParams) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsDesc,
+		arg.Holder,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Account
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.ProductID,
+			&i.CustomerID,
+			&i.ApprovalThreshold,
+			&i.WalletID,
+			&i.FrozenAt,
 		); err != nil {
 			return nil, err
 		}
@@ -158,20 +294,20 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 	return items, nil
 }
 
-const updateAccount = `-- name: UpdateAccount :one
+const setAccountFrozenAt = `-- name: SetAccountFrozenAt :one
 UPDATE accounts
-SET balance = $2
+SET frozen_at = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at
 `
 
-type UpdateAccountParams struct {
-	ID      int64 `json:"id"`
-	Balance int64 `json:"balance"`
+type SetAccountFrozenAtParams struct {
+	ID       int64        `json:"id"`
+	FrozenAt sql.NullTime `json:"frozen_at"`
 }
 
-func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, updateAccount, arg.ID, arg.Balance)
+func (q *Queries) SetAccountFrozenAt(ctx context.Context, arg SetAccountFrozenAtParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, setAccountFrozenAt, arg.ID, arg.FrozenAt)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -179,6 +315,11 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.ProductID,
+		&i.CustomerID,
+		&i.ApprovalThreshold,
+		&i.WalletID,
+		&i.FrozenAt,
 	)
 	return i, err
 }
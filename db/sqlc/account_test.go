@@ -28,7 +28,7 @@ func createRandomAccount(t *testing.T) Account {
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
 
-	return account
+	return withVerifiedCustomer(t, account)
 }
 
 func TestQueries_CreateAccount(t *testing.T) {
@@ -49,26 +49,6 @@ func TestQueries_GetAccount(t *testing.T) {
 	require.WithinDuration(t, account.CreatedAt, accountFound.CreatedAt, time.Second)
 }
 
-func TestQueries_UpdateAccount(t *testing.T) {
-	account := createRandomAccount(t)
-
-	accountToUpdate := UpdateAccountParams{
-		ID:      account.ID,
-		Balance: util.RandomMoney(),
-	}
-
-	accountUpdated, err := testQueries.UpdateAccount(context.Background(), accountToUpdate)
-
-	require.NoError(t, err)
-	require.NotEmpty(t, accountUpdated)
-
-	require.Equal(t, account.ID, accountUpdated.ID)
-	require.Equal(t, account.Owner, accountUpdated.Owner)
-	require.Equal(t, accountToUpdate.Balance, accountUpdated.Balance)
-	require.Equal(t, account.Currency, accountUpdated.Currency)
-	require.WithinDuration(t, account.CreatedAt, accountUpdated.CreatedAt, time.Second)
-}
-
 func TestQueries_DeleteAccount(t *testing.T) {
 	account := createRandomAccount(t)
 	err := testQueries.DeleteAccount(context.Background(), account.ID)
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrZeroAdjustment       = errors.New("adjustment amount must not be zero")
+	ErrNoAdjustmentReason   = errors.New("adjustment reason is required")
+	ErrNoAdjustmentActor    = errors.New("adjustment actor is required")
+	ErrNoSuspenseAccount    = errors.New("no suspense account for the currency")
+	ErrAdjustSuspenseItself = errors.New("the suspense account can't be adjusted against itself")
+)
+
+// WithSuspenseAccounts sets the accounts that balance adjustments are booked against, by currency
+func WithSuspenseAccounts(accounts map[string]int64) StoreOption {
+	return func(store *Store) {
+		store.suspenseAccounts = accounts
+	}
+}
+
+type AdjustBalanceResult struct {
+	Adjustment BalanceAdjustment `json:"adjustment"`
+	// Account has its adjusted balance
+	Account         Account `json:"account"`
+	Entry           Entry   `json:"entry"`
+	SuspenseAccount Account `json:"suspense_account"`
+	SuspenseEntry   Entry   `json:"suspense_entry"`
+}
+
+// AdjustBalance corrects the balance of an account by amount, positive to credit and negative to debit it.
+// The counterpart is booked on the suspense account of the account currency, so the ledger stays balanced,
+// and the adjustment is recorded with its reason and actor, both in the balance_adjustments table and in the audit log.
+func (store *Store) AdjustBalance(
+	ctx context.Context,
+	accountId int64,
+	amount int64,
+	reason string,
+	actor string,
+) (AdjustBalanceResult, error) {
+	var result AdjustBalanceResult
+
+	reason = strings.TrimSpace(reason)
+	switch {
+	case amount == 0:
+		return result, ErrZeroAdjustment
+	case reason == "":
+		return result, ErrNoAdjustmentReason
+	case actor == "":
+		return result, ErrNoAdjustmentActor
+	}
+
+	ctx = WithActor(ctx, actor)
+
+	txCtx := withTxTrace(ctx, "AdjustBalance", attribute.Int64("bank.account_id", accountId), attribute.Int64("bank.amount", amount))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, accountId)
+		if err != nil {
+			return err
+		}
+
+		suspenseAccountId, ok := store.suspenseAccounts[account.Currency]
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrNoSuspenseAccount, account.Currency)
+		}
+		if suspenseAccountId == accountId {
+			return ErrAdjustSuspenseItself
+		}
+
+		legs := []TransferLeg{
+			{AccountId: accountId, Amount: amount},
+			{AccountId: suspenseAccountId, Amount: -amount},
+		}
+
+		before, err := lockAccounts(q, ctx, sortedLegAccountIds(legs))
+		if err != nil {
+			return err
+		}
+
+		if before[suspenseAccountId].Currency != account.Currency {
+			return fmt.Errorf("suspense account %d is not in %s", suspenseAccountId, account.Currency)
+		}
+
+		result.Entry, err = createNewEntry(q, ctx, accountId, amount)
+		if err != nil {
+			return err
+		}
+
+		result.SuspenseEntry, err = createNewEntry(q, ctx, suspenseAccountId, -amount)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := updateLegsBalance(q, ctx, legs)
+		if err != nil {
+			return err
+		}
+		result.Account = accounts[accountId]
+		result.SuspenseAccount = accounts[suspenseAccountId]
+
+		err = createLegsEvents(q, ctx, []Entry{result.Entry, result.SuspenseEntry}, accounts)
+		if err != nil {
+			return err
+		}
+
+		result.Adjustment, err = q.CreateBalanceAdjustment(ctx, CreateBalanceAdjustmentParams{
+			AccountID:         accountId,
+			SuspenseAccountID: suspenseAccountId,
+			Amount:            amount,
+			Reason:            reason,
+			Actor:             actor,
+			EntryID:           result.Entry.ID,
+			SuspenseEntryID:   result.SuspenseEntry.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		after := struct {
+			Account    Account           `json:"account"`
+			Adjustment BalanceAdjustment `json:"adjustment"`
+		}{result.Account, result.Adjustment}
+
+		store.audit(q, AuditAccountAdjust, AuditEntityAccount, accountId, before[accountId], after)
+		store.auditEntries(q, result.Entry, result.SuspenseEntry)
+		return nil
+	})
+
+	return result, err
+}
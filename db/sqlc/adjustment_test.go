@@ -0,0 +1,71 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_AdjustBalance(t *testing.T) {
+	suspense := createRandomAccountWithCurrency(t, "USD")
+	account := createRandomAccountWithCurrency(t, "USD")
+	store := NewStore(testDB, WithSuspenseAccounts(map[string]int64{"USD": suspense.ID}))
+
+	result, err := store.AdjustBalance(context.Background(), account.ID, -25, "duplicate card payment", "ops-alice")
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance-25, result.Account.Balance)
+	require.Equal(t, suspense.Balance+25, result.SuspenseAccount.Balance)
+	require.Equal(t, int64(-25), result.Entry.Amount)
+	require.Equal(t, account.ID, result.Entry.AccountID)
+	require.Equal(t, int64(25), result.SuspenseEntry.Amount)
+	require.Equal(t, suspense.ID, result.SuspenseEntry.AccountID)
+
+	adjustments, err := store.ListBalanceAdjustments(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 1)
+	require.Equal(t, "duplicate card payment", adjustments[0].Reason)
+	require.Equal(t, "ops-alice", adjustments[0].Actor)
+	require.Equal(t, result.Entry.ID, adjustments[0].EntryID)
+
+	logs, err := store.ListAuditLogByEntity(context.Background(), ListAuditLogByEntityParams{
+		EntityType: AuditEntityAccount,
+		EntityID:   account.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, AuditAccountAdjust, logs[0].Action)
+	require.Equal(t, "ops-alice", logs[0].Actor)
+
+	var before Account
+	require.NoError(t, json.Unmarshal([]byte(logs[0].Before), &before))
+	require.Equal(t, account.Balance, before.Balance)
+}
+
+func TestStore_AdjustBalanceInvalid(t *testing.T) {
+	account := createRandomAccountWithCurrency(t, "EUR")
+	store := NewStore(testDB, WithSuspenseAccounts(map[string]int64{"EUR": account.ID}))
+	ctx := context.Background()
+
+	_, err := store.AdjustBalance(ctx, account.ID, 0, "reason", "ops")
+	require.ErrorIs(t, err, ErrZeroAdjustment)
+
+	_, err = store.AdjustBalance(ctx, account.ID, 10, "  ", "ops")
+	require.ErrorIs(t, err, ErrNoAdjustmentReason)
+
+	_, err = store.AdjustBalance(ctx, account.ID, 10, "reason", "")
+	require.ErrorIs(t, err, ErrNoAdjustmentActor)
+
+	_, err = store.AdjustBalance(ctx, account.ID, 10, "reason", "ops")
+	require.ErrorIs(t, err, ErrAdjustSuspenseItself)
+
+	usd := createRandomAccountWithCurrency(t, "USD")
+	_, err = store.AdjustBalance(ctx, usd.ID, 10, "reason", "ops")
+	require.ErrorIs(t, err, ErrNoSuspenseAccount)
+
+	unchanged, err := store.GetAccount(ctx, usd.ID)
+	require.NoError(t, err)
+	require.Equal(t, usd.Balance, unchanged.Balance)
+}
@@ -0,0 +1,344 @@
+package db
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Actions written to the audit log
+const (
+	AuditAccountCreate               = "account.create"
+	AuditAccountAdjust               = "account.adjust"
+	AuditAccountDelete               = "account.delete"
+	AuditAccountSetProduct           = "account.set_product"
+	AuditAccountSetCustomer          = "account.set_customer"
+	AuditAccountAddHolder            = "account.add_holder"
+	AuditAccountRemoveHolder         = "account.remove_holder"
+	AuditAccountSetApprovalThreshold = "account.set_approval_threshold"
+	AuditAccountFreeze               = "account.freeze"
+	AuditAccountUnfreeze             = "account.unfreeze"
+	AuditTransferCreate              = "transfer.create"
+	AuditTransferReverse             = "transfer.reverse"
+	AuditMultiTransferCreate         = "multi_transfer.create"
+	AuditMultiTransferReverse        = "multi_transfer.reverse"
+	AuditEntryCreate                 = "entry.create"
+	AuditTransferRequestCreate       = "transfer_request.create"
+	AuditTransferRequestApprove      = "transfer_request.approve"
+	AuditTransferRequestReject       = "transfer_request.reject"
+	AuditTransferRequestExpire       = "transfer_request.expire"
+	AuditCustomerCreate              = "customer.create"
+	AuditCustomerKYC                 = "customer.kyc"
+	AuditWalletCreate                = "wallet.create"
+	AuditWalletConvert               = "wallet.convert"
+)
+
+// Entity types of the audit log
+const (
+	AuditEntityAccount         = "account"
+	AuditEntityTransfer        = "transfer"
+	AuditEntityMultiTransfer   = "multi_transfer"
+	AuditEntityEntry           = "entry"
+	AuditEntityCustomer        = "customer"
+	AuditEntityTransferRequest = "transfer_request"
+	AuditEntityWallet          = "wallet"
+)
+
+// SystemActor is the actor of the mutations whose context has no actor
+const SystemActor = "system"
+
+// GenesisHash is the previous hash of the first audit log row
+var GenesisHash = strings.Repeat("0", sha256.Size*2)
+
+var ErrAuditChainBroken = errors.New("audit log hash chain is broken")
+
+type actorKey struct{}
+
+type operatorKey struct{}
+
+// WithActor returns a context whose mutations are recorded in the audit log as made by actor
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// WithOperator returns a context whose mutations are recorded in the audit log as made by a bank operator.
+// Unlike an actor, the operator acts as the bank itself: the roles of the account holders,
+// the approval thresholds and the other checks of the users don't apply to it.
+func WithOperator(ctx context.Context, operator string) context.Context {
+	return context.WithValue(ctx, operatorKey{}, operator)
+}
+
+// ActorFromContext returns the actor set by WithActor, else the operator set by WithOperator,
+// SystemActor when there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := userActor(ctx); ok {
+		return actor
+	}
+	if operator, ok := ctx.Value(operatorKey{}).(string); ok && operator != "" {
+		return operator
+	}
+	return SystemActor
+}
+
+// userActor returns the actor set by WithActor, false for the mutations made by the system itself
+func userActor(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok && actor != ""
+}
+
+type auditRecord struct {
+	action     string
+	entityType string
+	entityId   int64
+	before     interface{}
+	after      interface{}
+}
+
+// auditTrail collects the audit records of a database transaction,
+// they are appended to the log right before it commits
+type auditTrail struct {
+	records []auditRecord
+}
+
+// audit records a mutation made with the queries of a transaction started by execTx.
+// A nil before or after is stored as the JSON null.
+func (store *Store) audit(q *Queries, action, entityType string, entityId int64, before, after interface{}) {
+	trail, ok := store.trails.Load(q)
+	if !ok {
+		panic("audit: queries don't belong to a transaction of the store")
+	}
+
+	trail.(*auditTrail).records = append(trail.(*auditTrail).records, auditRecord{
+		action:     action,
+		entityType: entityType,
+		entityId:   entityId,
+		before:     before,
+		after:      after,
+	})
+}
+
+// appendAuditTrail appends the records to the audit log, chaining every row to the previous one.
+//
+// The chain needs the hash of the row committed right before, so the appends are serialized by an advisory lock
+// held until the transaction ends: every audited transaction of the cluster waits for the commit of the one before.
+// To keep that window short, the records are encoded before the lock is taken, and runTx calls this last,
+// right before the commit. The audited write throughput is then bounded by one commit at a time, about the
+// inverse of the commit latency (the WAL flush), whatever the number of connections. Taking row locks while
+// holding the lock could also deadlock with the transactions waiting for it, so nothing may run after it.
+func appendAuditTrail(q *Queries, ctx context.Context, trail *auditTrail) error {
+	if len(trail.records) == 0 {
+		return nil
+	}
+
+	actor := ActorFromContext(ctx)
+	rows := make([]CreateAuditLogParams, len(trail.records))
+	for i, record := range trail.records {
+		before, err := json.Marshal(record.before)
+		if err != nil {
+			return err
+		}
+
+		after, err := json.Marshal(record.after)
+		if err != nil {
+			return err
+		}
+
+		rows[i] = CreateAuditLogParams{
+			Actor:      actor,
+			Action:     record.action,
+			EntityType: record.entityType,
+			EntityID:   record.entityId,
+			Before:     string(before),
+			After:      string(after),
+		}
+	}
+
+	err := q.LockAuditLog(ctx)
+	if err != nil {
+		return err
+	}
+
+	prevHash, err := q.GetLastAuditHash(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		prevHash = GenesisHash
+	} else if err != nil {
+		return err
+	}
+
+	// Postgres keeps microseconds, the hash must be computed on what is read back
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+
+	for _, arg := range rows {
+		arg.PrevHash = prevHash
+		arg.CreatedAt = createdAt
+		arg.Hash = AuditHash(AuditLog{
+			Actor:      arg.Actor,
+			Action:     arg.Action,
+			EntityType: arg.EntityType,
+			EntityID:   arg.EntityID,
+			Before:     arg.Before,
+			After:      arg.After,
+			PrevHash:   arg.PrevHash,
+			CreatedAt:  arg.CreatedAt,
+		})
+
+		_, err = q.CreateAuditLog(ctx, arg)
+		if err != nil {
+			return err
+		}
+		prevHash = arg.Hash
+	}
+
+	return nil
+}
+
+// AuditHash returns the hex encoded SHA-256 of the previous hash and the fields of the row, the ID and the hash are left out
+func AuditHash(row AuditLog) string {
+	fields := []string{
+		row.PrevHash,
+		row.Actor,
+		row.Action,
+		row.EntityType,
+		strconv.FormatInt(row.EntityID, 10),
+		row.Before,
+		row.After,
+		row.CreatedAt.UTC().Format(time.RFC3339Nano),
+	}
+
+	hash := sha256.New()
+	for _, field := range fields {
+		// the length prefix keeps the fields from being shifted into each other
+		fmt.Fprintf(hash, "%d:%s\n", len(field), field)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+type VerifyAuditLogResult struct {
+	// Verified counts the rows checked before the end of the log or the broken link
+	Verified int64 `json:"verified"`
+	// LastHash is the hash of the last valid row, keeping it outside of the database
+	// lets a later verification detect that rows were removed from the end of the log
+	LastHash string `json:"last_hash"`
+}
+
+// VerifyAuditLog walks the whole audit log in ID order and checks that every row links to the previous one
+// and that its hash matches its content. It returns ErrAuditChainBroken with the ID of the first broken row.
+func (store *Store) VerifyAuditLog(ctx context.Context) (VerifyAuditLogResult, error) {
+	const pageSize = 1000
+
+	result := VerifyAuditLogResult{LastHash: GenesisHash}
+	var afterId int64
+
+	for {
+		rows, err := store.ListAuditLog(ctx, ListAuditLogParams{
+			AfterID: afterId,
+			MaxRows: pageSize,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		for _, row := range rows {
+			if row.PrevHash != result.LastHash {
+				return result, fmt.Errorf("%w: row %d doesn't link to the previous row", ErrAuditChainBroken, row.ID)
+			}
+			if row.Hash != AuditHash(row) {
+				return result, fmt.Errorf("%w: row %d was modified", ErrAuditChainBroken, row.ID)
+			}
+
+			result.Verified++
+			result.LastHash = row.Hash
+			afterId = row.ID
+		}
+
+		if len(rows) < pageSize {
+			return result, nil
+		}
+	}
+}
+
+// CreateAccount creates an account and records it in the audit log
+func (store *Store) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		account, err = q.CreateAccount(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditAccountCreate, AuditEntityAccount, account.ID, nil, account)
+		return nil
+	})
+
+	return account, err
+}
+
+// SetAccountProduct changes the product of an account and records the previous and new account in the audit log
+func (store *Store) SetAccountProduct(ctx context.Context, arg SetAccountProductParams) (Account, error) {
+	return store.updateAudited(ctx, arg.ID, AuditAccountSetProduct, func(q *Queries) (Account, error) {
+		return q.SetAccountProduct(ctx, arg)
+	})
+}
+
+// DeleteAccount deletes an account and records the deleted account in the audit log
+func (store *Store) DeleteAccount(ctx context.Context, id int64) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccount(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditAccountDelete, AuditEntityAccount, id, before, nil)
+		return nil
+	})
+}
+
+func (store *Store) updateAudited(
+	ctx context.Context,
+	accountId int64,
+	action string,
+	update func(q *Queries) (Account, error),
+) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetAccountForUpdate(ctx, accountId)
+		if err != nil {
+			return err
+		}
+
+		account, err = update(q)
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, action, AuditEntityAccount, account.ID, before, account)
+		return nil
+	})
+
+	return account, err
+}
+
+// auditEntries records the creation of the entries, skipping the zero values of the entries that weren't created
+func (store *Store) auditEntries(q *Queries, entries ...Entry) {
+	for _, entry := range entries {
+		if entry.ID != 0 {
+			store.audit(q, AuditEntryCreate, AuditEntityEntry, entry.ID, nil, entry)
+		}
+	}
+}
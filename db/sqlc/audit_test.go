@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestAuditHash(t *testing.T) {
+	row := AuditLog{
+		Actor:      "alice",
+		Action:     AuditAccountCreate,
+		EntityType: AuditEntityAccount,
+		EntityID:   1,
+		Before:     `{"balance":10}`,
+		After:      `{"balance":20}`,
+		PrevHash:   GenesisHash,
+		CreatedAt:  time.Date(2023, 5, 1, 10, 0, 0, 123456000, time.UTC),
+	}
+
+	hash := AuditHash(row)
+	require.Len(t, hash, 64)
+	require.Equal(t, hash, AuditHash(row))
+
+	// the time zone doesn't change the hash, only the instant does
+	moved := row
+	moved.CreatedAt = row.CreatedAt.In(time.FixedZone("CET", 3600))
+	require.Equal(t, hash, AuditHash(moved))
+
+	tampered := row
+	tampered.After = `{"balance":2000}`
+	require.NotEqual(t, hash, AuditHash(tampered))
+
+	// moving bytes from a field to the next one changes the hash
+	shifted := row
+	shifted.Actor, shifted.Action = "alic", "e"+row.Action
+	require.NotEqual(t, hash, AuditHash(shifted))
+
+	relinked := row
+	relinked.PrevHash = hash
+	require.NotEqual(t, hash, AuditHash(relinked))
+}
+
+func TestActorFromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, SystemActor, ActorFromContext(ctx))
+	require.Equal(t, "alice", ActorFromContext(WithActor(ctx, "alice")))
+	require.Equal(t, SystemActor, ActorFromContext(WithActor(ctx, "")))
+
+	// the operator is audited but isn't a user
+	operator := WithOperator(ctx, "ops")
+	require.Equal(t, "ops", ActorFromContext(operator))
+	_, ok := userActor(operator)
+	require.False(t, ok)
+	require.Equal(t, "alice", ActorFromContext(WithActor(operator, "alice")))
+}
+
+func TestStore_AuditTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := WithActor(context.Background(), "teller-1")
+
+	account1, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  100,
+		Currency: "USD",
+	})
+	require.NoError(t, err)
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	logs, err := store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{
+		EntityType: AuditEntityAccount,
+		EntityID:   account1.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, "teller-1", logs[0].Actor)
+	require.Equal(t, AuditAccountCreate, logs[0].Action)
+	require.Equal(t, "null", logs[0].Before)
+
+	var after Account
+	require.NoError(t, json.Unmarshal([]byte(logs[0].After), &after))
+	require.Equal(t, account1.Balance, after.Balance)
+	account1 = withVerifiedCustomer(t, account1)
+
+	result, err := store.TransferTX(ctx, TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	logs, err = store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{
+		EntityType: AuditEntityTransfer,
+		EntityID:   result.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	require.Equal(t, AuditTransferCreate, logs[0].Action)
+
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		logs, err = store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{
+			EntityType: AuditEntityEntry,
+			EntityID:   entry.ID,
+		})
+		require.NoError(t, err)
+		require.Len(t, logs, 1)
+		require.Equal(t, AuditEntryCreate, logs[0].Action)
+		require.Equal(t, "teller-1", logs[0].Actor)
+	}
+}
+
+func TestStore_VerifyAuditLog(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account, err := store.CreateAccount(ctx, CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+	})
+	require.NoError(t, err)
+
+	result, err := store.VerifyAuditLog(ctx)
+	require.NoError(t, err)
+	require.Positive(t, result.Verified)
+
+	logs, err := store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{
+		EntityType: AuditEntityAccount,
+		EntityID:   account.ID,
+	})
+	require.NoError(t, err)
+	require.Len(t, logs, 1)
+	row := logs[0]
+
+	// rewrite history, then put it back so the log stays valid for the other tests
+	_, err = testDB.ExecContext(ctx, `UPDATE audit_log SET "after" = '{}' WHERE id = $1`, row.ID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, err := testDB.ExecContext(ctx, `UPDATE audit_log SET "after" = $2 WHERE id = $1`, row.ID, row.After)
+		require.NoError(t, err)
+	})
+
+	_, err = store.VerifyAuditLog(ctx)
+	require.ErrorIs(t, err, ErrAuditChainBroken)
+	require.ErrorContains(t, err, "was modified")
+}
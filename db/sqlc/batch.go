@@ -0,0 +1,257 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// BatchMode tells ExecuteBatch what to do when a transfer of the batch fails
+type BatchMode string
+
+const (
+	// BatchAllOrNothing executes every transfer in one database transaction,
+	// a single failure rolls back the whole batch
+	BatchAllOrNothing BatchMode = "all-or-nothing"
+	// BatchBestEffort executes every transfer in its own database transaction,
+	// a failure only affects its own row
+	BatchBestEffort BatchMode = "best-effort"
+)
+
+var (
+	ErrInvalidBatchMode = errors.New("invalid batch mode")
+	ErrBatchInvalid     = errors.New("batch has invalid transfers")
+	ErrBatchFailed      = errors.New("batch failed and was rolled back")
+	ErrBatchRolledBack  = errors.New("rolled back because another transfer of the batch failed")
+	ErrNotExecuted      = errors.New("not executed because the batch has invalid transfers")
+)
+
+// BatchTransfer is a single transfer of a batch
+type BatchTransfer struct {
+	FromAccountId int64  `json:"from_account_id"`
+	ToAccountId   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Reference     string `json:"reference"`
+}
+
+// ExecuteBatchParams contains the input parameters of ExecuteBatch
+type ExecuteBatchParams struct {
+	Transfers []BatchTransfer `json:"transfers"`
+	Mode      BatchMode       `json:"mode"`
+}
+
+// BatchTransferResult is the outcome of a single transfer of a batch.
+// Err is nil when the transfer was executed.
+type BatchTransferResult struct {
+	BatchTransfer
+	Transfer Transfer `json:"transfer"`
+	Err      error    `json:"-"`
+}
+
+type ExecuteBatchResult struct {
+	// Results are returned in the same order as the transfers
+	Results   []BatchTransferResult `json:"results"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+}
+
+// ExecuteBatch validates every transfer of the batch up front and then executes them according to the mode.
+// With BatchAllOrNothing nothing is executed if any transfer is invalid or fails,
+// an amount above the approval threshold of its sender included,
+// with BatchBestEffort the valid transfers are executed one by one and the failures are reported per row.
+// The returned error is only set when the batch as a whole could not be executed.
+func (store *Store) ExecuteBatch(
+	ctx context.Context,
+	params ExecuteBatchParams,
+) (ExecuteBatchResult, error) {
+	var result ExecuteBatchResult
+
+	if params.Mode != BatchAllOrNothing && params.Mode != BatchBestEffort {
+		return result, fmt.Errorf("%w: %q", ErrInvalidBatchMode, params.Mode)
+	}
+
+	result.Results = make([]BatchTransferResult, len(params.Transfers))
+	for i, transfer := range params.Transfers {
+		result.Results[i].BatchTransfer = transfer
+	}
+
+	invalid, err := store.validateBatch(ctx, result.Results)
+	if err != nil {
+		return result, err
+	}
+
+	if params.Mode == BatchAllOrNothing {
+		if invalid {
+			markNotExecuted(result.Results)
+			result.count()
+			return result, ErrBatchInvalid
+		}
+
+		err = store.executeAllOrNothing(ctx, result.Results)
+		result.count()
+		return result, err
+	}
+
+	store.executeBestEffort(ctx, result.Results)
+	result.count()
+	return result, nil
+}
+
+// validateBatch sets the error of every invalid transfer and reports whether there was any
+func (store *Store) validateBatch(ctx context.Context, results []BatchTransferResult) (invalid bool, err error) {
+	accounts := make(map[int64]*Account)
+
+	getAccount := func(id int64) (*Account, error) {
+		if account, ok := accounts[id]; ok {
+			return account, nil
+		}
+
+		account, err := store.GetAccount(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			accounts[id] = nil
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		accounts[id] = &account
+		return &account, nil
+	}
+
+	for i := range results {
+		transfer := results[i].BatchTransfer
+
+		if transfer.Amount <= 0 {
+			results[i].Err = errors.New("amount must be positive")
+			invalid = true
+			continue
+		}
+
+		if transfer.FromAccountId == transfer.ToAccountId {
+			results[i].Err = errors.New("from and to accounts must be different")
+			invalid = true
+			continue
+		}
+
+		from, err := getAccount(transfer.FromAccountId)
+		if err != nil {
+			return invalid, err
+		}
+		if from == nil {
+			results[i].Err = fmt.Errorf("from account %d not found", transfer.FromAccountId)
+			invalid = true
+			continue
+		}
+
+		to, err := getAccount(transfer.ToAccountId)
+		if err != nil {
+			return invalid, err
+		}
+		if to == nil {
+			results[i].Err = fmt.Errorf("to account %d not found", transfer.ToAccountId)
+			invalid = true
+			continue
+		}
+
+		if from.Currency != to.Currency {
+			results[i].Err = fmt.Errorf("currency mismatch: %s vs %s", from.Currency, to.Currency)
+			invalid = true
+		}
+	}
+
+	return invalid, nil
+}
+
+func (store *Store) executeAllOrNothing(ctx context.Context, results []BatchTransferResult) error {
+	failed := -1
+	var executed []TransferTxResult
+
+	txCtx := withTxTrace(ctx, "ExecuteBatch", attribute.Int("bank.transfers", len(results)))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		executed = executed[:0]
+		for i := range results {
+			params := toTransferTxParams(results[i].BatchTransfer)
+			err := checkApprovalThreshold(q, ctx, params.FromAccountId, params.Amount)
+			if err != nil {
+				failed = i
+				return err
+			}
+
+			transferResult, err := store.transferTx(q, ctx, params)
+			if err != nil {
+				failed = i
+				return err
+			}
+			results[i].Transfer = transferResult.Transfer
+			executed = append(executed, transferResult)
+		}
+		return nil
+	})
+	if err == nil {
+		for _, transferResult := range executed {
+			store.observeTransfer(transferResult, nil)
+		}
+		return nil
+	}
+
+	store.observeTransfer(TransferTxResult{}, err)
+
+	for i := range results {
+		results[i].Transfer = Transfer{}
+		if i == failed {
+			results[i].Err = err
+		} else {
+			results[i].Err = ErrBatchRolledBack
+		}
+	}
+
+	return fmt.Errorf("%w: %v", ErrBatchFailed, err)
+}
+
+func (store *Store) executeBestEffort(ctx context.Context, results []BatchTransferResult) {
+	for i := range results {
+		if results[i].Err != nil {
+			continue
+		}
+
+		transferResult, err := store.TransferTX(ctx, toTransferTxParams(results[i].BatchTransfer))
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		results[i].Transfer = transferResult.Transfer
+	}
+}
+
+func markNotExecuted(results []BatchTransferResult) {
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = ErrNotExecuted
+		}
+	}
+}
+
+func (result *ExecuteBatchResult) count() {
+	result.Succeeded, result.Failed = 0, 0
+
+	for _, r := range result.Results {
+		if r.Err == nil {
+			result.Succeeded++
+		} else {
+			result.Failed++
+		}
+	}
+}
+
+func toTransferTxParams(transfer BatchTransfer) TransferTxParams {
+	return TransferTxParams{
+		FromAccountId: transfer.FromAccountId,
+		ToAccountId:   transfer.ToAccountId,
+		Amount:        transfer.Amount,
+		Reference:     transfer.Reference,
+	}
+}
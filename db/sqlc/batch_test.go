@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ExecuteBatchAllOrNothing(t *testing.T) {
+	store := NewStore(testDB)
+
+	employer := createRandomAccountWithCurrency(t, "EUR")
+	employee1 := createRandomAccountWithCurrency(t, "EUR")
+	employee2 := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.ExecuteBatch(context.Background(), ExecuteBatchParams{
+		Mode: BatchAllOrNothing,
+		Transfers: []BatchTransfer{
+			{FromAccountId: employer.ID, ToAccountId: employee1.ID, Amount: 100, Reference: "salary 1"},
+			{FromAccountId: employer.ID, ToAccountId: employee2.ID, Amount: 200, Reference: "salary 2"},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Succeeded)
+	require.Zero(t, result.Failed)
+
+	for _, r := range result.Results {
+		require.NoError(t, r.Err)
+		require.NotZero(t, r.Transfer.ID)
+	}
+
+	checkBalance(t, employer, -300)
+	checkBalance(t, employee1, 100)
+	checkBalance(t, employee2, 200)
+}
+
+func TestStore_ExecuteBatchAllOrNothingInvalid(t *testing.T) {
+	store := NewStore(testDB)
+
+	employer := createRandomAccountWithCurrency(t, "EUR")
+	employee1 := createRandomAccountWithCurrency(t, "EUR")
+	employee2 := createRandomAccountWithCurrency(t, "USD")
+
+	result, err := store.ExecuteBatch(context.Background(), ExecuteBatchParams{
+		Mode: BatchAllOrNothing,
+		Transfers: []BatchTransfer{
+			{FromAccountId: employer.ID, ToAccountId: employee1.ID, Amount: 100},
+			{FromAccountId: employer.ID, ToAccountId: employee2.ID, Amount: 200},
+			{FromAccountId: employer.ID, ToAccountId: employee1.ID, Amount: -5},
+		},
+	})
+	require.ErrorIs(t, err, ErrBatchInvalid)
+	require.Zero(t, result.Succeeded)
+	require.Equal(t, 3, result.Failed)
+	require.ErrorIs(t, result.Results[0].Err, ErrNotExecuted)
+	require.Contains(t, result.Results[1].Err.Error(), "currency mismatch")
+	require.Contains(t, result.Results[2].Err.Error(), "amount")
+
+	checkBalance(t, employer, 0)
+	checkBalance(t, employee1, 0)
+	checkBalance(t, employee2, 0)
+}
+
+func TestStore_ExecuteBatchBestEffort(t *testing.T) {
+	store := NewStore(testDB)
+
+	employer := createRandomAccountWithCurrency(t, "CAD")
+	employee1 := createRandomAccountWithCurrency(t, "CAD")
+	employee2 := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.ExecuteBatch(context.Background(), ExecuteBatchParams{
+		Mode: BatchBestEffort,
+		Transfers: []BatchTransfer{
+			{FromAccountId: employer.ID, ToAccountId: employee1.ID, Amount: 100},
+			{FromAccountId: employer.ID, ToAccountId: employee2.ID, Amount: 200},
+			{FromAccountId: employer.ID, ToAccountId: 0, Amount: 10},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Succeeded)
+	require.Equal(t, 2, result.Failed)
+	require.NoError(t, result.Results[0].Err)
+	require.NotZero(t, result.Results[0].Transfer.ID)
+	require.Error(t, result.Results[1].Err)
+	require.Contains(t, result.Results[2].Err.Error(), "not found")
+
+	checkBalance(t, employer, -100)
+	checkBalance(t, employee1, 100)
+	checkBalance(t, employee2, 0)
+}
+
+func TestStore_ExecuteBatchInvalidMode(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.ExecuteBatch(context.Background(), ExecuteBatchParams{Mode: "sometimes"})
+	require.ErrorIs(t, err, ErrInvalidBatchMode)
+}
@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// KYC statuses of a customer
+const (
+	KYCPending  = "pending"
+	KYCVerified = "verified"
+	KYCRejected = "rejected"
+)
+
+var (
+	ErrKYCNotVerified    = errors.New("the customer of the account is not KYC verified")
+	ErrInvalidKYCOutcome = errors.New("kyc outcome must be verified or rejected")
+	ErrNoKYCRejectReason = errors.New("a kyc rejection needs a reason")
+	ErrNoKYCReviewer     = errors.New("kyc reviewer is required")
+	ErrCustomerExists    = errors.New("a customer with this national id already exists")
+)
+
+// kycAudit is what the audit log keeps of a customer, the personal data stays out of it
+// because the log can't be edited to erase it
+type kycAudit struct {
+	KycStatus          string `json:"kyc_status"`
+	KycReviewedBy      string `json:"kyc_reviewed_by,omitempty"`
+	KycRejectionReason string `json:"kyc_rejection_reason,omitempty"`
+}
+
+func newKYCAudit(customer Customer) kycAudit {
+	return kycAudit{
+		KycStatus:          customer.KycStatus,
+		KycReviewedBy:      customer.KycReviewedBy,
+		KycRejectionReason: customer.KycRejectionReason,
+	}
+}
+
+// CreateCustomer creates a customer pending KYC and records it in the audit log
+func (store *Store) CreateCustomer(ctx context.Context, arg CreateCustomerParams) (Customer, error) {
+	var customer Customer
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		customer, err = q.CreateCustomer(ctx, arg)
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "customers_national_id_key" {
+			return ErrCustomerExists
+		}
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditCustomerCreate, AuditEntityCustomer, customer.ID, nil, newKYCAudit(customer))
+		return nil
+	})
+
+	return customer, err
+}
+
+// RecordKYCOutcomeParams contains the input parameters of RecordKYCOutcome
+type RecordKYCOutcomeParams struct {
+	CustomerID int64 `json:"customer_id"`
+	// Status is KYCVerified or KYCRejected
+	Status string `json:"status"`
+	// Reason is required to reject a customer
+	Reason   string `json:"reason"`
+	Reviewer string `json:"reviewer"`
+}
+
+// RecordKYCOutcome stores the result of the KYC review of a customer.
+// A verified customer can send money from its accounts, a rejected one can't until it's verified again.
+func (store *Store) RecordKYCOutcome(ctx context.Context, params RecordKYCOutcomeParams) (Customer, error) {
+	var customer Customer
+
+	params.Reason = strings.TrimSpace(params.Reason)
+	switch {
+	case params.Status != KYCVerified && params.Status != KYCRejected:
+		return customer, fmt.Errorf("%w: %q", ErrInvalidKYCOutcome, params.Status)
+	case params.Status == KYCRejected && params.Reason == "":
+		return customer, ErrNoKYCRejectReason
+	case params.Reviewer == "":
+		return customer, ErrNoKYCReviewer
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		before, err := q.GetCustomerForUpdate(ctx, params.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		customer, err = q.UpdateCustomerKYC(ctx, UpdateCustomerKYCParams{
+			KycStatus:          params.Status,
+			KycReviewedBy:      params.Reviewer,
+			KycRejectionReason: params.Reason,
+			ID:                 params.CustomerID,
+		})
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditCustomerKYC, AuditEntityCustomer, customer.ID, newKYCAudit(before), newKYCAudit(customer))
+		return nil
+	})
+
+	return customer, err
+}
+
+// SetAccountCustomer links an account to its customer and records the previous and new account in the audit log
+func (store *Store) SetAccountCustomer(ctx context.Context, arg SetAccountCustomerParams) (Account, error) {
+	return store.updateAudited(ctx, arg.ID, AuditAccountSetCustomer, func(q *Queries) (Account, error) {
+		return q.SetAccountCustomer(ctx, arg)
+	})
+}
+
+// WithInternalAccounts lists the internal accounts of the bank, they have no customer and can always be debited
+func WithInternalAccounts(accountIds []int64) StoreOption {
+	return func(store *Store) {
+		store.internalAccounts = make(map[int64]bool, len(accountIds))
+		for _, id := range accountIds {
+			store.internalAccounts[id] = true
+		}
+	}
+}
+
+// checkDebitKYC returns ErrKYCNotVerified when the account isn't an internal account of the bank
+// and doesn't belong to a KYC verified customer, the accounts without customer included
+func (store *Store) checkDebitKYC(q *Queries, ctx context.Context, accountId int64) error {
+	if store.internalAccounts[accountId] {
+		return nil
+	}
+
+	status, err := q.GetAccountKYCStatus(ctx, accountId)
+	if err != nil {
+		return err
+	}
+
+	if !status.Valid {
+		return fmt.Errorf("%w: account %d has no customer", ErrKYCNotVerified, accountId)
+	}
+	if status.String != KYCVerified {
+		return fmt.Errorf("%w: account %d is %s", ErrKYCNotVerified, accountId, status.String)
+	}
+	return nil
+}
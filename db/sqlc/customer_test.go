@@ -0,0 +1,152 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func createRandomCustomer(t *testing.T, store *Store) Customer {
+	customer, err := store.CreateCustomer(context.Background(), CreateCustomerParams{
+		LegalName:   util.RandomOwner(),
+		DateOfBirth: time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC),
+		Address:     util.RandomString(20),
+		NationalID:  util.RandomString(12),
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCPending, customer.KycStatus)
+	require.False(t, customer.KycVerifiedAt.Valid)
+
+	return customer
+}
+
+// withVerifiedCustomer links the account to a new KYC verified customer, so it can be debited
+func withVerifiedCustomer(t *testing.T, account Account) Account {
+	ctx := context.Background()
+	customer, err := testQueries.CreateCustomer(ctx, CreateCustomerParams{
+		LegalName:   account.Owner,
+		DateOfBirth: time.Date(1990, 1, 31, 0, 0, 0, 0, time.UTC),
+		Address:     util.RandomString(20),
+		NationalID:  util.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.UpdateCustomerKYC(ctx, UpdateCustomerKYCParams{ID: customer.ID, KycStatus: KYCVerified, KycReviewedBy: "admin"})
+	require.NoError(t, err)
+
+	account, err = testQueries.SetAccountCustomer(ctx, SetAccountCustomerParams{
+		ID:         account.ID,
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func TestStore_CreateCustomerDuplicate(t *testing.T) {
+	store := NewStore(testDB)
+	customer := createRandomCustomer(t, store)
+
+	_, err := store.CreateCustomer(context.Background(), CreateCustomerParams{
+		LegalName:   util.RandomOwner(),
+		DateOfBirth: customer.DateOfBirth,
+		Address:     customer.Address,
+		NationalID:  customer.NationalID,
+	})
+	require.ErrorIs(t, err, ErrCustomerExists)
+}
+
+func TestStore_RecordKYCOutcome(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	customer := createRandomCustomer(t, store)
+
+	_, err := store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{CustomerID: customer.ID, Status: KYCRejected, Reviewer: "admin"})
+	require.ErrorIs(t, err, ErrNoKYCRejectReason)
+
+	_, err = store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{CustomerID: customer.ID, Status: KYCPending, Reviewer: "admin"})
+	require.ErrorIs(t, err, ErrInvalidKYCOutcome)
+
+	verified, err := store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{CustomerID: customer.ID, Status: KYCVerified, Reviewer: "admin"})
+	require.NoError(t, err)
+	require.Equal(t, KYCVerified, verified.KycStatus)
+	require.Equal(t, "admin", verified.KycReviewedBy)
+	require.True(t, verified.KycReviewedAt.Valid)
+	require.True(t, verified.KycVerifiedAt.Valid)
+
+	rejected, err := store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{
+		CustomerID: customer.ID,
+		Status:     KYCRejected,
+		Reason:     "expired passport",
+		Reviewer:   "admin",
+	})
+	require.NoError(t, err)
+	require.Equal(t, KYCRejected, rejected.KycStatus)
+	require.Equal(t, "expired passport", rejected.KycRejectionReason)
+	require.False(t, rejected.KycVerifiedAt.Valid)
+
+	logs, err := store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{EntityType: AuditEntityCustomer, EntityID: customer.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 3)
+	require.NotContains(t, logs[0].After, customer.NationalID)
+}
+
+func TestStore_TransferTXRequiresKYC(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+	customer := createRandomCustomer(t, store)
+
+	_, err := store.SetAccountCustomer(ctx, SetAccountCustomerParams{
+		ID:         account1.ID,
+		CustomerID: sql.NullInt64{Int64: customer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	params := TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10}
+
+	_, err = store.TransferTX(ctx, params)
+	require.ErrorIs(t, err, ErrKYCNotVerified)
+
+	// an unverified customer can still receive money
+	_, err = store.TransferTX(ctx, TransferTxParams{FromAccountId: account2.ID, ToAccountId: account1.ID, Amount: 10})
+	require.NoError(t, err)
+
+	_, err = store.MultiTransferTX(ctx, MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: account1.ID, Amount: -10},
+		{AccountId: account2.ID, Amount: 10},
+	}})
+	require.ErrorIs(t, err, ErrKYCNotVerified)
+
+	_, err = store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{CustomerID: customer.ID, Status: KYCVerified, Reviewer: "admin"})
+	require.NoError(t, err)
+
+	_, err = store.TransferTX(ctx, params)
+	require.NoError(t, err)
+}
+
+func TestStore_TransferTXWithoutCustomer(t *testing.T) {
+	ctx := context.Background()
+	internal, err := testQueries.CreateAccount(ctx, CreateAccountParams{Owner: util.RandomOwner(), Balance: 100, Currency: "USD"})
+	require.NoError(t, err)
+	receiver := createRandomAccountWithCurrency(t, "USD")
+
+	params := TransferTxParams{FromAccountId: internal.ID, ToAccountId: receiver.ID, Amount: 10}
+
+	// an account without customer is only debited when it's an internal account of the bank
+	_, err = NewStore(testDB).TransferTX(ctx, params)
+	require.ErrorIs(t, err, ErrKYCNotVerified)
+
+	_, err = NewStore(testDB).MultiTransferTX(ctx, MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: internal.ID, Amount: -10},
+		{AccountId: receiver.ID, Amount: 10},
+	}})
+	require.ErrorIs(t, err, ErrKYCNotVerified)
+
+	_, err = NewStore(testDB, WithInternalAccounts([]int64{internal.ID})).TransferTX(ctx, params)
+	require.NoError(t, err)
+}
@@ -7,36 +7,49 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
 const createEntry = `-- name: CreateEntry :one
 INSERT INTO entries (
     account_id,
-    amount
+    amount,
+    transfer_id,
+    multi_transfer_id
 ) VALUES (
-             $1, $2
-         ) RETURNING id, account_id, amount, created_at
+             $1, $2, $3, $4
+         ) RETURNING id, account_id, amount, created_at, transfer_id, multi_transfer_id
 `
 
 type CreateEntryParams struct {
-	AccountID int64 `json:"account_id"`
-	Amount    int64 `json:"amount"`
+	AccountID       int64         `json:"account_id"`
+	Amount          int64         `json:"amount"`
+	TransferID      sql.NullInt64 `json:"transfer_id"`
+	MultiTransferID sql.NullInt64 `json:"multi_transfer_id"`
 }
 
 func (q *Queries) CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error) {
-	row := q.db.QueryRowContext(ctx, createEntry, arg.AccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createEntry,
+		arg.AccountID,
+		arg.Amount,
+		arg.TransferID,
+		arg.MultiTransferID,
+	)
 	var i Entry
 	err := row.Scan(
 		&i.ID,
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.MultiTransferID,
 	)
 	return i, err
 }
 
 const getEntry = `-- name: GetEntry :one
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, multi_transfer_id FROM entries
 WHERE id = $1 LIMIT 1
 `
 
@@ -48,12 +61,14 @@ func (q *Queries) GetEntry(ctx context.Context, id int64) (Entry, error) {
 		&i.AccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.TransferID,
+		&i.MultiTransferID,
 	)
 	return i, err
 }
 
 const listEntries = `-- name: ListEntries :many
-SELECT id, account_id, amount, created_at FROM entries
+SELECT id, account_id, amount, created_at, transfer_id, multi_transfer_id FROM entries
 WHERE account_id = $1
 ORDER BY id
     LIMIT $2
@@ -80,6 +95,8 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 			&i.AccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.TransferID,
+			&i.MultiTransferID,
 		); err != nil {
 			return nil, err
 		}
@@ -93,3 +110,184 @@ func (q *Queries) ListEntries(ctx context.Context, arg ListEntriesParams) ([]Ent
 	}
 	return items, nil
 }
+
+const listEntriesAsc = `-- name: ListEntriesAsc :many
+SELECT id, account_id, amount, created_at, transfer_id, multi_transfer_id FROM entries
+WHERE account_id = $1
+    AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListEntriesAscParams struct {
+	AccountID       int64     `json:"account_id"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+// Keyset pagination of the entries of an account, resuming after the cursor
+func (q *Queries) ListEntriesAsc(ctx context.Context, arg ListEntriesAscParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesAsc,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.MultiTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listEntriesDesc = `-- name: ListEntriesDesc :many
+SELECT id, account_id, amount, created_at, transfer_id, multi_transfer_id FROM entries
+WHERE account_id = $1
+    AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListEntriesDescParams struct {
+	AccountID       int64     `json:"account_id"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+func (q *Queries) ListEntriesDesc(ctx context.Context, arg ListEntriesDescParams) ([]Entry, error) {
+	rows, err := q.db.QueryContext(ctx, listEntriesDesc,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.MultiTransferID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listStatementLines = `-- name: ListStatementLines :many
+SELECT e.id,
+    e.amount,
+    e.created_at,
+    e.transfer_id,
+    COALESCE(t.reference, '')::varchar AS reference,
+    COALESCE(t.description, '')::varchar AS description,
+    COALESCE(CASE WHEN t.from_account_id = e.account_id THEN t.to_account_id ELSE t.from_account_id END, 0)::bigint AS counterparty_id
+FROM entries e
+LEFT JOIN transfers t ON t.id = e.transfer_id
+WHERE e.account_id = $1
+    AND e.created_at >= $2
+    AND e.created_at < $3
+ORDER BY e.created_at, e.id
+`
+
+type ListStatementLinesParams struct {
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+type ListStatementLinesRow struct {
+	ID             int64         `json:"id"`
+	Amount         int64         `json:"amount"`
+	CreatedAt      time.Time     `json:"created_at"`
+	TransferID     sql.NullInt64 `json:"transfer_id"`
+	Reference      string        `json:"reference"`
+	Description    string        `json:"description"`
+	CounterpartyID int64         `json:"counterparty_id"`
+}
+
+func (q *Queries) ListStatementLines(ctx context.Context, arg ListStatementLinesParams) ([]ListStatementLinesRow, error) {
+	rows, err := q.db.QueryContext(ctx, listStatementLines, arg.AccountID, arg.PeriodStart, arg.PeriodEnd)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListStatementLinesRow
+	for rows.Next() {
+		var i ListStatementLinesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.Reference,
+			&i.Description,
+			&i.CounterpartyID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const sumEntriesSince = `-- name: SumEntriesSince :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS total FROM entries
+WHERE account_id = $1 AND created_at >= $2
+`
+
+type SumEntriesSinceParams struct {
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (q *Queries) SumEntriesSince(ctx context.Context, arg SumEntriesSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, sumEntriesSince, arg.AccountID, arg.CreatedAt)
+	var total int64
+	err := row.Scan(&total)
+	return total, err
+}
@@ -0,0 +1,183 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var ErrNoRevenueAccount = errors.New("no revenue account for the fee currency")
+
+// FeeRule describes how the fee of a transfer is computed for one currency.
+// The fee is Flat plus BasisPoints of the amount (1 basis point is 0.01%),
+// raised to Min and capped to Max when they are set.
+type FeeRule struct {
+	Flat        int64 `json:"flat"`
+	BasisPoints int64 `json:"basis_points"`
+	Min         int64 `json:"min"`
+	// Max is ignored when it's zero
+	Max int64 `json:"max"`
+}
+
+// FeeSchedule contains the fee rule and the bank revenue account of every currency that has a fee
+type FeeSchedule struct {
+	Rules map[string]FeeRule `json:"rules"`
+	// RevenueAccounts receives the fees, by currency
+	RevenueAccounts map[string]int64 `json:"revenue_accounts"`
+}
+
+// WithFeeSchedule makes the store charge fees on transfers according to the schedule
+func WithFeeSchedule(schedule FeeSchedule) StoreOption {
+	return func(store *Store) {
+		store.fees = schedule
+	}
+}
+
+// ParseFeeRules parses a comma separated list of CURRENCY:FLAT:BASIS_POINTS[:MIN[:MAX]], in minor units,
+// like EUR:25:50:100:1000 for 0.25 plus 0.5% with a fee between 1.00 and 10.00
+func ParseFeeRules(value string) (map[string]FeeRule, error) {
+	rules := make(map[string]FeeRule)
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		fields := strings.Split(item, ":")
+		if len(fields) < 3 || len(fields) > 5 {
+			return nil, fmt.Errorf("invalid fee rule %q: expected CURRENCY:FLAT:BASIS_POINTS[:MIN[:MAX]]", item)
+		}
+
+		var numbers [4]int64
+		for i, field := range fields[1:] {
+			number, err := strconv.ParseInt(strings.TrimSpace(field), 10, 64)
+			if err != nil || number < 0 {
+				return nil, fmt.Errorf("invalid fee rule %q: %q is not a non-negative integer", item, field)
+			}
+			numbers[i] = number
+		}
+
+		rules[strings.TrimSpace(fields[0])] = FeeRule{Flat: numbers[0], BasisPoints: numbers[1], Min: numbers[2], Max: numbers[3]}
+	}
+	return rules, nil
+}
+
+// NewFeeSchedule returns the schedule of the rules, parsed by ParseFeeRules, with the revenue accounts of their currencies
+func NewFeeSchedule(rules string, revenueAccounts map[string]int64) (FeeSchedule, error) {
+	parsed, err := ParseFeeRules(rules)
+	if err != nil {
+		return FeeSchedule{}, err
+	}
+
+	schedule := FeeSchedule{Rules: parsed, RevenueAccounts: revenueAccounts}
+	return schedule, schedule.Validate()
+}
+
+// Validate checks every currency with a rule has a revenue account
+func (schedule FeeSchedule) Validate() error {
+	for currency := range schedule.Rules {
+		if _, ok := schedule.RevenueAccounts[currency]; !ok {
+			return fmt.Errorf("%w: %s", ErrNoRevenueAccount, currency)
+		}
+	}
+	return nil
+}
+
+// Fee returns the fee of a transfer of amount in currency, zero when the currency has no rule
+func (schedule FeeSchedule) Fee(currency string, amount int64) int64 {
+	rule, ok := schedule.Rules[currency]
+	if !ok {
+		return 0
+	}
+
+	return rule.Fee(amount)
+}
+
+// Fee returns the fee of a transfer of amount, the percentage part is rounded half up
+func (rule FeeRule) Fee(amount int64) int64 {
+	// split the amount to avoid overflowing amount * BasisPoints
+	percentage := amount/10000*rule.BasisPoints + (amount%10000*rule.BasisPoints+5000)/10000
+
+	fee := rule.Flat + percentage
+	if fee < rule.Min {
+		fee = rule.Min
+	}
+	if rule.Max > 0 && fee > rule.Max {
+		fee = rule.Max
+	}
+
+	return fee
+}
+
+// transferFee returns the fee of the transfer and the revenue account that receives it
+func (store *Store) transferFee(
+	q *Queries,
+	ctx context.Context,
+	params TransferTxParams,
+) (fee int64, revenueAccountId int64, err error) {
+	if len(store.fees.Rules) == 0 {
+		return 0, 0, nil
+	}
+
+	fromAccount, err := q.GetAccount(ctx, params.FromAccountId)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	fee = store.fees.Fee(fromAccount.Currency, params.Amount)
+	if fee == 0 {
+		return 0, 0, nil
+	}
+
+	revenueAccountId, ok := store.fees.RevenueAccounts[fromAccount.Currency]
+	if !ok {
+		return 0, 0, fmt.Errorf("%w: %s", ErrNoRevenueAccount, fromAccount.Currency)
+	}
+
+	return fee, revenueAccountId, nil
+}
+
+// createFeeEntries debits the fee from the sender and credits it to the revenue account
+func createFeeEntries(
+	q *Queries,
+	ctx context.Context,
+	params TransferTxParams,
+	revenueAccountId int64,
+	result *TransferTxResult,
+) (err error) {
+	result.FeeEntry, err = createTransferEntry(q, ctx, params.FromAccountId, -result.Fee, result.Transfer.ID)
+	if err != nil {
+		return
+	}
+
+	result.RevenueEntry, err = createTransferEntry(q, ctx, revenueAccountId, result.Fee, result.Transfer.ID)
+	return
+}
+
+// updateBalancesWithFee updates the sender, receiver and revenue accounts in ascending ID order,
+// so it can't deadlock with other transfers touching the same accounts
+func updateBalancesWithFee(
+	q *Queries,
+	params TransferTxParams,
+	revenueAccountId int64,
+	result *TransferTxResult,
+	ctx context.Context,
+) error {
+	legs := []TransferLeg{
+		{AccountId: params.FromAccountId, Amount: -params.Amount - result.Fee},
+		{AccountId: params.ToAccountId, Amount: params.Amount},
+		{AccountId: revenueAccountId, Amount: result.Fee},
+	}
+
+	accounts, err := updateLegsBalance(q, ctx, legs)
+	if err != nil {
+		return err
+	}
+
+	result.FromAccount = accounts[params.FromAccountId]
+	result.ToAccount = accounts[params.ToAccountId]
+	result.RevenueAccount = accounts[revenueAccountId]
+	return nil
+}
@@ -0,0 +1,137 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFeeRule_Fee(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rule   FeeRule
+		amount int64
+		fee    int64
+	}{
+		{name: "Flat", rule: FeeRule{Flat: 25}, amount: 1000, fee: 25},
+		{name: "Percentage", rule: FeeRule{BasisPoints: 150}, amount: 1000, fee: 15},
+		{name: "RoundHalfUp", rule: FeeRule{BasisPoints: 150}, amount: 1010, fee: 15},
+		{name: "FlatAndPercentage", rule: FeeRule{Flat: 10, BasisPoints: 100}, amount: 5000, fee: 60},
+		{name: "Min", rule: FeeRule{BasisPoints: 100, Min: 30}, amount: 1000, fee: 30},
+		{name: "Max", rule: FeeRule{BasisPoints: 100, Max: 500}, amount: 1000000, fee: 500},
+		{name: "NoMax", rule: FeeRule{BasisPoints: 100}, amount: 1000000, fee: 10000},
+		{name: "LargeAmount", rule: FeeRule{BasisPoints: 10000}, amount: 1 << 60, fee: 1 << 60},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, tc.rule.Fee(tc.amount))
+		})
+	}
+}
+
+func TestFeeSchedule_Fee(t *testing.T) {
+	schedule := FeeSchedule{
+		Rules: map[string]FeeRule{"EUR": {Flat: 5}},
+	}
+
+	require.Equal(t, int64(5), schedule.Fee("EUR", 100))
+	require.Zero(t, schedule.Fee("USD", 100))
+}
+
+func TestParseFeeRules(t *testing.T) {
+	rules, err := ParseFeeRules("EUR:25:50:100:1000, USD:0:30,GBP:10:0:20")
+	require.NoError(t, err)
+	require.Equal(t, map[string]FeeRule{
+		"EUR": {Flat: 25, BasisPoints: 50, Min: 100, Max: 1000},
+		"USD": {BasisPoints: 30},
+		"GBP": {Flat: 10, Min: 20},
+	}, rules)
+
+	rules, err = ParseFeeRules("")
+	require.NoError(t, err)
+	require.Empty(t, rules)
+
+	for _, value := range []string{"EUR", "EUR:25", "EUR:1:2:3:4:5", "EUR:x:50", "EUR:-1:50"} {
+		_, err = ParseFeeRules(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestFeeSchedule_Validate(t *testing.T) {
+	schedule := FeeSchedule{
+		Rules:           map[string]FeeRule{"EUR": {Flat: 5}, "USD": {Flat: 5}},
+		RevenueAccounts: map[string]int64{"EUR": 1},
+	}
+	require.ErrorIs(t, schedule.Validate(), ErrNoRevenueAccount)
+
+	schedule.RevenueAccounts["USD"] = 2
+	require.NoError(t, schedule.Validate())
+}
+
+func TestStore_TransferTXWithFee(t *testing.T) {
+	revenue := createRandomAccountWithCurrency(t, "EUR")
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	store := NewStore(testDB, WithFeeSchedule(FeeSchedule{
+		Rules:           map[string]FeeRule{"EUR": {Flat: 2, BasisPoints: 100, Max: 50}},
+		RevenueAccounts: map[string]int64{"EUR": revenue.ID},
+	}))
+
+	result, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        300,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(5), result.Fee)
+	require.Equal(t, int64(5), result.Transfer.Fee)
+	require.Equal(t, int64(-300), result.FromEntry.Amount)
+	require.Equal(t, int64(300), result.ToEntry.Amount)
+	require.Equal(t, account1.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-5), result.FeeEntry.Amount)
+	require.Equal(t, revenue.ID, result.RevenueEntry.AccountID)
+	require.Equal(t, int64(5), result.RevenueEntry.Amount)
+
+	require.Equal(t, account1.Balance-305, result.FromAccount.Balance)
+	require.Equal(t, account2.Balance+300, result.ToAccount.Balance)
+	require.Equal(t, revenue.Balance+5, result.RevenueAccount.Balance)
+
+	checkBalance(t, account1, -305)
+	checkBalance(t, account2, 300)
+	checkBalance(t, revenue, 5)
+}
+
+func TestStore_TransferTXWithoutRevenueAccount(t *testing.T) {
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	store := NewStore(testDB, WithFeeSchedule(FeeSchedule{
+		Rules: map[string]FeeRule{"USD": {Flat: 1}},
+	}))
+
+	_, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrNoRevenueAccount)
+
+	checkBalance(t, account1, 0)
+	checkBalance(t, account2, 0)
+}
+
+func TestNewFeeSchedule(t *testing.T) {
+	schedule, err := NewFeeSchedule("EUR:25:50", map[string]int64{"EUR": 3})
+	require.NoError(t, err)
+	require.Equal(t, FeeSchedule{Rules: map[string]FeeRule{"EUR": {Flat: 25, BasisPoints: 50}}, RevenueAccounts: map[string]int64{"EUR": 3}}, schedule)
+
+	_, err = NewFeeSchedule("EUR:25:50", nil)
+	require.ErrorIs(t, err, ErrNoRevenueAccount)
+
+	_, err = NewFeeSchedule("EUR", nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,60 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrAccountFrozen    = errors.New("the account is frozen")
+	ErrAccountNotFrozen = errors.New("the account is not frozen")
+)
+
+// FreezeAccount freezes the account: no transfer, request or conversion moves money in or out of it until it's unfrozen.
+// The balance adjustments, the interest postings and the reversals made by the bank are still booked on it.
+func (store *Store) FreezeAccount(ctx context.Context, accountId int64) (Account, error) {
+	return store.updateAudited(ctx, accountId, AuditAccountFreeze, func(q *Queries) (Account, error) {
+		return setAccountFrozen(q, ctx, accountId, true)
+	})
+}
+
+// UnfreezeAccount lifts the freeze of the account
+func (store *Store) UnfreezeAccount(ctx context.Context, accountId int64) (Account, error) {
+	return store.updateAudited(ctx, accountId, AuditAccountUnfreeze, func(q *Queries) (Account, error) {
+		return setAccountFrozen(q, ctx, accountId, false)
+	})
+}
+
+// setAccountFrozen sets or clears the freeze time of an account locked by the transaction
+func setAccountFrozen(q *Queries, ctx context.Context, accountId int64, frozen bool) (Account, error) {
+	account, err := q.GetAccount(ctx, accountId)
+	if err != nil {
+		return account, err
+	}
+
+	switch {
+	case frozen && account.FrozenAt.Valid:
+		return account, fmt.Errorf("%w: %d", ErrAccountFrozen, accountId)
+	case !frozen && !account.FrozenAt.Valid:
+		return account, fmt.Errorf("%w: %d", ErrAccountNotFrozen, accountId)
+	}
+
+	return q.SetAccountFrozenAt(ctx, SetAccountFrozenAtParams{
+		ID:       accountId,
+		FrozenAt: sql.NullTime{Time: time.Now(), Valid: frozen},
+	})
+}
+
+// checkNotFrozen returns ErrAccountFrozen for the first frozen account.
+// The accounts must be locked by the transaction, so they can't be frozen before it commits.
+func checkNotFrozen(accounts ...Account) error {
+	for _, account := range accounts {
+		if account.FrozenAt.Valid {
+			return fmt.Errorf("%w: %d", ErrAccountFrozen, account.ID)
+		}
+	}
+	return nil
+}
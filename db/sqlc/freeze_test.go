@@ -0,0 +1,62 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_FreezeAccount(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	frozen, err := store.FreezeAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.True(t, frozen.FrozenAt.Valid)
+	require.Equal(t, account1.Balance, frozen.Balance)
+
+	_, err = store.FreezeAccount(ctx, account1.ID)
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTX(ctx, TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.TransferTX(ctx, TransferTxParams{FromAccountId: account2.ID, ToAccountId: account1.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	_, err = store.MultiTransferTX(ctx, MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: account2.ID, Amount: -10},
+		{AccountId: account1.ID, Amount: 10},
+	}})
+	require.ErrorIs(t, err, ErrAccountFrozen)
+
+	unchanged, err := store.GetAccount(ctx, account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, unchanged.Balance)
+
+	unfrozen, err := store.UnfreezeAccount(ctx, account1.ID)
+	require.NoError(t, err)
+	require.False(t, unfrozen.FrozenAt.Valid)
+
+	_, err = store.UnfreezeAccount(ctx, account1.ID)
+	require.ErrorIs(t, err, ErrAccountNotFrozen)
+
+	_, err = store.TransferTX(ctx, TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	require.NoError(t, err)
+
+	logs, err := store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{EntityType: AuditEntityAccount, EntityID: account1.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, AuditAccountFreeze, logs[0].Action)
+	require.Equal(t, AuditAccountUnfreeze, logs[1].Action)
+}
+
+func TestStore_FreezeAccountNotFound(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.FreezeAccount(context.Background(), -1)
+	require.Error(t, err)
+}
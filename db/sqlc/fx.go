@@ -0,0 +1,233 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"simple_bank/money"
+)
+
+// Sources of the FX rates
+const (
+	FXSourceManual = "manual"
+	FXSourceCSV    = "csv"
+	FXSourceECB    = "ecb"
+)
+
+// How an FX quote is derived from the stored rates
+const (
+	FXIdentity = "identity"
+	FXDirect   = "direct"
+	FXInverse  = "inverse"
+	FXCross    = "cross"
+)
+
+const (
+	// DefaultFXBaseCurrency is the currency the cross rates are derived through, the ECB publishes its rates against it
+	DefaultFXBaseCurrency = "EUR"
+	// DefaultFXMaxAge covers a weekend and a bank holiday without new rates from a daily feed
+	DefaultFXMaxAge = 96 * time.Hour
+)
+
+var (
+	ErrNoFXRate         = errors.New("no FX rate between the currencies")
+	ErrSameFXCurrencies = errors.New("an FX rate needs two different currencies")
+	ErrStaleFXRate      = errors.New("the FX rate is too old")
+	ErrInvalidFXSource  = errors.New("FX rate source must be manual, csv or ecb")
+)
+
+// WithFXBaseCurrency sets the currency the cross rates are derived through
+func WithFXBaseCurrency(currency string) StoreOption {
+	return func(store *Store) {
+		store.fxBaseCurrency = currency
+	}
+}
+
+// WithFXMaxAge sets how old the rates of a quote can be at the time of the quote, zero accepts any age
+func WithFXMaxAge(maxAge time.Duration) StoreOption {
+	return func(store *Store) {
+		store.fxMaxAge = maxAge
+	}
+}
+
+// FXQuote is the rate between two currencies at a given time
+type FXQuote struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+	// Rate is the units of To for one unit of From, rounded to 10 decimals
+	Rate string `json:"rate"`
+	// Derivation is FXIdentity, FXDirect, FXInverse or FXCross
+	Derivation string `json:"derivation"`
+	// EffectiveFrom is the time of the oldest stored rate the quote is derived from
+	EffectiveFrom time.Time `json:"effective_from"`
+	// Rates are the stored rates the quote is derived from
+	Rates []FxRate `json:"rates"`
+
+	// rate is the exact rate, Rate is its rounded decimal
+	rate *big.Rat
+}
+
+// AddFXRate stores the rate of a currency pair effective from arg.EffectiveFrom, or from now when it's zero.
+// The source defaults to FXSourceManual.
+func (store *Store) AddFXRate(ctx context.Context, arg CreateFXRateParams) (FxRate, error) {
+	arg, err := validateFXRate(arg, time.Now())
+	if err != nil {
+		return FxRate{}, err
+	}
+
+	return store.CreateFXRate(ctx, arg)
+}
+
+// ImportFXRates stores the rates of a feed within a single database transaction,
+// nothing is stored when one of them is invalid. A rate already imported is replaced.
+func (store *Store) ImportFXRates(ctx context.Context, rates []CreateFXRateParams) (int, error) {
+	now := time.Now()
+	validated := make([]CreateFXRateParams, len(rates))
+	for i, rate := range rates {
+		var err error
+		validated[i], err = validateFXRate(rate, now)
+		if err != nil {
+			return 0, fmt.Errorf("rate %d: %w", i+1, err)
+		}
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		for _, rate := range validated {
+			if _, err := q.CreateFXRate(ctx, rate); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(rates), nil
+}
+
+func validateFXRate(arg CreateFXRateParams, now time.Time) (CreateFXRateParams, error) {
+	if arg.BaseCurrency == arg.QuoteCurrency {
+		return arg, ErrSameFXCurrencies
+	}
+	for _, currency := range []string{arg.BaseCurrency, arg.QuoteCurrency} {
+		if _, err := money.LookupCurrency(currency); err != nil {
+			return arg, err
+		}
+	}
+
+	if _, err := money.ParseRate(arg.Rate); err != nil {
+		return arg, err
+	}
+
+	switch arg.Source {
+	case "":
+		arg.Source = FXSourceManual
+	case FXSourceManual, FXSourceCSV, FXSourceECB:
+	default:
+		return arg, fmt.Errorf("%w: %q", ErrInvalidFXSource, arg.Source)
+	}
+
+	if arg.EffectiveFrom.IsZero() {
+		arg.EffectiveFrom = now
+	}
+	return arg, nil
+}
+
+// FXRate returns the rate of from in to valid at the given time. It's the latest rate of the pair effective at that time,
+// the inverse of the rate of the opposite pair when it's more recent, or else a cross rate through the base currency.
+// ErrStaleFXRate is returned when a stored rate the quote is derived from is older than the max age at that time.
+func (store *Store) FXRate(ctx context.Context, from, to string, at time.Time) (FXQuote, error) {
+	return store.fxQuote(store.Queries, ctx, from, to, at)
+}
+
+func (store *Store) fxQuote(q *Queries, ctx context.Context, from, to string, at time.Time) (FXQuote, error) {
+	quote := FXQuote{From: from, To: to, At: at, Rates: []FxRate{}}
+
+	for _, currency := range []string{from, to} {
+		if _, err := money.LookupCurrency(currency); err != nil {
+			return quote, err
+		}
+	}
+
+	if from == to {
+		quote.rate, quote.Derivation, quote.EffectiveFrom = big.NewRat(1, 1), FXIdentity, at
+		quote.Rate = money.FormatRate(quote.rate)
+		return quote, nil
+	}
+
+	rate, stored, derivation, err := pairRate(q, ctx, from, to, at)
+	if err == nil {
+		quote.rate, quote.Derivation, quote.Rates = rate, derivation, []FxRate{stored}
+	}
+
+	base := store.fxBaseCurrency
+	if errors.Is(err, ErrNoFXRate) && from != base && to != base {
+		fromBase, fromStored, _, fromErr := pairRate(q, ctx, from, base, at)
+		baseTo, toStored, _, toErr := pairRate(q, ctx, base, to, at)
+
+		switch {
+		case fromErr != nil && !errors.Is(fromErr, ErrNoFXRate):
+			err = fromErr
+		case toErr != nil && !errors.Is(toErr, ErrNoFXRate):
+			err = toErr
+		case fromErr == nil && toErr == nil:
+			err = nil
+			quote.rate, quote.Derivation = new(big.Rat).Mul(fromBase, baseTo), FXCross
+			quote.Rates = []FxRate{fromStored, toStored}
+		}
+	}
+	if errors.Is(err, ErrNoFXRate) {
+		return quote, fmt.Errorf("%w: %s to %s at %s", ErrNoFXRate, from, to, at.Format(time.RFC3339))
+	}
+	if err != nil {
+		return quote, err
+	}
+
+	quote.Rate = money.FormatRate(quote.rate)
+	quote.EffectiveFrom = quote.Rates[0].EffectiveFrom
+	for _, stored := range quote.Rates[1:] {
+		if stored.EffectiveFrom.Before(quote.EffectiveFrom) {
+			quote.EffectiveFrom = stored.EffectiveFrom
+		}
+	}
+
+	if store.fxMaxAge > 0 && at.Sub(quote.EffectiveFrom) > store.fxMaxAge {
+		return quote, fmt.Errorf("%w: %s to %s is effective from %s", ErrStaleFXRate, from, to, quote.EffectiveFrom.Format(time.RFC3339))
+	}
+	return quote, nil
+}
+
+// pairRate returns the rate of from in to derived from the latest stored rate of the pair or of the opposite pair
+func pairRate(q *Queries, ctx context.Context, from, to string, at time.Time) (*big.Rat, FxRate, string, error) {
+	direct, err := q.GetFXRateAt(ctx, GetFXRateAtParams{BaseCurrency: from, QuoteCurrency: to, EffectiveFrom: at})
+	hasDirect := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, direct, "", err
+	}
+
+	inverse, err := q.GetFXRateAt(ctx, GetFXRateAtParams{BaseCurrency: to, QuoteCurrency: from, EffectiveFrom: at})
+	hasInverse := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, inverse, "", err
+	}
+
+	switch {
+	case hasDirect && (!hasInverse || !direct.EffectiveFrom.Before(inverse.EffectiveFrom)):
+		rate, err := money.ParseRate(direct.Rate)
+		return rate, direct, FXDirect, err
+	case hasInverse:
+		rate, err := money.ParseRate(inverse.Rate)
+		if err != nil {
+			return nil, inverse, "", err
+		}
+		return rate.Inv(rate), inverse, FXInverse, nil
+	default:
+		return nil, FxRate{}, "", ErrNoFXRate
+	}
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_FXRate(t *testing.T) {
+	store := NewStore(testDB, WithFXBaseCurrency("NOK"), WithFXMaxAge(48*time.Hour))
+	ctx := context.Background()
+
+	day1 := time.Date(2001, 3, 1, 0, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	imported, err := store.ImportFXRates(ctx, []CreateFXRateParams{
+		{BaseCurrency: "NOK", QuoteCurrency: "DKK", Rate: "0.8", EffectiveFrom: day1, Source: FXSourceCSV},
+		{BaseCurrency: "NOK", QuoteCurrency: "DKK", Rate: "0.75", EffectiveFrom: day2, Source: FXSourceCSV},
+		{BaseCurrency: "PLN", QuoteCurrency: "NOK", Rate: "2.5", EffectiveFrom: day1, Source: FXSourceCSV},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, imported)
+
+	testCases := []struct {
+		name       string
+		from       string
+		to         string
+		at         time.Time
+		rate       string
+		derivation string
+		err        error
+	}{
+		{name: "Direct", from: "NOK", to: "DKK", at: day1.Add(time.Hour), rate: "0.8", derivation: FXDirect},
+		{name: "Latest", from: "NOK", to: "DKK", at: day2.Add(time.Hour), rate: "0.75", derivation: FXDirect},
+		{name: "Inverse", from: "DKK", to: "NOK", at: day2, rate: "1.3333333333", derivation: FXInverse},
+		{name: "Cross", from: "PLN", to: "DKK", at: day2, rate: "1.875", derivation: FXCross},
+		{name: "Identity", from: "DKK", to: "DKK", at: day1, rate: "1", derivation: FXIdentity},
+		{name: "BeforeFirstRate", from: "NOK", to: "DKK", at: day1.Add(-time.Second), err: ErrNoFXRate},
+		{name: "Stale", from: "PLN", to: "NOK", at: day1.AddDate(0, 0, 3), err: ErrStaleFXRate},
+		{name: "NoRate", from: "PLN", to: "HUF", at: day2, err: ErrNoFXRate},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			quote, err := store.FXRate(ctx, tc.from, tc.to, tc.at)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.rate, quote.Rate)
+			require.Equal(t, tc.derivation, quote.Derivation)
+		})
+	}
+
+	quote, err := store.FXRate(ctx, "PLN", "DKK", day2)
+	require.NoError(t, err)
+	require.Len(t, quote.Rates, 2)
+	require.True(t, quote.EffectiveFrom.Equal(day1))
+}
+
+func TestStore_ImportFXRatesInvalid(t *testing.T) {
+	store := NewStore(testDB)
+
+	_, err := store.ImportFXRates(context.Background(), []CreateFXRateParams{
+		{BaseCurrency: "NOK", QuoteCurrency: "DKK", Rate: "0.8"},
+		{BaseCurrency: "NOK", QuoteCurrency: "NOK", Rate: "1"},
+	})
+	require.ErrorIs(t, err, ErrSameFXCurrencies)
+
+	_, err = store.AddFXRate(context.Background(), CreateFXRateParams{BaseCurrency: "NOK", QuoteCurrency: "DKK", Rate: "0.8", Source: "bloomberg"})
+	require.ErrorIs(t, err, ErrInvalidFXSource)
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// SchemaVersion is the version of the last migration of db/migration, the database must be at it to serve requests.
+// It must be bumped with every new migration.
+const SchemaVersion = 20
+
+var (
+	ErrSchemaDirty    = errors.New("the last database migration failed, the schema is dirty")
+	ErrSchemaVersion  = errors.New("the database schema is not at the expected version")
+	ErrNoSchemaLedger = errors.New("the database has no migration applied")
+	ErrStoreClosed    = errors.New("the store is closed")
+)
+
+// CheckReady pings the database and checks its migrations are at SchemaVersion
+func (store *Store) CheckReady(ctx context.Context) error {
+	if err := store.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	version, dirty, err := store.schemaVersion(ctx)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case dirty:
+		return fmt.Errorf("%w at version %d", ErrSchemaDirty, version)
+	case version != SchemaVersion:
+		return fmt.Errorf("%w: %d, expected %d", ErrSchemaVersion, version, SchemaVersion)
+	default:
+		return nil
+	}
+}
+
+// schemaVersion reads the version golang-migrate recorded in schema_migrations
+func (store *Store) schemaVersion(ctx context.Context) (version int64, dirty bool, err error) {
+	err = store.db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, ErrNoSchemaLedger
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("read schema version: %w", err)
+	}
+	return version, dirty, nil
+}
+
+// enterTx counts a transaction starting, it fails once the store is closed
+func (store *Store) enterTx() error {
+	store.lifecycle.Lock()
+	defer store.lifecycle.Unlock()
+
+	if store.drained != nil {
+		return ErrStoreClosed
+	}
+	store.runningTxs++
+	return nil
+}
+
+// leaveTx counts a transaction over, the last one of a closed store lets Close go on
+func (store *Store) leaveTx() {
+	store.lifecycle.Lock()
+	defer store.lifecycle.Unlock()
+
+	store.runningTxs--
+	if store.drained != nil && store.runningTxs == 0 {
+		close(store.drained)
+	}
+}
+
+// Close refuses the new transactions, waits for the running ones, like the transfers in flight,
+// and closes the database. When the context ends first, the database is closed anyway and the context error returned.
+func (store *Store) Close(ctx context.Context) error {
+	store.lifecycle.Lock()
+	if store.drained != nil {
+		store.lifecycle.Unlock()
+		return ErrStoreClosed
+	}
+	store.drained = make(chan struct{})
+	if store.runningTxs == 0 {
+		close(store.drained)
+	}
+	store.lifecycle.Unlock()
+
+	var err error
+	select {
+	case <-store.drained:
+	case <-ctx.Done():
+		err = fmt.Errorf("transactions still running: %w", ctx.Err())
+	}
+
+	return errors.Join(err, store.db.Close())
+}
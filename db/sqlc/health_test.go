@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_CheckReady(t *testing.T) {
+	store := NewStore(testDB)
+	require.NoError(t, store.CheckReady(context.Background()))
+}
+
+func TestSchemaVersionIsLastMigration(t *testing.T) {
+	files, err := os.ReadDir("../migration")
+	require.NoError(t, err)
+
+	var last int64
+	for _, file := range files {
+		number, _, _ := strings.Cut(file.Name(), "_")
+		version, err := strconv.ParseInt(number, 10, 64)
+		require.NoError(t, err, file.Name())
+		last = max(last, version)
+	}
+	require.Equal(t, int64(SchemaVersion), last, "bump db.SchemaVersion with the migration")
+}
+
+func TestStoreCloseWaitsForTransactions(t *testing.T) {
+	conn, err := sql.Open(dbDriver, dbSource)
+	require.NoError(t, err)
+	store := NewStore(conn)
+
+	require.NoError(t, store.enterTx())
+
+	closed := make(chan error)
+	go func() {
+		closed <- store.Close(context.Background())
+	}()
+
+	select {
+	case <-closed:
+		t.Fatal("Close returned with a running transaction")
+	case <-time.After(20 * time.Millisecond):
+	}
+	require.Eventually(t, func() bool {
+		return store.enterTx() == ErrStoreClosed
+	}, time.Second, time.Millisecond)
+
+	store.leaveTx()
+	require.NoError(t, <-closed)
+
+	err = store.execTx(context.Background(), func(q *Queries) error { return nil })
+	require.ErrorIs(t, err, ErrStoreClosed)
+	require.ErrorIs(t, store.Close(context.Background()), ErrStoreClosed)
+}
+
+func TestStoreCloseTimeout(t *testing.T) {
+	conn, err := sql.Open(dbDriver, dbSource)
+	require.NoError(t, err)
+	store := NewStore(conn)
+	require.NoError(t, store.enterTx())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, store.Close(ctx), context.DeadlineExceeded)
+}
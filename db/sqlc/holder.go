@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+)
+
+// Roles of the holders of an account.
+// The owner is accounts.owner, the other roles are account_holders rows.
+const (
+	RoleOwner     = "owner"
+	RoleCoOwner   = "co_owner"
+	RoleViewer    = "viewer"
+	RoleSignatory = "signatory"
+)
+
+var (
+	ErrNotAccountHolder      = errors.New("the user doesn't hold the account")
+	ErrViewerCannotTransfer  = errors.New("a viewer can't transfer from the account")
+	ErrTransferLimitExceeded = errors.New("the amount is above the transfer limit of the signatory")
+	ErrInvalidHolderRole     = errors.New("holder role must be co_owner, viewer or signatory")
+	ErrInvalidTransferLimit  = errors.New("a signatory needs a positive transfer limit, the other roles have none")
+	ErrHolderExists          = errors.New("the user already holds the account")
+	ErrHolderNotAllowed      = errors.New("the user is not allowed to manage this holder of the account")
+)
+
+// AccountRole returns the role of the user on the account with the transfer limit of a signatory,
+// ErrNotAccountHolder when the user doesn't hold the account and sql.ErrNoRows when the account doesn't exist
+func (store *Store) AccountRole(ctx context.Context, accountId int64, username string) (GetAccountRoleRow, error) {
+	return accountRole(store.Queries, ctx, accountId, username)
+}
+
+func accountRole(q *Queries, ctx context.Context, accountId int64, username string) (GetAccountRoleRow, error) {
+	role, err := q.GetAccountRole(ctx, GetAccountRoleParams{Username: username, AccountID: accountId})
+	if err == nil && role.Role == "" {
+		err = fmt.Errorf("%w: %s on account %d", ErrNotAccountHolder, username, accountId)
+	}
+	return role, err
+}
+
+// checkDebitRole checks the role of the actor of the context allows it to send amount from the account.
+// The owner and co-owners can send any amount, a signatory up to its transfer limit and a viewer nothing.
+// The mutations of the system, without actor, aren't checked.
+func checkDebitRole(q *Queries, ctx context.Context, accountId int64, amount int64) error {
+	actor, ok := userActor(ctx)
+	if !ok {
+		return nil
+	}
+
+	role, err := accountRole(q, ctx, accountId, actor)
+	if err != nil {
+		return err
+	}
+
+	switch role.Role {
+	case RoleViewer:
+		return ErrViewerCannotTransfer
+	case RoleSignatory:
+		if amount > role.TransferLimit.Int64 {
+			return fmt.Errorf("%w: %d > %d", ErrTransferLimitExceeded, amount, role.TransferLimit.Int64)
+		}
+	}
+	return nil
+}
+
+// AddAccountHolderParams contains the input parameters of AddAccountHolder
+type AddAccountHolderParams struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	Role      string `json:"role"`
+	// TransferLimit is required for a signatory and must be zero for the other roles
+	TransferLimit int64 `json:"transfer_limit"`
+}
+
+// AddAccountHolder gives a user a role on the account, the actor of the context is recorded as inviting it.
+// The owner can add any role, a co-owner can add viewers and signatories.
+func (store *Store) AddAccountHolder(ctx context.Context, params AddAccountHolderParams) (AccountHolder, error) {
+	var holder AccountHolder
+
+	switch params.Role {
+	case RoleCoOwner, RoleViewer:
+		if params.TransferLimit != 0 {
+			return holder, ErrInvalidTransferLimit
+		}
+	case RoleSignatory:
+		if params.TransferLimit <= 0 {
+			return holder, ErrInvalidTransferLimit
+		}
+	default:
+		return holder, fmt.Errorf("%w: %q", ErrInvalidHolderRole, params.Role)
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, params.AccountID)
+		if err != nil {
+			return err
+		}
+		if account.Owner == params.Username {
+			return ErrHolderExists
+		}
+
+		err = checkManageHolder(q, ctx, params.AccountID, params.Role)
+		if err != nil {
+			return err
+		}
+
+		holder, err = q.CreateAccountHolder(ctx, CreateAccountHolderParams{
+			AccountID:     params.AccountID,
+			Username:      params.Username,
+			Role:          params.Role,
+			TransferLimit: sql.NullInt64{Int64: params.TransferLimit, Valid: params.Role == RoleSignatory},
+			InvitedBy:     ActorFromContext(ctx),
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "account_holders_pkey" {
+			return ErrHolderExists
+		}
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditAccountAddHolder, AuditEntityAccount, params.AccountID, nil, holder)
+		return nil
+	})
+
+	return holder, err
+}
+
+// RemoveAccountHolder removes the role of a user on the account.
+// The owner can remove anyone, a co-owner the viewers and signatories, and every holder can leave the account.
+// It returns ErrNotAccountHolder when the user doesn't hold the account, the owner can't be removed.
+func (store *Store) RemoveAccountHolder(ctx context.Context, accountId int64, username string) error {
+	return store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetAccountForUpdate(ctx, accountId)
+		if err != nil {
+			return err
+		}
+
+		holder, err := q.GetAccountHolder(ctx, GetAccountHolderParams{AccountID: accountId, Username: username})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: %s on account %d", ErrNotAccountHolder, username, accountId)
+		}
+		if err != nil {
+			return err
+		}
+
+		if actor, ok := userActor(ctx); !ok || actor != username {
+			err = checkManageHolder(q, ctx, accountId, holder.Role)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = q.DeleteAccountHolder(ctx, DeleteAccountHolderParams{AccountID: accountId, Username: username})
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditAccountRemoveHolder, AuditEntityAccount, accountId, holder, nil)
+		return nil
+	})
+}
+
+// checkManageHolder checks the actor of the context can add or remove a holder with the role
+func checkManageHolder(q *Queries, ctx context.Context, accountId int64, role string) error {
+	actor, ok := userActor(ctx)
+	if !ok {
+		return nil
+	}
+
+	actorRole, err := accountRole(q, ctx, accountId, actor)
+	if errors.Is(err, ErrNotAccountHolder) {
+		return ErrHolderNotAllowed
+	}
+	if err != nil {
+		return err
+	}
+
+	if actorRole.Role == RoleOwner || (actorRole.Role == RoleCoOwner && role != RoleCoOwner) {
+		return nil
+	}
+	return ErrHolderNotAllowed
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestStore_AccountHolders(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, "USD")
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	owner := WithActor(context.Background(), account.Owner)
+	coOwnerName, viewerName, signatoryName := util.RandomOwner(), util.RandomOwner(), util.RandomOwner()
+	coOwner := WithActor(context.Background(), coOwnerName)
+	viewer := WithActor(context.Background(), viewerName)
+	signatory := WithActor(context.Background(), signatoryName)
+
+	_, err := store.AddAccountHolder(owner, AddAccountHolderParams{AccountID: account.ID, Username: signatoryName, Role: RoleSignatory})
+	require.ErrorIs(t, err, ErrInvalidTransferLimit)
+
+	_, err = store.AddAccountHolder(viewer, AddAccountHolderParams{AccountID: account.ID, Username: viewerName, Role: RoleViewer})
+	require.ErrorIs(t, err, ErrHolderNotAllowed)
+
+	holder, err := store.AddAccountHolder(owner, AddAccountHolderParams{AccountID: account.ID, Username: coOwnerName, Role: RoleCoOwner})
+	require.NoError(t, err)
+	require.Equal(t, account.Owner, holder.InvitedBy)
+	require.False(t, holder.TransferLimit.Valid)
+
+	_, err = store.AddAccountHolder(coOwner, AddAccountHolderParams{AccountID: account.ID, Username: util.RandomOwner(), Role: RoleCoOwner})
+	require.ErrorIs(t, err, ErrHolderNotAllowed)
+
+	_, err = store.AddAccountHolder(coOwner, AddAccountHolderParams{AccountID: account.ID, Username: viewerName, Role: RoleViewer})
+	require.NoError(t, err)
+
+	_, err = store.AddAccountHolder(coOwner, AddAccountHolderParams{AccountID: account.ID, Username: viewerName, Role: RoleViewer})
+	require.ErrorIs(t, err, ErrHolderExists)
+
+	holder, err = store.AddAccountHolder(coOwner, AddAccountHolderParams{
+		AccountID:     account.ID,
+		Username:      signatoryName,
+		Role:          RoleSignatory,
+		TransferLimit: 50,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(50), holder.TransferLimit.Int64)
+
+	holders, err := store.ListAccountHolders(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, holders, 3)
+
+	page, err := store.ListAccountsPage(context.Background(), viewerName, PageParams{})
+	require.NoError(t, err)
+	require.Len(t, page.Accounts, 1)
+	require.Equal(t, account.ID, page.Accounts[0].ID)
+
+	params := TransferTxParams{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 10}
+
+	_, err = store.TransferTX(coOwner, params)
+	require.NoError(t, err)
+
+	_, err = store.TransferTX(viewer, params)
+	require.ErrorIs(t, err, ErrViewerCannotTransfer)
+
+	_, err = store.TransferTX(signatory, params)
+	require.NoError(t, err)
+
+	params.Amount = 51
+	_, err = store.TransferTX(signatory, params)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTX(WithActor(context.Background(), util.RandomOwner()), params)
+	require.ErrorIs(t, err, ErrNotAccountHolder)
+
+	// a co-owner can't remove another co-owner, but every holder can leave
+	err = store.RemoveAccountHolder(signatory, account.ID, coOwnerName)
+	require.ErrorIs(t, err, ErrHolderNotAllowed)
+
+	err = store.RemoveAccountHolder(viewer, account.ID, viewerName)
+	require.NoError(t, err)
+
+	err = store.RemoveAccountHolder(owner, account.ID, viewerName)
+	require.ErrorIs(t, err, ErrNotAccountHolder)
+
+	logs, err := store.ListAuditLogByEntity(context.Background(), ListAuditLogByEntityParams{EntityType: AuditEntityAccount, EntityID: account.ID})
+	require.NoError(t, err)
+
+	var actions []string
+	for _, log := range logs {
+		actions = append(actions, log.Action)
+	}
+	require.Contains(t, actions, AuditAccountAddHolder)
+	require.Contains(t, actions, AuditAccountRemoveHolder)
+}
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// Day count conventions of the account products
+const (
+	DayCountActual365 = "ACT/365"
+	DayCountActual360 = "ACT/360"
+	DayCount30360     = "30/360"
+)
+
+var (
+	ErrUnknownDayCount     = errors.New("unknown day count convention")
+	ErrNoExpenseAccount    = errors.New("no interest expense account for the currency")
+	errInterestAlreadyPaid = errors.New("interest already posted for the period")
+)
+
+type AccrueInterestResult struct {
+	Accrued int `json:"accrued"`
+	// AlreadyAccrued counts the accounts skipped because the date was already accrued
+	AlreadyAccrued int `json:"already_accrued"`
+}
+
+// AccrueInterest stores one day of accrued but unpaid interest for every account with a product and a positive balance.
+// The interest is computed on the current balance, so the job is meant to run once a day, at the end of the day.
+// Accruing the same date twice is safe, accounts that already have an accrual for the date are skipped.
+func (store *Store) AccrueInterest(ctx context.Context, date time.Time) (AccrueInterestResult, error) {
+	var result AccrueInterestResult
+	date = truncateToDay(date)
+
+	accounts, err := store.ListAccountsForAccrual(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, account := range accounts {
+		micros, err := dailyInterestMicros(account.Balance, account.AnnualRateBps, account.DayCount, date)
+		if err != nil {
+			return result, fmt.Errorf("account %d: %w", account.ID, err)
+		}
+
+		rows, err := store.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:    account.ID,
+			AccrualDate:  date,
+			Balance:      account.Balance,
+			AmountMicros: micros,
+		})
+		if err != nil {
+			return result, fmt.Errorf("account %d: %w", account.ID, err)
+		}
+
+		if rows == 0 {
+			result.AlreadyAccrued++
+		} else {
+			result.Accrued++
+		}
+	}
+
+	return result, nil
+}
+
+// AccrueInterestUntil accrues every day after the last accrued date up to until, so the days missed while the job
+// wasn't running are caught up. Only until is accrued when nothing was accrued yet.
+// The missed days are computed on the current balance as well.
+func (store *Store) AccrueInterestUntil(ctx context.Context, until time.Time) (AccrueInterestResult, error) {
+	var result AccrueInterestResult
+
+	last, err := store.GetLastAccrualDate(ctx)
+	if err != nil {
+		return result, err
+	}
+
+	for _, date := range accrualDates(last, until) {
+		accrued, err := store.AccrueInterest(ctx, date)
+		if err != nil {
+			return result, fmt.Errorf("%s: %w", date.Format(time.DateOnly), err)
+		}
+		result.Accrued += accrued.Accrued
+		result.AlreadyAccrued += accrued.AlreadyAccrued
+	}
+
+	return result, nil
+}
+
+// accrualDates returns the days after last up to until, or only until when last is invalid
+func accrualDates(last sql.NullTime, until time.Time) []time.Time {
+	until = truncateToDay(until)
+	if !last.Valid {
+		return []time.Time{until}
+	}
+
+	var dates []time.Time
+	for date := truncateToDay(last.Time).AddDate(0, 0, 1); !date.After(until); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date)
+	}
+	return dates
+}
+
+// PostInterestParams contains the input parameters of PostInterest
+type PostInterestParams struct {
+	// Period is any day of the month to post
+	Period time.Time `json:"period"`
+	// ExpenseAccounts pay the interest, by currency
+	ExpenseAccounts map[string]int64 `json:"expense_accounts"`
+}
+
+type PostInterestResult struct {
+	Postings []InterestPosting `json:"postings"`
+	// AlreadyPosted counts the accounts skipped because the period was already posted
+	AlreadyPosted int `json:"already_posted"`
+}
+
+// PostInterest credits the interest accrued during a month to every account, debiting the interest expense account of its currency.
+// The accrued amount is rounded half to even to the minor unit.
+// Every account is posted in its own database transaction and at most once per period, so re-running a month is safe.
+func (store *Store) PostInterest(ctx context.Context, params PostInterestParams) (PostInterestResult, error) {
+	var result PostInterestResult
+
+	periodStart := firstDayOfMonth(params.Period)
+	accrued, err := store.ListAccruedInterest(ctx, ListAccruedInterestParams{
+		PeriodStart: periodStart,
+		PeriodEnd:   periodStart.AddDate(0, 1, 0),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, interest := range accrued {
+		expenseAccountId, ok := params.ExpenseAccounts[interest.Currency]
+		if !ok {
+			return result, fmt.Errorf("%w: %s", ErrNoExpenseAccount, interest.Currency)
+		}
+
+		posting, err := store.postAccountInterest(ctx, interest, periodStart, expenseAccountId)
+		if errors.Is(err, errInterestAlreadyPaid) {
+			result.AlreadyPosted++
+			continue
+		}
+		if err != nil {
+			return result, fmt.Errorf("account %d: %w", interest.AccountID, err)
+		}
+
+		result.Postings = append(result.Postings, posting)
+	}
+
+	return result, nil
+}
+
+func (store *Store) postAccountInterest(
+	ctx context.Context,
+	interest ListAccruedInterestRow,
+	period time.Time,
+	expenseAccountId int64,
+) (InterestPosting, error) {
+	var posting InterestPosting
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		var entry, expenseEntry Entry
+
+		amount := roundMicros(interest.TotalMicros)
+		if amount > 0 {
+			expenseEntry, err = createNewEntry(q, ctx, expenseAccountId, -amount)
+			if err != nil {
+				return err
+			}
+
+			entry, err = createNewEntry(q, ctx, interest.AccountID, amount)
+			if err != nil {
+				return err
+			}
+
+			accounts, err := updateLegsBalance(q, ctx, []TransferLeg{
+				{AccountId: expenseAccountId, Amount: -amount},
+				{AccountId: interest.AccountID, Amount: amount},
+			})
+			if err != nil {
+				return err
+			}
+
+			err = createLegsEvents(q, ctx, []Entry{expenseEntry, entry}, accounts)
+			if err != nil {
+				return err
+			}
+
+			store.auditEntries(q, expenseEntry, entry)
+		}
+
+		// the posting is created last, so a concurrent or repeated posting rolls back its entries
+		posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID:      interest.AccountID,
+			Period:         period,
+			Amount:         amount,
+			EntryID:        sql.NullInt64{Int64: entry.ID, Valid: amount > 0},
+			ExpenseEntryID: sql.NullInt64{Int64: expenseEntry.ID, Valid: amount > 0},
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return errInterestAlreadyPaid
+		}
+		return err
+	})
+
+	return posting, err
+}
+
+// dayCountFraction returns the fraction of a year that a single day accrues for
+func dayCountFraction(convention string, date time.Time) (numerator int64, denominator int64, err error) {
+	switch convention {
+	case DayCountActual365:
+		return 1, 365, nil
+	case DayCountActual360:
+		return 1, 360, nil
+	case DayCount30360:
+		return days30360(date, date.AddDate(0, 0, 1)), 360, nil
+	default:
+		return 0, 0, fmt.Errorf("%w: %q", ErrUnknownDayCount, convention)
+	}
+}
+
+// days30360 returns the days between two dates with the US 30/360 convention,
+// where every month has 30 days: a 31 days month accrues nothing for one day and the end of February accrues up to the 30th
+func days30360(from, to time.Time) int64 {
+	y1, m1, d1 := from.Date()
+	y2, m2, d2 := to.Date()
+
+	if d1 == 31 {
+		d1 = 30
+	}
+	if d2 == 31 && d1 >= 30 {
+		d2 = 30
+	}
+
+	return int64(360*(y2-y1) + 30*(int(m2)-int(m1)) + (d2 - d1))
+}
+
+// dailyInterestMicros returns the interest of one day, in millionths of the minor unit
+func dailyInterestMicros(balance int64, annualRateBps int64, convention string, date time.Time) (int64, error) {
+	numerator, denominator, err := dayCountFraction(convention, date)
+	if err != nil {
+		return 0, err
+	}
+
+	// balance * rate / 10000 * fraction * 1000000, computed with big ints to avoid overflows
+	micros := new(big.Int).Mul(big.NewInt(balance), big.NewInt(annualRateBps))
+	micros.Mul(micros, big.NewInt(100*numerator))
+	micros.Quo(micros, big.NewInt(denominator))
+
+	if !micros.IsInt64() {
+		return 0, fmt.Errorf("interest of balance %d overflows", balance)
+	}
+
+	return micros.Int64(), nil
+}
+
+// roundMicros rounds millionths of the minor unit to the minor unit, half to even
+func roundMicros(micros int64) int64 {
+	const unit = 1000000
+
+	quotient, remainder := micros/unit, micros%unit
+	if remainder < 0 {
+		quotient, remainder = quotient-1, remainder+unit
+	}
+
+	if remainder > unit/2 || (remainder == unit/2 && quotient%2 != 0) {
+		quotient++
+	}
+
+	return quotient
+}
+
+func truncateToDay(date time.Time) time.Time {
+	year, month, day := date.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func firstDayOfMonth(date time.Time) time.Time {
+	year, month, _ := date.Date()
+	return time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,155 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestDays30360(t *testing.T) {
+	require.Equal(t, int64(1), days30360(date(2023, 1, 15), date(2023, 1, 16)))
+	require.Equal(t, int64(0), days30360(date(2023, 1, 30), date(2023, 1, 31)))
+	require.Equal(t, int64(1), days30360(date(2023, 1, 31), date(2023, 2, 1)))
+	require.Equal(t, int64(3), days30360(date(2023, 2, 28), date(2023, 3, 1)))
+	require.Equal(t, int64(2), days30360(date(2024, 2, 29), date(2024, 3, 1)))
+	require.Equal(t, int64(360), days30360(date(2023, 1, 1), date(2024, 1, 1)))
+
+	// every month accrues 30 days
+	for month := time.January; month <= time.December; month++ {
+		var total int64
+		for day := date(2023, month, 1); day.Month() == month; day = day.AddDate(0, 0, 1) {
+			numerator, denominator, err := dayCountFraction(DayCount30360, day)
+			require.NoError(t, err)
+			require.Equal(t, int64(360), denominator)
+			total += numerator
+		}
+		require.Equal(t, int64(30), total, month.String())
+	}
+}
+
+func TestDailyInterestMicros(t *testing.T) {
+	// 3.65% of 10000 over a 365 days year is 1 per day
+	micros, err := dailyInterestMicros(10000, 365, DayCountActual365, date(2023, 5, 10))
+	require.NoError(t, err)
+	require.Equal(t, int64(1000000), micros)
+
+	// 3.6% of 10000 over a 360 days year is 1 per day
+	micros, err = dailyInterestMicros(10000, 360, DayCountActual360, date(2023, 5, 10))
+	require.NoError(t, err)
+	require.Equal(t, int64(1000000), micros)
+
+	// the 30th of a 31 days month accrues nothing with 30/360
+	micros, err = dailyInterestMicros(10000, 360, DayCount30360, date(2023, 5, 30))
+	require.NoError(t, err)
+	require.Zero(t, micros)
+
+	_, err = dailyInterestMicros(10000, 360, "ACT/ACT", date(2023, 5, 31))
+	require.ErrorIs(t, err, ErrUnknownDayCount)
+}
+
+func TestAccrualDates(t *testing.T) {
+	until := date(2024, time.March, 2)
+
+	require.Equal(t, []time.Time{until}, accrualDates(sql.NullTime{}, until))
+	require.Equal(t, []time.Time{date(2024, time.February, 28), date(2024, time.February, 29), date(2024, time.March, 1), until},
+		accrualDates(sql.NullTime{Time: date(2024, time.February, 27), Valid: true}, until))
+	require.Empty(t, accrualDates(sql.NullTime{Time: until, Valid: true}, until))
+}
+
+func TestRoundMicros(t *testing.T) {
+	require.Equal(t, int64(0), roundMicros(499999))
+	require.Equal(t, int64(0), roundMicros(500000))
+	require.Equal(t, int64(1), roundMicros(500001))
+	require.Equal(t, int64(2), roundMicros(1500000))
+	require.Equal(t, int64(2), roundMicros(2500000))
+	require.Equal(t, int64(3), roundMicros(2500001))
+	require.Equal(t, int64(-2), roundMicros(-1500000))
+}
+
+func createRandomAccountWithProduct(t *testing.T, product AccountProduct, balance int64) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  balance,
+		Currency: "EUR",
+	})
+	require.NoError(t, err)
+
+	account, err = testQueries.SetAccountProduct(context.Background(), SetAccountProductParams{
+		ID:        account.ID,
+		ProductID: sql.NullInt64{Int64: product.ID, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, product.ID, account.ProductID.Int64)
+
+	return withVerifiedCustomer(t, account)
+}
+
+func TestStore_AccrueAndPostInterest(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	product, err := store.CreateAccountProduct(ctx, CreateAccountProductParams{
+		Name:          "savings " + util.RandomString(8),
+		AnnualRateBps: 365,
+		DayCount:      DayCountActual365,
+	})
+	require.NoError(t, err)
+
+	// interest is only accrued on new savings accounts,
+	// so use a year far in the past that no other test posts
+	year := int(util.RandomInt(1000, 1900))
+	account := createRandomAccountWithProduct(t, product, 10000)
+	expense := createRandomAccountWithCurrency(t, "EUR")
+
+	for day := 1; day <= 3; day++ {
+		_, err = store.AccrueInterest(ctx, date(year, time.March, day))
+		require.NoError(t, err)
+	}
+
+	// re-running a day must not accrue twice
+	result, err := store.AccrueInterest(ctx, date(year, time.March, 3))
+	require.NoError(t, err)
+	require.NotZero(t, result.AlreadyAccrued)
+
+	accruals, err := store.ListInterestAccruals(ctx, account.ID)
+	require.NoError(t, err)
+	require.Len(t, accruals, 3)
+	for _, accrual := range accruals {
+		require.Equal(t, int64(1000000), accrual.AmountMicros)
+	}
+
+	params := PostInterestParams{
+		Period:          date(year, time.March, 15),
+		ExpenseAccounts: map[string]int64{"EUR": expense.ID},
+	}
+
+	posted, err := store.PostInterest(ctx, params)
+	require.NoError(t, err)
+	require.NotEmpty(t, posted.Postings)
+
+	posting, err := store.GetInterestPosting(ctx, GetInterestPostingParams{
+		AccountID: account.ID,
+		Period:    date(year, time.March, 1),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(3), posting.Amount)
+	require.True(t, posting.EntryID.Valid)
+	require.True(t, posting.ExpenseEntryID.Valid)
+
+	checkBalance(t, account, 3)
+
+	// re-running the month must not post twice
+	posted, err = store.PostInterest(ctx, params)
+	require.NoError(t, err)
+	require.NotZero(t, posted.AlreadyPosted)
+
+	checkBalance(t, account, 3)
+}
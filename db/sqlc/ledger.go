@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// LedgerCheck is the result of CheckLedger, the ledger is balanced when all its lists are empty
+type LedgerCheck struct {
+	// Accounts have a balance that is not the sum of their entries
+	Accounts []ListUnbalancedAccountsRow `json:"accounts"`
+	// Transfers have entries that don't sum to zero
+	Transfers []ListUnbalancedTransfersRow `json:"transfers"`
+	// Currencies have entries that don't sum to zero
+	Currencies []ListUnbalancedCurrenciesRow `json:"currencies"`
+}
+
+// Balanced reports whether the check found nothing wrong
+func (check LedgerCheck) Balanced() bool {
+	return len(check.Accounts) == 0 && len(check.Transfers) == 0 && len(check.Currencies) == 0
+}
+
+// CheckLedger checks the double entry bookkeeping of the whole ledger: every account balance must be the sum
+// of its entries, and the entries of every transfer and of every currency must sum to zero.
+// The checks read a single snapshot of the database, so the transactions running meanwhile can't skew them.
+func (store *Store) CheckLedger(ctx context.Context) (LedgerCheck, error) {
+	var check LedgerCheck
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return check, err
+	}
+	defer tx.Rollback()
+
+	q := New(store.conn(tx, nil))
+
+	check.Accounts, err = q.ListUnbalancedAccounts(ctx)
+	if err != nil {
+		return check, err
+	}
+
+	check.Transfers, err = q.ListUnbalancedTransfers(ctx)
+	if err != nil {
+		return check, err
+	}
+
+	check.Currencies, err = q.ListUnbalancedCurrencies(ctx)
+	if err != nil {
+		return check, err
+	}
+
+	return check, tx.Commit()
+}
@@ -0,0 +1,66 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestStore_CheckLedger(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account1 := createAccountWithBalance(t, "EUR", 0)
+	account2 := createAccountWithBalance(t, "EUR", 0)
+	unbooked := createAccountWithBalance(t, "EUR", 100)
+
+	result, err := store.TransferTX(ctx, TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 30})
+	require.NoError(t, err)
+
+	check, err := store.CheckLedger(ctx)
+	require.NoError(t, err)
+	require.Contains(t, check.Accounts, ListUnbalancedAccountsRow{ID: unbooked.ID, Currency: "EUR", Balance: 100})
+	require.NotContains(t, unbalancedAccountIds(check), account1.ID)
+	require.NotContains(t, unbalancedAccountIds(check), account2.ID)
+	require.NotContains(t, check.Transfers, ListUnbalancedTransfersRow{TransferID: result.Transfer.ID})
+
+	// an entry booked on the transfer without its counterpart
+	_, err = testQueries.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  account2.ID,
+		Amount:     5,
+		TransferID: sql.NullInt64{Int64: result.Transfer.ID, Valid: true},
+	})
+	require.NoError(t, err)
+
+	check, err = store.CheckLedger(ctx)
+	require.NoError(t, err)
+	require.False(t, check.Balanced())
+	require.Contains(t, check.Transfers, ListUnbalancedTransfersRow{TransferID: result.Transfer.ID, EntriesTotal: 5})
+	require.Contains(t, check.Accounts, ListUnbalancedAccountsRow{ID: account2.ID, Currency: "EUR", Balance: 30, EntriesTotal: 35})
+}
+
+func TestLedgerCheck_Balanced(t *testing.T) {
+	require.True(t, LedgerCheck{}.Balanced())
+	require.False(t, LedgerCheck{Currencies: []ListUnbalancedCurrenciesRow{{Currency: "EUR", EntriesTotal: 1}}}.Balanced())
+}
+
+func createAccountWithBalance(t *testing.T, currency string, balance int64) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  balance,
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	return withVerifiedCustomer(t, account)
+}
+
+func unbalancedAccountIds(check LedgerCheck) []int64 {
+	ids := make([]int64, len(check.Accounts))
+	for i, account := range check.Accounts {
+		ids[i] = account.ID
+	}
+	return ids
+}
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	_ "github.com/lib/pq"
+	"simple_bank/util"
 )
 
 const (
@@ -26,6 +27,5 @@ func TestMain(m *testing.M) {
 	}
 
 	testQueries = New(testDB)
-	os.Exit(m.Run())
-
+	os.Exit(util.RunTests(m))
 }
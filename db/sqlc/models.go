@@ -5,23 +5,175 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
 type Account struct {
-	ID        int64     `json:"id"`
-	Owner     string    `json:"owner"`
-	Balance   int64     `json:"balance"`
-	Currency  string    `json:"currency"`
+	ID        int64         `json:"id"`
+	Owner     string        `json:"owner"`
+	Balance   int64         `json:"balance"`
+	Currency  string        `json:"currency"`
+	CreatedAt time.Time     `json:"created_at"`
+	ProductID sql.NullInt64 `json:"product_id"`
+	// null for the internal accounts of the bank and the accounts opened before customers existed
+	CustomerID sql.NullInt64 `json:"customer_id"`
+	// transfers above this amount need the approval of a second holder, null when no approval is needed
+	ApprovalThreshold sql.NullInt64 `json:"approval_threshold"`
+	// the wallet the account is the balance of, in its currency
+	WalletID sql.NullInt64 `json:"wallet_id"`
+	// no money moves in or out of a frozen account, null when the account is not frozen
+	FrozenAt sql.NullTime `json:"frozen_at"`
+}
+
+type AccountHolder struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// co_owner, viewer or signatory, the owner of the account is accounts.owner
+	Role string `json:"role"`
+	// maximum amount of a single transfer made by a signatory
+	TransferLimit sql.NullInt64 `json:"transfer_limit"`
+	InvitedBy     string        `json:"invited_by"`
+	CreatedAt     time.Time     `json:"created_at"`
+}
+
+type AccountProduct struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// 1 basis point is 0.01%
+	AnnualRateBps int64     `json:"annual_rate_bps"`
+	DayCount      string    `json:"day_count"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type AuditLog struct {
+	ID         int64  `json:"id"`
+	Actor      string `json:"actor"`
+	Action     string `json:"action"`
+	EntityType string `json:"entity_type"`
+	EntityID   int64  `json:"entity_id"`
+	// JSON, stored as text so the hashed bytes are kept as is
+	Before string `json:"before"`
+	// JSON, stored as text so the hashed bytes are kept as is
+	After    string `json:"after"`
+	PrevHash string `json:"prev_hash"`
+	// SHA-256 of the previous hash and the fields of the row
+	Hash      string    `json:"hash"`
 	CreatedAt time.Time `json:"created_at"`
 }
 
+type BalanceAdjustment struct {
+	ID                int64 `json:"id"`
+	AccountID         int64 `json:"account_id"`
+	SuspenseAccountID int64 `json:"suspense_account_id"`
+	// credited to the account and debited from the suspense account, can be negative
+	Amount          int64     `json:"amount"`
+	Reason          string    `json:"reason"`
+	Actor           string    `json:"actor"`
+	EntryID         int64     `json:"entry_id"`
+	SuspenseEntryID int64     `json:"suspense_entry_id"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type Conversion struct {
+	ID            int64 `json:"id"`
+	WalletID      int64 `json:"wallet_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	FromAmount    int64 `json:"from_amount"`
+	ToAmount      int64 `json:"to_amount"`
+	// units of the to currency for one unit of the from currency
+	Rate        string    `json:"rate"`
+	Actor       string    `json:"actor"`
+	FromEntryID int64     `json:"from_entry_id"`
+	ToEntryID   int64     `json:"to_entry_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Customer struct {
+	ID          int64     `json:"id"`
+	LegalName   string    `json:"legal_name"`
+	DateOfBirth time.Time `json:"date_of_birth"`
+	Address     string    `json:"address"`
+	NationalID  string    `json:"national_id"`
+	// pending, verified or rejected
+	KycStatus     string       `json:"kyc_status"`
+	KycReviewedBy string       `json:"kyc_reviewed_by"`
+	KycReviewedAt sql.NullTime `json:"kyc_reviewed_at"`
+	// set when the customer is verified, cleared when it is rejected
+	KycVerifiedAt      sql.NullTime `json:"kyc_verified_at"`
+	KycRejectionReason string       `json:"kyc_rejection_reason"`
+	CreatedAt          time.Time    `json:"created_at"`
+}
+
 type Entry struct {
 	ID        int64 `json:"id"`
 	AccountID int64 `json:"account_id"`
 	// can be negative or negative
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// null when the entry doesn't belong to a transfer
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// null when the entry doesn't belong to a multi transfer
+	MultiTransferID sql.NullInt64 `json:"multi_transfer_id"`
+}
+
+type FxRate struct {
+	BaseCurrency  string `json:"base_currency"`
+	QuoteCurrency string `json:"quote_currency"`
+	// units of the quote currency for one unit of the base currency
+	Rate string `json:"rate"`
+	// the rate is valid from this time until the next rate of the pair
+	EffectiveFrom time.Time `json:"effective_from"`
+	// manual, csv or ecb
+	Source    string    `json:"source"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	Balance     int64     `json:"balance"`
+	// millionths of the minor unit, accrued but not paid
+	AmountMicros int64     `json:"amount_micros"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the month the interest was accrued
+	Period         time.Time     `json:"period"`
+	Amount         int64         `json:"amount"`
+	EntryID        sql.NullInt64 `json:"entry_id"`
+	ExpenseEntryID sql.NullInt64 `json:"expense_entry_id"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type MultiTransfer struct {
+	ID int64 `json:"id"`
+	// the multi transfer this one moves back, null when it is not a reversal
+	ReversesID sql.NullInt64 `json:"reverses_id"`
+	// reason of the reversal, empty when it is not a reversal
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Outbox struct {
+	ID        int64  `json:"id"`
+	EventType string `json:"event_type"`
+	// transfer ID or account ID, depending on the event type
+	AggregateID int64           `json:"aggregate_id"`
+	Payload     json.RawMessage `json:"payload"`
+	CreatedAt   time.Time       `json:"created_at"`
+	// null until the relay published the event
+	DeliveredAt sql.NullTime `json:"delivered_at"`
+	// a relay is publishing the event until then, null when no relay claimed it
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	// the transaction that created the event, the events are streamed in its order once it ended
+	XactID int64 `json:"xact_id"`
 }
 
 type Transfer struct {
@@ -31,4 +183,84 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// charged to the sender on top of the amount
+	Fee         int64  `json:"fee"`
+	Reference   string `json:"reference"`
+	Description string `json:"description"`
+	// free form JSON object given by the client
+	Metadata json.RawMessage `json:"metadata"`
+}
+
+type TransferRequest struct {
+	ID            int64           `json:"id"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Reference     string          `json:"reference"`
+	Description   string          `json:"description"`
+	Metadata      json.RawMessage `json:"metadata"`
+	// pending_approval, approved, rejected or expired
+	Status          string       `json:"status"`
+	RequestedBy     string       `json:"requested_by"`
+	ReviewedBy      string       `json:"reviewed_by"`
+	ReviewedAt      sql.NullTime `json:"reviewed_at"`
+	RejectionReason string       `json:"rejection_reason"`
+	// the transfer executed when the request was approved
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type TransferReversal struct {
+	ID         int64 `json:"id"`
+	TransferID int64 `json:"transfer_id"`
+	// the transfer moving the amount back, the fee of the reversed transfer is not refunded
+	ReversalID int64     `json:"reversal_id"`
+	Reason     string    `json:"reason"`
+	Actor      string    `json:"actor"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Wallet struct {
+	ID         int64     `json:"id"`
+	CustomerID int64     `json:"customer_id"`
+	Owner      string    `json:"owner"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	EventID        int64 `json:"event_id"`
+	Attempt        int32 `json:"attempt"`
+	// null when no response was received
+	StatusCode sql.NullInt32 `json:"status_code"`
+	Error      string        `json:"error"`
+	Succeeded  bool          `json:"succeeded"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type WebhookJob struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	EventID        int64 `json:"event_id"`
+	// failed delivery attempts so far
+	Attempts      int32     `json:"attempts"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+	// a dispatcher is delivering the event until then, null when no dispatcher claimed it
+	ClaimedUntil sql.NullTime `json:"claimed_until"`
+	CreatedAt    time.Time    `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID     int64  `json:"id"`
+	Owner  string `json:"owner"`
+	Url    string `json:"url"`
+	Secret string `json:"secret"`
+	// empty means every event type
+	EventTypes          []string     `json:"event_types"`
+	Active              bool         `json:"active"`
+	ConsecutiveFailures int32        `json:"consecutive_failures"`
+	DisabledAt          sql.NullTime `json:"disabled_at"`
+	CreatedAt           time.Time    `json:"created_at"`
 }
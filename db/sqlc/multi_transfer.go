@@ -0,0 +1,346 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrNotEnoughLegs  = errors.New("a multi transfer needs at least two legs")
+	ErrZeroAmountLeg  = errors.New("a transfer leg amount must not be zero")
+	ErrUnbalancedLegs = errors.New("transfer legs must sum to zero per currency")
+)
+
+// TransferLeg is a single movement of a multi transfer.
+// A negative amount debits the account and a positive amount credits it.
+type TransferLeg struct {
+	AccountId int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+}
+
+// MultiTransferTxParams contains the input parameters of the multi transfer transaction
+type MultiTransferTxParams struct {
+	Legs []TransferLeg `json:"legs"`
+}
+
+type MultiTransferTxResult struct {
+	// MultiTransfer groups the entries of the legs
+	MultiTransfer MultiTransfer `json:"multi_transfer"`
+	// Entries are returned in the same order as the legs
+	Entries []Entry `json:"entries"`
+	// Accounts are returned in ascending ID order, with their updated balances
+	Accounts []Account `json:"accounts"`
+}
+
+// MultiTransferTX moves money between any number of accounts within a single database transaction.
+// The entries of the legs are linked to a new multi transfer, so they can be looked up and reversed together.
+// The legs must sum to zero for every currency involved, otherwise nothing is written.
+// All touched accounts are locked in ascending ID order, so concurrent multi transfers can't deadlock each other.
+// When the context has an actor, the debits of an account, summed over its legs, must not exceed its approval threshold.
+func (store *Store) MultiTransferTX(
+	ctx context.Context,
+	params MultiTransferTxParams,
+) (MultiTransferTxResult, error) {
+	var result MultiTransferTxResult
+
+	if err := validateLegs(params.Legs); err != nil {
+		return result, err
+	}
+
+	txCtx := withTxTrace(ctx, "MultiTransferTX", attribute.Int("bank.legs", len(params.Legs)))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		var err error
+
+		accountIds := sortedLegAccountIds(params.Legs)
+
+		accounts, err := lockAccounts(q, ctx, accountIds)
+		if err != nil {
+			return err
+		}
+
+		err = checkLegsBalance(params.Legs, accounts)
+		if err != nil {
+			return err
+		}
+
+		for _, accountId := range accountIds {
+			if err = checkNotFrozen(accounts[accountId]); err != nil {
+				return err
+			}
+		}
+
+		debits := make(map[int64]int64)
+		for _, leg := range params.Legs {
+			if leg.Amount > 0 {
+				continue
+			}
+			if err = checkDebitRole(q, ctx, leg.AccountId, -leg.Amount); err != nil {
+				return err
+			}
+			if err = store.checkDebitKYC(q, ctx, leg.AccountId); err != nil {
+				return err
+			}
+			debits[leg.AccountId] -= leg.Amount
+		}
+
+		// a debit split over several legs is checked as a whole
+		for _, accountId := range accountIds {
+			if debit, ok := debits[accountId]; ok {
+				if err = checkApprovalThreshold(q, ctx, accountId, debit); err != nil {
+					return err
+				}
+			}
+		}
+
+		result.MultiTransfer, err = q.CreateMultiTransfer(ctx, CreateMultiTransferParams{})
+		if err != nil {
+			return err
+		}
+
+		err = bookLegs(q, ctx, params.Legs, &result)
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditMultiTransferCreate, AuditEntityMultiTransfer, result.MultiTransfer.ID, nil, result.MultiTransfer)
+		store.auditEntries(q, result.Entries...)
+		return nil
+	})
+
+	return result, err
+}
+
+// ReverseMultiTransfer moves the legs of a multi transfer back, with a new multi transfer linked to the reversed one.
+// Like ReverseTransfer, a multi transfer is reversed at most once, a reversal can't be reversed, and the reversal is
+// a correction of the bank: it's booked on frozen accounts too, without the checks of the roles, and recorded with
+// its reason and actor.
+func (store *Store) ReverseMultiTransfer(
+	ctx context.Context,
+	multiTransferId int64,
+	reason string,
+	actor string,
+) (MultiTransferTxResult, error) {
+	var result MultiTransferTxResult
+
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		return result, ErrNoReversalReason
+	case actor == "":
+		return result, ErrNoReversalActor
+	}
+
+	ctx = WithActor(ctx, actor)
+
+	txCtx := withTxTrace(ctx, "ReverseMultiTransfer", attribute.Int64("bank.multi_transfer_id", multiTransferId))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		original, err := q.GetMultiTransfer(ctx, multiTransferId)
+		if err != nil {
+			return err
+		}
+
+		err = checkMultiTransferReversible(q, ctx, original)
+		if err != nil {
+			return err
+		}
+
+		entries, err := q.ListMultiTransferEntries(ctx, sql.NullInt64{Int64: multiTransferId, Valid: true})
+		if err != nil {
+			return err
+		}
+
+		legs := make([]TransferLeg, len(entries))
+		for i, entry := range entries {
+			legs[i] = TransferLeg{AccountId: entry.AccountID, Amount: -entry.Amount}
+		}
+
+		_, err = lockAccounts(q, ctx, sortedLegAccountIds(legs))
+		if err != nil {
+			return err
+		}
+
+		result.MultiTransfer, err = q.CreateMultiTransfer(ctx, CreateMultiTransferParams{
+			ReversesID: sql.NullInt64{Int64: multiTransferId, Valid: true},
+			Reason:     reason,
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "multi_transfers_reverses_id_key" {
+			return fmt.Errorf("%w: multi transfer %d", ErrTransferAlreadyReversed, multiTransferId)
+		}
+		if err != nil {
+			return err
+		}
+
+		err = bookLegs(q, ctx, legs, &result)
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditMultiTransferCreate, AuditEntityMultiTransfer, result.MultiTransfer.ID, nil, result.MultiTransfer)
+		store.audit(q, AuditMultiTransferReverse, AuditEntityMultiTransfer, multiTransferId, original, result.MultiTransfer)
+		store.auditEntries(q, result.Entries...)
+		return nil
+	})
+
+	return result, err
+}
+
+// checkMultiTransferReversible returns an error when the multi transfer is already reversed or is itself a reversal.
+// Two reversals racing past it are caught by the unique reverses_id of multi_transfers.
+func checkMultiTransferReversible(q *Queries, ctx context.Context, original MultiTransfer) error {
+	if original.ReversesID.Valid {
+		return fmt.Errorf("%w: multi transfer %d reverses multi transfer %d", ErrReverseReversal, original.ID, original.ReversesID.Int64)
+	}
+
+	reversal, err := q.GetMultiTransferReversal(ctx, sql.NullInt64{Int64: original.ID, Valid: true})
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	default:
+		return fmt.Errorf("%w: multi transfer %d by multi transfer %d", ErrTransferAlreadyReversed, original.ID, reversal.ID)
+	}
+}
+
+// bookLegs creates the entries of the legs, linked to result.MultiTransfer, and updates the balances of their
+// accounts, which must be locked already
+func bookLegs(q *Queries, ctx context.Context, legs []TransferLeg, result *MultiTransferTxResult) error {
+	var err error
+
+	result.Entries = make([]Entry, len(legs))
+	for i, leg := range legs {
+		result.Entries[i], err = createMultiTransferEntry(q, ctx, leg.AccountId, leg.Amount, result.MultiTransfer.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	updated, err := updateLegsBalance(q, ctx, legs)
+	if err != nil {
+		return err
+	}
+
+	accountIds := sortedLegAccountIds(legs)
+	result.Accounts = make([]Account, len(accountIds))
+	for i, accountId := range accountIds {
+		result.Accounts[i] = updated[accountId]
+	}
+
+	return createLegsEvents(q, ctx, result.Entries, updated)
+}
+
+func validateLegs(legs []TransferLeg) error {
+	if len(legs) < 2 {
+		return ErrNotEnoughLegs
+	}
+
+	for _, leg := range legs {
+		if leg.Amount == 0 {
+			return ErrZeroAmountLeg
+		}
+	}
+
+	return nil
+}
+
+// sortedLegAccountIds returns the distinct account IDs of the legs in ascending order
+func sortedLegAccountIds(legs []TransferLeg) []int64 {
+	seen := make(map[int64]bool, len(legs))
+	ids := make([]int64, 0, len(legs))
+
+	for _, leg := range legs {
+		if !seen[leg.AccountId] {
+			seen[leg.AccountId] = true
+			ids = append(ids, leg.AccountId)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// lockAccounts locks the given accounts for update, in the given order
+func lockAccounts(
+	q *Queries,
+	ctx context.Context,
+	accountIds []int64,
+) (map[int64]Account, error) {
+	accounts := make(map[int64]Account, len(accountIds))
+
+	for _, accountId := range accountIds {
+		account, err := q.GetAccountForUpdate(ctx, accountId)
+		if err != nil {
+			return nil, fmt.Errorf("lock account %d: %w", accountId, err)
+		}
+		accounts[accountId] = account
+	}
+
+	return accounts, nil
+}
+
+// updateLegsBalance adds the net amount of the legs to every account, in ascending ID order
+func updateLegsBalance(q *Queries, ctx context.Context, legs []TransferLeg) (map[int64]Account, error) {
+	netAmounts := make(map[int64]int64, len(legs))
+	for _, leg := range legs {
+		netAmounts[leg.AccountId] += leg.Amount
+	}
+
+	accounts := make(map[int64]Account, len(netAmounts))
+	for _, accountId := range sortedLegAccountIds(legs) {
+		account, err := updateAccountBalance(q, ctx, accountId, netAmounts[accountId])
+		if err != nil {
+			return nil, err
+		}
+		accounts[accountId] = account
+	}
+
+	return accounts, nil
+}
+
+// createLegsEvents writes a balance changed event per entry.
+// An account can have several legs, so the balance after each entry is computed backwards from the final balance.
+func createLegsEvents(q *Queries, ctx context.Context, entries []Entry, accounts map[int64]Account) error {
+	balances := make([]int64, len(entries))
+	remaining := make(map[int64]int64, len(accounts))
+	for id, account := range accounts {
+		remaining[id] = account.Balance
+	}
+
+	for i := len(entries) - 1; i >= 0; i-- {
+		balances[i] = remaining[entries[i].AccountID]
+		remaining[entries[i].AccountID] -= entries[i].Amount
+	}
+
+	for i, entry := range entries {
+		err := createBalanceChangedEvent(q, ctx, entry, balances[i], accounts[entry.AccountID].Currency, 0)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkLegsBalance(legs []TransferLeg, accounts map[int64]Account) error {
+	totals := make(map[string]int64)
+
+	for _, leg := range legs {
+		totals[accounts[leg.AccountId].Currency] += leg.Amount
+	}
+
+	for currency, total := range totals {
+		if total != 0 {
+			return fmt.Errorf("%w: %s is off by %d", ErrUnbalancedLegs, currency, total)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    util.RandomOwner(),
+		Balance:  util.RandomMoney(),
+		Currency: currency,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, account)
+
+	return withVerifiedCustomer(t, account)
+}
+
+func TestStore_MultiTransferTX(t *testing.T) {
+	store := NewStore(testDB)
+
+	payer := createRandomAccountWithCurrency(t, "EUR")
+	friend1 := createRandomAccountWithCurrency(t, "EUR")
+	friend2 := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.MultiTransferTX(context.Background(), MultiTransferTxParams{
+		Legs: []TransferLeg{
+			{AccountId: payer.ID, Amount: -30},
+			{AccountId: friend1.ID, Amount: 10},
+			{AccountId: friend2.ID, Amount: 20},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Entries, 3)
+	require.Len(t, result.Accounts, 3)
+
+	require.Equal(t, payer.ID, result.Entries[0].AccountID)
+	require.Equal(t, int64(-30), result.Entries[0].Amount)
+	require.Equal(t, friend1.ID, result.Entries[1].AccountID)
+	require.Equal(t, int64(10), result.Entries[1].Amount)
+	require.Equal(t, friend2.ID, result.Entries[2].AccountID)
+	require.Equal(t, int64(20), result.Entries[2].Amount)
+
+	for i := 1; i < len(result.Accounts); i++ {
+		require.Less(t, result.Accounts[i-1].ID, result.Accounts[i].ID)
+	}
+
+	checkBalance(t, payer, -30)
+	checkBalance(t, friend1, 10)
+	checkBalance(t, friend2, 20)
+}
+
+func TestStore_ReverseMultiTransfer(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	payer := createRandomAccountWithCurrency(t, "EUR")
+	friend1 := createRandomAccountWithCurrency(t, "EUR")
+	friend2 := createRandomAccountWithCurrency(t, "EUR")
+
+	original, err := store.MultiTransferTX(ctx, MultiTransferTxParams{
+		Legs: []TransferLeg{
+			{AccountId: payer.ID, Amount: -30},
+			{AccountId: friend1.ID, Amount: 10},
+			{AccountId: friend2.ID, Amount: 20},
+		},
+	})
+	require.NoError(t, err)
+
+	// the entries of the legs are grouped by their multi transfer
+	entries, err := store.ListMultiTransferEntries(ctx, sql.NullInt64{Int64: original.MultiTransfer.ID, Valid: true})
+	require.NoError(t, err)
+	require.Equal(t, original.Entries, entries)
+
+	_, err = store.ReverseMultiTransfer(ctx, original.MultiTransfer.ID, " ", "ops-alice")
+	require.ErrorIs(t, err, ErrNoReversalReason)
+
+	reversal, err := store.ReverseMultiTransfer(ctx, original.MultiTransfer.ID, "wrong split", "ops-alice")
+	require.NoError(t, err)
+	require.Equal(t, original.MultiTransfer.ID, reversal.MultiTransfer.ReversesID.Int64)
+	require.Equal(t, "wrong split", reversal.MultiTransfer.Reason)
+	require.Len(t, reversal.Entries, 3)
+	for i, entry := range reversal.Entries {
+		require.Equal(t, original.Entries[i].AccountID, entry.AccountID)
+		require.Equal(t, -original.Entries[i].Amount, entry.Amount)
+		require.Equal(t, reversal.MultiTransfer.ID, entry.MultiTransferID.Int64)
+	}
+
+	checkBalance(t, payer, 0)
+	checkBalance(t, friend1, 0)
+	checkBalance(t, friend2, 0)
+
+	_, err = store.ReverseMultiTransfer(ctx, original.MultiTransfer.ID, "again", "ops-alice")
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	_, err = store.ReverseMultiTransfer(ctx, reversal.MultiTransfer.ID, "undo", "ops-alice")
+	require.ErrorIs(t, err, ErrReverseReversal)
+}
+
+func TestStore_MultiTransferTXUnbalanced(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+	account3 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := store.MultiTransferTX(context.Background(), MultiTransferTxParams{
+		Legs: []TransferLeg{
+			{AccountId: account1.ID, Amount: -10},
+			{AccountId: account2.ID, Amount: 5},
+			{AccountId: account3.ID, Amount: 5},
+		},
+	})
+	require.ErrorIs(t, err, ErrUnbalancedLegs)
+
+	// nothing must have been written
+	checkBalance(t, account1, 0)
+	checkBalance(t, account2, 0)
+	checkBalance(t, account3, 0)
+}
+
+func TestStore_MultiTransferTXDeadLock(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "CAD")
+	account2 := createRandomAccountWithCurrency(t, "CAD")
+	account3 := createRandomAccountWithCurrency(t, "CAD")
+	accounts := []Account{account1, account2, account3}
+
+	transactionsQty := 9
+	errors := make(chan error)
+
+	for i := 0; i < transactionsQty; i++ {
+		// rotate who pays, so every transaction touches the accounts in a different order
+		from := accounts[i%3]
+		to1 := accounts[(i+1)%3]
+		to2 := accounts[(i+2)%3]
+
+		go func() {
+			_, err := store.MultiTransferTX(context.Background(), MultiTransferTxParams{
+				Legs: []TransferLeg{
+					{AccountId: to2.ID, Amount: 5},
+					{AccountId: from.ID, Amount: -10},
+					{AccountId: to1.ID, Amount: 5},
+				},
+			})
+			errors <- err
+		}()
+	}
+
+	for i := 0; i < transactionsQty; i++ {
+		err := <-errors
+		require.NoError(t, err)
+	}
+
+	// every account paid 3 times and received 6 times
+	for _, account := range accounts {
+		checkBalance(t, account, 0)
+	}
+}
+
+func TestValidateLegs(t *testing.T) {
+	require.ErrorIs(t, validateLegs(nil), ErrNotEnoughLegs)
+	require.ErrorIs(t, validateLegs([]TransferLeg{{AccountId: 1, Amount: -1}}), ErrNotEnoughLegs)
+	require.ErrorIs(t, validateLegs([]TransferLeg{{AccountId: 1, Amount: 0}, {AccountId: 2, Amount: 0}}), ErrZeroAmountLeg)
+	require.NoError(t, validateLegs([]TransferLeg{{AccountId: 1, Amount: -1}, {AccountId: 2, Amount: 1}}))
+}
+
+func TestSortedLegAccountIds(t *testing.T) {
+	legs := []TransferLeg{
+		{AccountId: 7, Amount: -3},
+		{AccountId: 2, Amount: 1},
+		{AccountId: 7, Amount: 1},
+		{AccountId: 4, Amount: 1},
+	}
+
+	require.Equal(t, []int64{2, 4, 7}, sortedLegAccountIds(legs))
+}
+
+func checkBalance(t *testing.T, account Account, expectedBalanceChange int64) {
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance+expectedBalanceChange, updatedAccount.Balance)
+}
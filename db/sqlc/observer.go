@@ -0,0 +1,84 @@
+package db
+
+import (
+	"errors"
+	"time"
+)
+
+// Outcomes of the transfers reported to the Observer
+const (
+	TransferSucceeded        = "succeeded"
+	TransferApprovalRequired = "approval_required"
+	// TransferDenied is a transfer the actor or the account is not allowed to make: role, limit or KYC
+	TransferDenied = "denied"
+	// TransferInvalid is a transfer with invalid details
+	TransferInvalid = "invalid"
+	TransferFailed  = "failed"
+)
+
+// Observer is told how the transactions and the transfers of the store went, metrics.Metrics implements it
+type Observer interface {
+	// ObserveTx is called after every transaction with its duration
+	ObserveTx(duration time.Duration, err error)
+	// ObserveTransfer is called after every transfer with its outcome, the currency and the amount are only set when it succeeded
+	ObserveTransfer(outcome string, currency string, amount int64)
+}
+
+// WithObserver reports the transactions and the transfers of the store to the observer
+func WithObserver(observer Observer) StoreOption {
+	return func(store *Store) {
+		store.observer = observer
+	}
+}
+
+type nopObserver struct{}
+
+func (nopObserver) ObserveTx(time.Duration, error) {}
+
+func (nopObserver) ObserveTransfer(string, string, int64) {}
+
+// observeTransfer reports the outcome of a transfer once its transaction is over
+func (store *Store) observeTransfer(result TransferTxResult, err error) {
+	outcome := transferOutcome(err)
+	if outcome == TransferSucceeded {
+		store.observer.ObserveTransfer(outcome, result.FromAccount.Currency, result.Transfer.Amount)
+	} else {
+		store.observer.ObserveTransfer(outcome, "", 0)
+	}
+}
+
+func transferOutcome(err error) string {
+	if err == nil {
+		return TransferSucceeded
+	}
+	if errors.Is(err, ErrApprovalRequired) {
+		return TransferApprovalRequired
+	}
+
+	for _, denied := range []error{
+		ErrNotAccountHolder,
+		ErrViewerCannotTransfer,
+		ErrTransferLimitExceeded,
+		ErrKYCNotVerified,
+		ErrAccountFrozen,
+		ErrSelfApproval,
+		ErrNoReviewer,
+	} {
+		if errors.Is(err, denied) {
+			return TransferDenied
+		}
+	}
+
+	for _, invalid := range []error{
+		ErrReferenceTooLong,
+		ErrDescriptionTooLong,
+		ErrInvalidMetadata,
+		ErrMetadataTooLarge,
+	} {
+		if errors.Is(err, invalid) {
+			return TransferInvalid
+		}
+	}
+
+	return TransferFailed
+}
@@ -0,0 +1,72 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// recordingObserver keeps what the store reports
+type recordingObserver struct {
+	mu        sync.Mutex
+	txs       int
+	outcomes  []string
+	amounts   map[string]int64
+	lastTxErr error
+}
+
+func (observer *recordingObserver) ObserveTx(duration time.Duration, err error) {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.txs++
+	observer.lastTxErr = err
+}
+
+func (observer *recordingObserver) ObserveTransfer(outcome string, currency string, amount int64) {
+	observer.mu.Lock()
+	defer observer.mu.Unlock()
+	observer.outcomes = append(observer.outcomes, outcome)
+	if currency != "" {
+		observer.amounts[currency] += amount
+	}
+}
+
+func TestStore_ObserveTransfers(t *testing.T) {
+	observer := &recordingObserver{amounts: map[string]int64{}}
+	store := NewStore(testDB, WithObserver(observer))
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	_, err := store.TransferTX(context.Background(), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	require.NoError(t, err)
+
+	_, err = store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+		Metadata:      []byte("[]"),
+	})
+	require.ErrorIs(t, err, ErrInvalidMetadata)
+
+	_, err = store.TransferTX(WithActor(context.Background(), "not-a-holder"), TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	require.ErrorIs(t, err, ErrNotAccountHolder)
+
+	require.Equal(t, []string{TransferSucceeded, TransferInvalid, TransferDenied}, observer.outcomes)
+	require.Equal(t, map[string]int64{"USD": 10}, observer.amounts)
+	require.Equal(t, 3, observer.txs)
+	require.ErrorIs(t, observer.lastTxErr, ErrNotAccountHolder)
+}
+
+func TestTransferOutcome(t *testing.T) {
+	require.Equal(t, TransferSucceeded, transferOutcome(nil))
+	require.Equal(t, TransferApprovalRequired, transferOutcome(ErrApprovalRequired))
+	require.Equal(t, TransferDenied, transferOutcome(fmt.Errorf("account 1: %w", ErrKYCNotVerified)))
+	require.Equal(t, TransferDenied, transferOutcome(fmt.Errorf("%w: 1", ErrAccountFrozen)))
+	require.Equal(t, TransferInvalid, transferOutcome(ErrReferenceTooLong))
+	require.Equal(t, TransferFailed, transferOutcome(errors.New("connection reset")))
+}
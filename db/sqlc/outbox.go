@@ -0,0 +1,187 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// Event types written to the outbox
+const (
+	// EventTransferCreated has the transfer ID as aggregate ID and a TransferCreatedPayload
+	EventTransferCreated = "TransferCreated"
+	// EventAccountCredited has the account ID as aggregate ID and a BalanceChangedPayload
+	EventAccountCredited = "AccountCredited"
+	// EventAccountDebited has the account ID as aggregate ID and a BalanceChangedPayload
+	EventAccountDebited = "AccountDebited"
+)
+
+// OutboxChannel is the Postgres channel notified of every outbox event when its transaction commits
+const OutboxChannel = "outbox_events"
+
+// OutboxNotification is the payload of the OutboxChannel notifications
+type OutboxNotification struct {
+	ID          int64  `json:"id"`
+	EventType   string `json:"event_type"`
+	AggregateID int64  `json:"aggregate_id"`
+}
+
+type TransferCreatedPayload struct {
+	Transfer Transfer `json:"transfer"`
+}
+
+// BalanceChangedPayload describes an entry and the balance of its account right after it
+type BalanceChangedPayload struct {
+	AccountID int64  `json:"account_id"`
+	EntryID   int64  `json:"entry_id"`
+	Amount    int64  `json:"amount"`
+	Balance   int64  `json:"balance"`
+	Currency  string `json:"currency"`
+	// TransferID is zero when the entry doesn't belong to a transfer
+	TransferID int64 `json:"transfer_id,omitempty"`
+}
+
+// OutboxClaimTTL is how long a relay has to publish the events it claimed before other relays can claim them again
+const OutboxClaimTTL = time.Minute
+
+// RelayOutbox publishes up to limit pending outbox events in ID order and marks them delivered.
+// The events are claimed for OutboxClaimTTL by a single statement and published outside of any transaction,
+// so concurrent relays skip them and a slow publisher holds no lock nor connection.
+// It stops at the first publish failure: the events published before it are still marked delivered,
+// and the others are released for the next call. It returns how many events were delivered.
+func (store *Store) RelayOutbox(
+	ctx context.Context,
+	limit int32,
+	publish func(ctx context.Context, event Outbox) error,
+) (int, error) {
+	now := time.Now()
+	events, err := store.ClaimOutboxEvents(ctx, ClaimOutboxEventsParams{
+		ClaimedUntil: now.Add(OutboxClaimTTL),
+		Now:          now,
+		MaxEvents:    limit,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// RETURNING doesn't keep the order of the subquery
+	sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+	for i, event := range events {
+		if err := publish(ctx, event); err != nil {
+			return i, errors.Join(err, store.releaseOutboxEvents(events[i:]))
+		}
+
+		if err := store.MarkOutboxEventDelivered(ctx, event.ID); err != nil {
+			return i, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// releaseOutboxEvents lets the next relay call claim the events right away, instead of after their claim expired
+func (store *Store) releaseOutboxEvents(events []Outbox) error {
+	ids := make([]int64, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+
+	// the claims are released even when the publish failed because the context is done
+	return store.ReleaseOutboxEvents(context.Background(), ids)
+}
+
+func createNewOutboxEvent(
+	q *Queries,
+	ctx context.Context,
+	eventType string,
+	aggregateId int64,
+	payload interface{},
+) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	event, err := q.CreateOutboxEvent(ctx, CreateOutboxEventParams{
+		EventType:   eventType,
+		AggregateID: aggregateId,
+		Payload:     data,
+	})
+	if err != nil {
+		return err
+	}
+
+	notification, err := json.Marshal(OutboxNotification{
+		ID:          event.ID,
+		EventType:   event.EventType,
+		AggregateID: event.AggregateID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return q.NotifyOutboxEvent(ctx, string(notification))
+}
+
+// createBalanceChangedEvent writes an AccountDebited or AccountCredited event, depending on the sign of the entry
+func createBalanceChangedEvent(
+	q *Queries,
+	ctx context.Context,
+	entry Entry,
+	balance int64,
+	currency string,
+	transferId int64,
+) error {
+	eventType := EventAccountCredited
+	if entry.Amount < 0 {
+		eventType = EventAccountDebited
+	}
+
+	return createNewOutboxEvent(q, ctx, eventType, entry.AccountID, BalanceChangedPayload{
+		AccountID:  entry.AccountID,
+		EntryID:    entry.ID,
+		Amount:     entry.Amount,
+		Balance:    balance,
+		Currency:   currency,
+		TransferID: transferId,
+	})
+}
+
+// createTransferEvents writes the events of a transfer.
+// The balances of the sender events are computed backwards from its final balance,
+// so every event carries the balance right after its own entry.
+func createTransferEvents(q *Queries, ctx context.Context, result TransferTxResult) error {
+	err := createNewOutboxEvent(q, ctx, EventTransferCreated, result.Transfer.ID, TransferCreatedPayload{
+		Transfer: result.Transfer,
+	})
+	if err != nil {
+		return err
+	}
+
+	transferId := result.Transfer.ID
+	fromBalance := result.FromAccount.Balance - result.FeeEntry.Amount
+
+	err = createBalanceChangedEvent(q, ctx, result.FromEntry, fromBalance, result.FromAccount.Currency, transferId)
+	if err != nil {
+		return err
+	}
+
+	err = createBalanceChangedEvent(q, ctx, result.ToEntry, result.ToAccount.Balance, result.ToAccount.Currency, transferId)
+	if err != nil {
+		return err
+	}
+
+	if result.Fee == 0 {
+		return nil
+	}
+
+	err = createBalanceChangedEvent(q, ctx, result.FeeEntry, result.FromAccount.Balance, result.FromAccount.Currency, transferId)
+	if err != nil {
+		return err
+	}
+
+	return createBalanceChangedEvent(q, ctx, result.RevenueEntry, result.RevenueAccount.Balance, result.RevenueAccount.Currency, transferId)
+}
@@ -0,0 +1,188 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_TransferTXWritesOutboxEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	transferEvents, err := store.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateID: result.Transfer.ID,
+		EventTypes:  []string{EventTransferCreated},
+	})
+	require.NoError(t, err)
+	require.Len(t, transferEvents, 1)
+
+	var created TransferCreatedPayload
+	require.NoError(t, json.Unmarshal(transferEvents[0].Payload, &created))
+	require.Equal(t, result.Transfer.ID, created.Transfer.ID)
+
+	debited, err := store.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateID: account1.ID,
+		EventTypes:  []string{EventAccountDebited},
+	})
+	require.NoError(t, err)
+	require.Len(t, debited, 1)
+
+	var payload BalanceChangedPayload
+	require.NoError(t, json.Unmarshal(debited[0].Payload, &payload))
+	require.Equal(t, result.FromEntry.ID, payload.EntryID)
+	require.Equal(t, int64(-10), payload.Amount)
+	require.Equal(t, account1.Balance-10, payload.Balance)
+	require.Equal(t, result.Transfer.ID, payload.TransferID)
+
+	credited, err := store.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateID: account2.ID,
+		EventTypes:  []string{EventAccountCredited},
+	})
+	require.NoError(t, err)
+	require.Len(t, credited, 1)
+}
+
+func TestStore_RelayOutbox(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	result, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	published := relayAllOutbox(t, store)
+
+	events, err := store.ListOutboxEventsByAggregate(context.Background(), ListOutboxEventsByAggregateParams{
+		AggregateID: result.Transfer.ID,
+		EventTypes:  []string{EventTransferCreated},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.True(t, events[0].DeliveredAt.Valid)
+	require.True(t, published[events[0].ID])
+}
+
+func TestStore_RelayOutboxClaims(t *testing.T) {
+	store := NewStore(testDB)
+	relayAllOutbox(t, store)
+
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+
+	_, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	// a failed publish releases the claimed events
+	errUnavailable := errors.New("broker unavailable")
+	delivered, err := store.RelayOutbox(context.Background(), 100, func(ctx context.Context, event Outbox) error {
+		return errUnavailable
+	})
+	require.ErrorIs(t, err, errUnavailable)
+	require.Zero(t, delivered)
+
+	// while the events are published, another relay finds nothing to claim
+	checked := false
+	delivered, err = store.RelayOutbox(context.Background(), 100, func(ctx context.Context, event Outbox) error {
+		if !checked {
+			checked = true
+			other, err := store.RelayOutbox(ctx, 100, func(ctx context.Context, event Outbox) error {
+				return fmt.Errorf("event %d published by two relays", event.ID)
+			})
+			require.NoError(t, err)
+			require.Zero(t, other)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, delivered)
+}
+
+// relayAllOutbox publishes every pending outbox event and returns their IDs
+func relayAllOutbox(t *testing.T, store *Store) map[int64]bool {
+	published := make(map[int64]bool)
+	for {
+		delivered, err := store.RelayOutbox(context.Background(), 100, func(ctx context.Context, event Outbox) error {
+			published[event.ID] = true
+			return nil
+		})
+		require.NoError(t, err)
+		if delivered == 0 {
+			return published
+		}
+	}
+}
+
+func TestListAccountEventsAfterCommittedOutOfIDOrder(t *testing.T) {
+	ctx := context.Background()
+	account := createRandomAccount(t)
+
+	horizon, err := testQueries.GetOutboxHorizon(ctx)
+	require.NoError(t, err)
+
+	insert := func(tx *sql.Tx) Outbox {
+		event, err := New(tx).CreateOutboxEvent(ctx, CreateOutboxEventParams{
+			EventType:   EventAccountCredited,
+			AggregateID: account.ID,
+			Payload:     json.RawMessage(`{}`),
+		})
+		require.NoError(t, err)
+		return event
+	}
+
+	// the first transaction inserts its event first but commits after the second one
+	first, err := testDB.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	defer first.Rollback()
+	slow := insert(first)
+
+	second, err := testDB.BeginTx(ctx, nil)
+	require.NoError(t, err)
+	fast := insert(second)
+	require.NoError(t, second.Commit())
+	require.Greater(t, fast.ID, slow.ID)
+
+	list := func() []int64 {
+		events, err := testQueries.ListAccountEventsAfter(ctx, ListAccountEventsAfterParams{
+			AfterXactID: horizon,
+			AccountIds:  []int64{account.ID},
+			MaxEvents:   10,
+		})
+		require.NoError(t, err)
+
+		var ids []int64
+		for _, event := range events {
+			ids = append(ids, event.ID)
+		}
+		return ids
+	}
+
+	// the committed event waits for the transaction started before it, so a cursor can't move past the slow event
+	require.Empty(t, list())
+
+	require.NoError(t, first.Commit())
+	require.Equal(t, []int64{slow.ID, fast.ID}, list())
+}
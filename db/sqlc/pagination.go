@@ -0,0 +1,237 @@
+package db
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SortOrder is the order of a page, by creation time and then by ID
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var (
+	ErrInvalidCursor    = errors.New("invalid cursor")
+	ErrInvalidPageSize  = fmt.Errorf("page size must be between 1 and %d", MaxPageSize)
+	ErrInvalidSortOrder = errors.New("sort order must be asc or desc")
+)
+
+// Cursor is the position of the last row of a page
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+// EncodeCursor returns the opaque form of the cursor handed to the clients
+func EncodeCursor(cursor Cursor) string {
+	raw := strconv.FormatInt(cursor.CreatedAt.UnixMicro(), 10) + "," + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a cursor returned by EncodeCursor
+func DecodeCursor(encoded string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	micros, id, ok := strings.Cut(string(raw), ",")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	createdAt, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	cursor := Cursor{CreatedAt: time.UnixMicro(createdAt).UTC()}
+	cursor.ID, err = strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return cursor, nil
+}
+
+// PageParams selects a page of a list.
+// An empty Cursor starts from the first row, a zero Limit means DefaultPageSize and an empty Order means SortAsc.
+type PageParams struct {
+	Cursor string    `json:"cursor"`
+	Limit  int32     `json:"limit"`
+	Order  SortOrder `json:"order"`
+}
+
+// start validates the params and returns the cursor to resume after
+func (params *PageParams) start() (Cursor, error) {
+	if params.Limit == 0 {
+		params.Limit = DefaultPageSize
+	}
+	if params.Limit < 1 || params.Limit > MaxPageSize {
+		return Cursor{}, ErrInvalidPageSize
+	}
+
+	if params.Order == "" {
+		params.Order = SortAsc
+	}
+	if params.Order != SortAsc && params.Order != SortDesc {
+		return Cursor{}, ErrInvalidSortOrder
+	}
+
+	if params.Cursor != "" {
+		return DecodeCursor(params.Cursor)
+	}
+
+	// before the first row of the order
+	if params.Order == SortAsc {
+		return Cursor{CreatedAt: time.Unix(0, 0).UTC(), ID: 0}, nil
+	}
+	return Cursor{CreatedAt: time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC), ID: math.MaxInt64}, nil
+}
+
+// nextPage trims the extra row fetched to know if there is a next page and returns the cursor of that page
+func nextPage[T any](rows []T, limit int32, cursor func(row T) Cursor) ([]T, string) {
+	if len(rows) <= int(limit) {
+		return rows, ""
+	}
+
+	rows = rows[:limit]
+	return rows, EncodeCursor(cursor(rows[len(rows)-1]))
+}
+
+type AccountsPage struct {
+	Accounts []Account `json:"accounts"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// ListAccountsPage returns a page of the accounts the user owns or holds
+func (store *Store) ListAccountsPage(ctx context.Context, holder string, params PageParams) (AccountsPage, error) {
+	var page AccountsPage
+
+	cursor, err := params.start()
+	if err != nil {
+		return page, err
+	}
+
+	var accounts []Account
+	if params.Order == SortAsc {
+		accounts, err = store.ListAccountsAsc(ctx, ListAccountsAscParams{
+			Holder:          holder,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	} else {
+		accounts, err = store.ListAccountsDesc(ctx, ListAccountsDescParams{
+			Holder:          holder,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	}
+	if err != nil {
+		return page, err
+	}
+
+	page.Accounts, page.NextCursor = nextPage(accounts, params.Limit, func(account Account) Cursor {
+		return Cursor{CreatedAt: account.CreatedAt, ID: account.ID}
+	})
+	return page, nil
+}
+
+type EntriesPage struct {
+	Entries []Entry `json:"entries"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// ListEntriesPage returns a page of the entries of the account
+func (store *Store) ListEntriesPage(ctx context.Context, accountId int64, params PageParams) (EntriesPage, error) {
+	var page EntriesPage
+
+	cursor, err := params.start()
+	if err != nil {
+		return page, err
+	}
+
+	var entries []Entry
+	if params.Order == SortAsc {
+		entries, err = store.ListEntriesAsc(ctx, ListEntriesAscParams{
+			AccountID:       accountId,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	} else {
+		entries, err = store.ListEntriesDesc(ctx, ListEntriesDescParams{
+			AccountID:       accountId,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	}
+	if err != nil {
+		return page, err
+	}
+
+	page.Entries, page.NextCursor = nextPage(entries, params.Limit, func(entry Entry) Cursor {
+		return Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+	})
+	return page, nil
+}
+
+type TransfersPage struct {
+	Transfers []Transfer `json:"transfers"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"next_cursor"`
+}
+
+// ListTransfersPage returns a page of the transfers from or to the account
+func (store *Store) ListTransfersPage(ctx context.Context, accountId int64, params PageParams) (TransfersPage, error) {
+	var page TransfersPage
+
+	cursor, err := params.start()
+	if err != nil {
+		return page, err
+	}
+
+	var transfers []Transfer
+	if params.Order == SortAsc {
+		transfers, err = store.ListTransfersAsc(ctx, ListTransfersAscParams{
+			AccountID:       accountId,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	} else {
+		transfers, err = store.ListTransfersDesc(ctx, ListTransfersDescParams{
+			AccountID:       accountId,
+			CursorCreatedAt: cursor.CreatedAt,
+			CursorID:        cursor.ID,
+			MaxRows:         params.Limit + 1,
+		})
+	}
+	if err != nil {
+		return page, err
+	}
+
+	page.Transfers, page.NextCursor = nextPage(transfers, params.Limit, func(transfer Transfer) Cursor {
+		return Cursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
+	})
+	return page, nil
+}
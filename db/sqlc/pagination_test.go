@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestCursor(t *testing.T) {
+	cursor := Cursor{CreatedAt: time.Date(2023, 5, 1, 10, 0, 0, 123456000, time.UTC), ID: 42}
+
+	decoded, err := DecodeCursor(EncodeCursor(cursor))
+	require.NoError(t, err)
+	require.Equal(t, cursor, decoded)
+
+	for _, encoded := range []string{"not base64!", "MTIz", "YSxi", "MTIzLGI"} {
+		_, err = DecodeCursor(encoded)
+		require.ErrorIs(t, err, ErrInvalidCursor, encoded)
+	}
+}
+
+func TestPageParams_start(t *testing.T) {
+	params := PageParams{}
+	cursor, err := params.start()
+	require.NoError(t, err)
+	require.Equal(t, int32(DefaultPageSize), params.Limit)
+	require.Equal(t, SortAsc, params.Order)
+	require.Zero(t, cursor.ID)
+
+	params = PageParams{Order: SortDesc}
+	cursor, err = params.start()
+	require.NoError(t, err)
+	require.True(t, cursor.CreatedAt.After(time.Now()))
+
+	params = PageParams{Limit: MaxPageSize + 1}
+	_, err = params.start()
+	require.ErrorIs(t, err, ErrInvalidPageSize)
+
+	params = PageParams{Order: "up"}
+	_, err = params.start()
+	require.ErrorIs(t, err, ErrInvalidSortOrder)
+
+	params = PageParams{Cursor: "@@"}
+	_, err = params.start()
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNextPage(t *testing.T) {
+	key := func(id int64) Cursor { return Cursor{CreatedAt: time.Unix(id, 0), ID: id} }
+
+	rows, next := nextPage([]int64{1, 2}, 2, key)
+	require.Equal(t, []int64{1, 2}, rows)
+	require.Empty(t, next)
+
+	rows, next = nextPage([]int64{1, 2, 3}, 2, key)
+	require.Equal(t, []int64{1, 2}, rows)
+
+	cursor, err := DecodeCursor(next)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), cursor.ID)
+}
+
+func TestStore_ListEntriesPage(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+
+	var created []int64
+	for i := 0; i < 5; i++ {
+		entry, err := testQueries.CreateEntry(context.Background(), CreateEntryParams{
+			AccountID: account.ID,
+			Amount:    util.RandomMoney(),
+		})
+		require.NoError(t, err)
+		created = append(created, entry.ID)
+	}
+
+	for _, order := range []SortOrder{SortAsc, SortDesc} {
+		var listed []int64
+		params := PageParams{Limit: 2, Order: order}
+		pages := 0
+
+		for {
+			page, err := store.ListEntriesPage(context.Background(), account.ID, params)
+			require.NoError(t, err)
+			pages++
+
+			for _, entry := range page.Entries {
+				listed = append(listed, entry.ID)
+			}
+			if page.NextCursor == "" {
+				break
+			}
+			params.Cursor = page.NextCursor
+		}
+
+		require.Equal(t, 3, pages)
+		if order == SortDesc {
+			for i, j := 0, len(listed)-1; i < j; i, j = i+1, j-1 {
+				listed[i], listed[j] = listed[j], listed[i]
+			}
+		}
+		require.Equal(t, created, listed)
+	}
+}
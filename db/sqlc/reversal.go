@@ -0,0 +1,138 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+var (
+	ErrNoReversalReason        = errors.New("reversal reason is required")
+	ErrNoReversalActor         = errors.New("reversal actor is required")
+	ErrTransferAlreadyReversed = errors.New("the transfer is already reversed")
+	ErrReverseReversal         = errors.New("a reversal can't be reversed")
+)
+
+type ReverseTransferResult struct {
+	// Reversal links the reversed transfer to the transfer moving its amount back
+	Reversal TransferReversal `json:"reversal"`
+	// Transfer moves the amount back from the receiver to the sender of the reversed transfer
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// ReverseTransfer moves the amount of a transfer back from its receiver to its sender, with a new transfer
+// linked to the reversed one. The fee of the reversed transfer is not refunded.
+// A transfer is reversed at most once and a reversal can't be reversed. The reversal is a correction of the bank:
+// it's booked on frozen accounts too, without the checks of the roles, and recorded with its reason and actor.
+func (store *Store) ReverseTransfer(
+	ctx context.Context,
+	transferId int64,
+	reason string,
+	actor string,
+) (ReverseTransferResult, error) {
+	var result ReverseTransferResult
+
+	reason = strings.TrimSpace(reason)
+	switch {
+	case reason == "":
+		return result, ErrNoReversalReason
+	case actor == "":
+		return result, ErrNoReversalActor
+	}
+
+	ctx = WithActor(ctx, actor)
+
+	txCtx := withTxTrace(ctx, "ReverseTransfer", attribute.Int64("bank.transfer_id", transferId))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		original, err := q.GetTransfer(ctx, transferId)
+		if err != nil {
+			return err
+		}
+
+		err = checkReversible(q, ctx, transferId)
+		if err != nil {
+			return err
+		}
+
+		params := TransferTxParams{
+			FromAccountId: original.ToAccountID,
+			ToAccountId:   original.FromAccountID,
+			Amount:        original.Amount,
+			Reference:     fmt.Sprintf("Reversal of transfer %d", transferId),
+			Description:   reason,
+			Metadata:      []byte(fmt.Sprintf(`{"reversal_of": %d}`, transferId)),
+		}
+		params.Metadata, err = validateTransferDetails(params)
+		if err != nil {
+			return err
+		}
+
+		transfer := &result.Transfer
+		transfer.Transfer, err = createNewTransfer(q, ctx, params, 0)
+		if err != nil {
+			return err
+		}
+
+		transfer.FromEntry, err = createTransferEntry(q, ctx, params.FromAccountId, -params.Amount, transfer.Transfer.ID)
+		if err != nil {
+			return err
+		}
+
+		transfer.ToEntry, err = createTransferEntry(q, ctx, params.ToAccountId, params.Amount, transfer.Transfer.ID)
+		if err != nil {
+			return err
+		}
+
+		err = updateToAndFromAccountsBalance(q, params, transfer, ctx)
+		if err != nil {
+			return err
+		}
+
+		err = createTransferEvents(q, ctx, *transfer)
+		if err != nil {
+			return err
+		}
+
+		result.Reversal, err = q.CreateTransferReversal(ctx, CreateTransferReversalParams{
+			TransferID: transferId,
+			ReversalID: transfer.Transfer.ID,
+			Reason:     reason,
+			Actor:      actor,
+		})
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Constraint == "transfer_reversals_transfer_id_key" {
+			return fmt.Errorf("%w: %d", ErrTransferAlreadyReversed, transferId)
+		}
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditTransferCreate, AuditEntityTransfer, transfer.Transfer.ID, nil, transfer.Transfer)
+		store.audit(q, AuditTransferReverse, AuditEntityTransfer, transferId, original, result.Reversal)
+		store.auditEntries(q, transfer.FromEntry, transfer.ToEntry)
+		return nil
+	})
+
+	return result, err
+}
+
+// checkReversible returns an error when the transfer is already reversed or is itself a reversal.
+// Two reversals racing past it are caught by the unique reversed transfer of transfer_reversals.
+func checkReversible(q *Queries, ctx context.Context, transferId int64) error {
+	reversal, err := q.GetTransferReversal(ctx, transferId)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return nil
+	case err != nil:
+		return err
+	case reversal.ReversalID == transferId:
+		return fmt.Errorf("%w: transfer %d reverses transfer %d", ErrReverseReversal, transferId, reversal.TransferID)
+	default:
+		return fmt.Errorf("%w: %d by transfer %d", ErrTransferAlreadyReversed, transferId, reversal.ReversalID)
+	}
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ReverseTransfer(t *testing.T) {
+	revenue := createRandomAccountWithCurrency(t, "EUR")
+	account1 := createRandomAccountWithCurrency(t, "EUR")
+	account2 := createRandomAccountWithCurrency(t, "EUR")
+	ctx := context.Background()
+
+	store := NewStore(testDB, WithFeeSchedule(FeeSchedule{
+		Rules:           map[string]FeeRule{"EUR": {Flat: 5}},
+		RevenueAccounts: map[string]int64{"EUR": revenue.ID},
+	}))
+
+	original, err := store.TransferTX(ctx, TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 100})
+	require.NoError(t, err)
+
+	// an account frozen after the transfer is still reversed
+	_, err = store.FreezeAccount(ctx, account2.ID)
+	require.NoError(t, err)
+
+	result, err := store.ReverseTransfer(ctx, original.Transfer.ID, " paid to the wrong account ", "ops-alice")
+	require.NoError(t, err)
+
+	reversal := result.Transfer.Transfer
+	require.Equal(t, account2.ID, reversal.FromAccountID)
+	require.Equal(t, account1.ID, reversal.ToAccountID)
+	require.Equal(t, int64(100), reversal.Amount)
+	require.Zero(t, reversal.Fee)
+	require.Equal(t, fmt.Sprintf("Reversal of transfer %d", original.Transfer.ID), reversal.Reference)
+	require.Equal(t, "paid to the wrong account", reversal.Description)
+	require.JSONEq(t, fmt.Sprintf(`{"reversal_of": %d}`, original.Transfer.ID), string(reversal.Metadata))
+
+	require.Equal(t, original.Transfer.ID, result.Reversal.TransferID)
+	require.Equal(t, reversal.ID, result.Reversal.ReversalID)
+	require.Equal(t, "ops-alice", result.Reversal.Actor)
+
+	// the fee is not refunded
+	require.Equal(t, account1.Balance-5, result.Transfer.ToAccount.Balance)
+	require.Equal(t, account2.Balance, result.Transfer.FromAccount.Balance)
+
+	logs, err := store.ListAuditLogByEntity(ctx, ListAuditLogByEntityParams{EntityType: AuditEntityTransfer, EntityID: original.Transfer.ID})
+	require.NoError(t, err)
+	require.Len(t, logs, 2)
+	require.Equal(t, AuditTransferReverse, logs[1].Action)
+	require.Equal(t, "ops-alice", logs[1].Actor)
+
+	var before Transfer
+	require.NoError(t, json.Unmarshal([]byte(logs[1].Before), &before))
+	require.Equal(t, original.Transfer.ID, before.ID)
+
+	_, err = store.ReverseTransfer(ctx, original.Transfer.ID, "again", "ops-alice")
+	require.ErrorIs(t, err, ErrTransferAlreadyReversed)
+
+	_, err = store.ReverseTransfer(ctx, reversal.ID, "undo the reversal", "ops-alice")
+	require.ErrorIs(t, err, ErrReverseReversal)
+}
+
+func TestStore_ReverseTransferInvalid(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	_, err := store.ReverseTransfer(ctx, 1, " ", "ops")
+	require.ErrorIs(t, err, ErrNoReversalReason)
+
+	_, err = store.ReverseTransfer(ctx, 1, "reason", "")
+	require.ErrorIs(t, err, ErrNoReversalActor)
+
+	_, err = store.ReverseTransfer(ctx, -1, "reason", "ops")
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
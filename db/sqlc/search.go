@@ -0,0 +1,315 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Direction filters the money movements of an account
+type Direction string
+
+const (
+	DirectionIncoming Direction = "incoming"
+	DirectionOutgoing Direction = "outgoing"
+	DirectionBoth     Direction = "both"
+)
+
+var (
+	ErrSearchNoAccount     = errors.New("search needs an account id")
+	ErrInvalidDirection    = errors.New("direction must be incoming, outgoing or both")
+	ErrInvalidAmountRange  = errors.New("min amount must not be greater than max amount")
+	ErrInvalidDateRange    = errors.New("from must be before to")
+	ErrCounterpartyAccount = errors.New("counterparty must be another account")
+)
+
+// SearchTransfersParams filters the transfers of an account, the zero value of a filter disables it
+type SearchTransfersParams struct {
+	AccountID int64     `json:"account_id"`
+	Direction Direction `json:"direction"`
+	MinAmount int64     `json:"min_amount"`
+	MaxAmount int64     `json:"max_amount"`
+	// From is inclusive and To is exclusive
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	// CounterpartyID is the other account of the transfer
+	CounterpartyID int64 `json:"counterparty_id"`
+	// Reference matches the transfers whose reference contains it, ignoring the case
+	Reference string `json:"reference"`
+	// Description matches the transfers whose description contains it, ignoring the case
+	Description string `json:"description"`
+	// Metadata matches the transfers whose metadata contains this JSON object
+	Metadata json.RawMessage `json:"metadata"`
+	PageParams
+}
+
+// SearchEntriesParams filters the entries of an account, the zero value of a filter disables it
+type SearchEntriesParams struct {
+	AccountID int64     `json:"account_id"`
+	Direction Direction `json:"direction"`
+	// MinAmount and MaxAmount bound the absolute value of the entry amount
+	MinAmount int64 `json:"min_amount"`
+	MaxAmount int64 `json:"max_amount"`
+	// From is inclusive and To is exclusive
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+	PageParams
+}
+
+// SearchTransfers returns a page of the transfers of an account matching every filter of the params
+func (store *Store) SearchTransfers(ctx context.Context, params SearchTransfersParams) (TransfersPage, error) {
+	var page TransfersPage
+
+	cursor, err := params.start()
+	if err != nil {
+		return page, err
+	}
+
+	query, args, err := buildTransferSearch(params, cursor)
+	if err != nil {
+		return page, err
+	}
+
+	rows, err := store.Queries.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Reference,
+			&i.Description,
+			&i.Metadata,
+		); err != nil {
+			return page, err
+		}
+		transfers = append(transfers, i)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	page.Transfers, page.NextCursor = nextPage(transfers, params.Limit, func(transfer Transfer) Cursor {
+		return Cursor{CreatedAt: transfer.CreatedAt, ID: transfer.ID}
+	})
+	return page, nil
+}
+
+// SearchEntries returns a page of the entries of an account matching every filter of the params
+func (store *Store) SearchEntries(ctx context.Context, params SearchEntriesParams) (EntriesPage, error) {
+	var page EntriesPage
+
+	cursor, err := params.start()
+	if err != nil {
+		return page, err
+	}
+
+	query, args, err := buildEntrySearch(params, cursor)
+	if err != nil {
+		return page, err
+	}
+
+	rows, err := store.Queries.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return page, err
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var i Entry
+		if err := rows.Scan(
+			&i.ID,
+			&i.AccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.TransferID,
+			&i.MultiTransferID,
+		); err != nil {
+			return page, err
+		}
+		entries = append(entries, i)
+	}
+	if err := rows.Err(); err != nil {
+		return page, err
+	}
+
+	page.Entries, page.NextCursor = nextPage(entries, params.Limit, func(entry Entry) Cursor {
+		return Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+	})
+	return page, nil
+}
+
+// searchQuery builds a WHERE clause where every value is passed as a placeholder argument,
+// the conditions themselves never contain user input
+type searchQuery struct {
+	conditions []string
+	args       []interface{}
+}
+
+// arg adds a value to the arguments and returns its placeholder
+func (query *searchQuery) arg(value interface{}) string {
+	query.args = append(query.args, value)
+	return "$" + strconv.Itoa(len(query.args))
+}
+
+func (query *searchQuery) where(condition string) {
+	query.conditions = append(query.conditions, condition)
+}
+
+// page adds the keyset condition of the cursor and returns the ORDER BY and LIMIT clauses
+func (query *searchQuery) page(params PageParams, cursor Cursor) string {
+	operator, order := ">", "ASC"
+	if params.Order == SortDesc {
+		operator, order = "<", "DESC"
+	}
+
+	query.where(fmt.Sprintf("(created_at, id) %s (%s::timestamptz, %s::bigint)", operator, query.arg(cursor.CreatedAt), query.arg(cursor.ID)))
+
+	return fmt.Sprintf("ORDER BY created_at %s, id %s\nLIMIT %s", order, order, query.arg(params.Limit+1))
+}
+
+func (query *searchQuery) sql(selectFrom string, orderLimit string) string {
+	return selectFrom + "\nWHERE " + strings.Join(query.conditions, "\n    AND ") + "\n" + orderLimit
+}
+
+func buildTransferSearch(params SearchTransfersParams, cursor Cursor) (string, []interface{}, error) {
+	var query searchQuery
+
+	err := validateSearch(params.AccountID, &params.Direction, params.MinAmount, params.MaxAmount, params.From, params.To)
+	if err != nil {
+		return "", nil, err
+	}
+	if params.CounterpartyID < 0 || params.CounterpartyID == params.AccountID {
+		return "", nil, ErrCounterpartyAccount
+	}
+
+	account := query.arg(params.AccountID)
+	switch params.Direction {
+	case DirectionOutgoing:
+		query.where("from_account_id = " + account)
+		if params.CounterpartyID != 0 {
+			query.where("to_account_id = " + query.arg(params.CounterpartyID))
+		}
+	case DirectionIncoming:
+		query.where("to_account_id = " + account)
+		if params.CounterpartyID != 0 {
+			query.where("from_account_id = " + query.arg(params.CounterpartyID))
+		}
+	default:
+		if params.CounterpartyID != 0 {
+			counterparty := query.arg(params.CounterpartyID)
+			query.where(fmt.Sprintf("((from_account_id = %s AND to_account_id = %s) OR (from_account_id = %s AND to_account_id = %s))",
+				account, counterparty, counterparty, account))
+		} else {
+			query.where(fmt.Sprintf("(from_account_id = %s OR to_account_id = %s)", account, account))
+		}
+	}
+
+	if params.MinAmount != 0 {
+		query.where("amount >= " + query.arg(params.MinAmount))
+	}
+	if params.MaxAmount != 0 {
+		query.where("amount <= " + query.arg(params.MaxAmount))
+	}
+	if !params.From.IsZero() {
+		query.where("created_at >= " + query.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		query.where("created_at < " + query.arg(params.To))
+	}
+	if reference := strings.TrimSpace(params.Reference); reference != "" {
+		query.where("lower(reference) LIKE " + query.arg("%"+escapeLike(strings.ToLower(reference))+"%"))
+	}
+	if description := strings.TrimSpace(params.Description); description != "" {
+		query.where("lower(description) LIKE " + query.arg("%"+escapeLike(strings.ToLower(description))+"%"))
+	}
+	if len(params.Metadata) > 0 {
+		var object map[string]json.RawMessage
+		if json.Unmarshal(params.Metadata, &object) != nil || object == nil {
+			return "", nil, ErrInvalidMetadata
+		}
+		query.where("metadata @> " + query.arg(string(params.Metadata)) + "::jsonb")
+	}
+
+	orderLimit := query.page(params.PageParams, cursor)
+	selectFrom := "SELECT id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata FROM transfers"
+	return query.sql(selectFrom, orderLimit), query.args, nil
+}
+
+func buildEntrySearch(params SearchEntriesParams, cursor Cursor) (string, []interface{}, error) {
+	var query searchQuery
+
+	err := validateSearch(params.AccountID, &params.Direction, params.MinAmount, params.MaxAmount, params.From, params.To)
+	if err != nil {
+		return "", nil, err
+	}
+
+	query.where("account_id = " + query.arg(params.AccountID))
+
+	switch params.Direction {
+	case DirectionIncoming:
+		query.where("amount > 0")
+	case DirectionOutgoing:
+		query.where("amount < 0")
+	}
+
+	if params.MinAmount != 0 {
+		query.where("abs(amount) >= " + query.arg(params.MinAmount))
+	}
+	if params.MaxAmount != 0 {
+		query.where("abs(amount) <= " + query.arg(params.MaxAmount))
+	}
+	if !params.From.IsZero() {
+		query.where("created_at >= " + query.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		query.where("created_at < " + query.arg(params.To))
+	}
+
+	orderLimit := query.page(params.PageParams, cursor)
+	selectFrom := "SELECT id, account_id, amount, created_at, transfer_id, multi_transfer_id FROM entries"
+	return query.sql(selectFrom, orderLimit), query.args, nil
+}
+
+// validateSearch checks the filters shared by the searches and defaults the direction to DirectionBoth
+func validateSearch(accountId int64, direction *Direction, minAmount, maxAmount int64, from, to time.Time) error {
+	if accountId <= 0 {
+		return ErrSearchNoAccount
+	}
+
+	if *direction == "" {
+		*direction = DirectionBoth
+	}
+	if *direction != DirectionIncoming && *direction != DirectionOutgoing && *direction != DirectionBoth {
+		return ErrInvalidDirection
+	}
+
+	if minAmount < 0 || maxAmount < 0 || (maxAmount != 0 && minAmount > maxAmount) {
+		return ErrInvalidAmountRange
+	}
+
+	if !from.IsZero() && !to.IsZero() && !from.Before(to) {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+// escapeLike escapes the LIKE wildcards, so the text is matched literally
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(text)
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildTransferSearch(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name       string
+		params     SearchTransfersParams
+		conditions []string
+		args       []interface{}
+	}{
+		{
+			name:       "Both",
+			params:     SearchTransfersParams{AccountID: 1},
+			conditions: []string{"(from_account_id = $1 OR to_account_id = $1)"},
+			args:       []interface{}{int64(1)},
+		},
+		{
+			name:       "Outgoing",
+			params:     SearchTransfersParams{AccountID: 1, Direction: DirectionOutgoing},
+			conditions: []string{"from_account_id = $1"},
+			args:       []interface{}{int64(1)},
+		},
+		{
+			name:       "IncomingFromCounterparty",
+			params:     SearchTransfersParams{AccountID: 1, Direction: DirectionIncoming, CounterpartyID: 2},
+			conditions: []string{"to_account_id = $1", "from_account_id = $2"},
+			args:       []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:       "BothWithCounterparty",
+			params:     SearchTransfersParams{AccountID: 1, CounterpartyID: 2},
+			conditions: []string{"((from_account_id = $1 AND to_account_id = $2) OR (from_account_id = $2 AND to_account_id = $1))"},
+			args:       []interface{}{int64(1), int64(2)},
+		},
+		{
+			name:       "AmountRange",
+			params:     SearchTransfersParams{AccountID: 1, MinAmount: 10, MaxAmount: 20},
+			conditions: []string{"amount >= $2", "amount <= $3"},
+			args:       []interface{}{int64(1), int64(10), int64(20)},
+		},
+		{
+			name:       "DateRange",
+			params:     SearchTransfersParams{AccountID: 1, From: from, To: to},
+			conditions: []string{"created_at >= $2", "created_at < $3"},
+			args:       []interface{}{int64(1), from, to},
+		},
+		{
+			name:       "Reference",
+			params:     SearchTransfersParams{AccountID: 1, Reference: " Rent 100%_ "},
+			conditions: []string{"lower(reference) LIKE $2"},
+			args:       []interface{}{int64(1), `%rent 100\%\_%`},
+		},
+		{
+			name:       "Description",
+			params:     SearchTransfersParams{AccountID: 1, Description: "Rent"},
+			conditions: []string{"lower(description) LIKE $2"},
+			args:       []interface{}{int64(1), "%rent%"},
+		},
+		{
+			name:       "Metadata",
+			params:     SearchTransfersParams{AccountID: 1, Metadata: json.RawMessage(`{"invoice":"7"}`)},
+			conditions: []string{"metadata @> $2::jsonb"},
+			args:       []interface{}{int64(1), `{"invoice":"7"}`},
+		},
+		{
+			name: "Everything",
+			params: SearchTransfersParams{
+				AccountID:      1,
+				Direction:      DirectionOutgoing,
+				MinAmount:      10,
+				MaxAmount:      20,
+				From:           from,
+				To:             to,
+				CounterpartyID: 2,
+				Reference:      "rent",
+			},
+			conditions: []string{
+				"from_account_id = $1",
+				"to_account_id = $2",
+				"amount >= $3",
+				"amount <= $4",
+				"created_at >= $5",
+				"created_at < $6",
+				"lower(reference) LIKE $7",
+			},
+			args: []interface{}{int64(1), int64(2), int64(10), int64(20), from, to, "%rent%"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params := tc.params
+			cursor, err := params.start()
+			require.NoError(t, err)
+
+			query, args, err := buildTransferSearch(params, cursor)
+			require.NoError(t, err)
+
+			for _, condition := range tc.conditions {
+				require.Contains(t, query, condition)
+			}
+
+			// the keyset condition and the limit always come last
+			n := len(tc.args)
+			require.Contains(t, query, "(created_at, id) > ($"+strconv.Itoa(n+1)+"::timestamptz, $"+strconv.Itoa(n+2)+"::bigint)")
+			require.Contains(t, query, "LIMIT $"+strconv.Itoa(n+3))
+			require.Equal(t, tc.args, args[:n])
+			require.Equal(t, []interface{}{cursor.CreatedAt, cursor.ID, int32(DefaultPageSize + 1)}, args[n:])
+		})
+	}
+}
+
+func TestBuildTransferSearchInvalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		params SearchTransfersParams
+		err    error
+	}{
+		{name: "NoAccount", params: SearchTransfersParams{}, err: ErrSearchNoAccount},
+		{name: "Direction", params: SearchTransfersParams{AccountID: 1, Direction: "sideways"}, err: ErrInvalidDirection},
+		{name: "AmountRange", params: SearchTransfersParams{AccountID: 1, MinAmount: 20, MaxAmount: 10}, err: ErrInvalidAmountRange},
+		{name: "NegativeAmount", params: SearchTransfersParams{AccountID: 1, MinAmount: -1}, err: ErrInvalidAmountRange},
+		{
+			name:   "DateRange",
+			params: SearchTransfersParams{AccountID: 1, From: time.Now(), To: time.Now().Add(-time.Hour)},
+			err:    ErrInvalidDateRange,
+		},
+		{name: "SelfCounterparty", params: SearchTransfersParams{AccountID: 1, CounterpartyID: 1}, err: ErrCounterpartyAccount},
+		{name: "MetadataArray", params: SearchTransfersParams{AccountID: 1, Metadata: json.RawMessage(`[1]`)}, err: ErrInvalidMetadata},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _, err := buildTransferSearch(tc.params, Cursor{})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestBuildEntrySearch(t *testing.T) {
+	params := SearchEntriesParams{AccountID: 1, Direction: DirectionOutgoing, MinAmount: 5, PageParams: PageParams{Order: SortDesc}}
+	cursor, err := params.start()
+	require.NoError(t, err)
+
+	query, args, err := buildEntrySearch(params, cursor)
+	require.NoError(t, err)
+	require.Contains(t, query, "account_id = $1")
+	require.Contains(t, query, "amount < 0")
+	require.Contains(t, query, "abs(amount) >= $2")
+	require.Contains(t, query, "(created_at, id) < ($3::timestamptz, $4::bigint)")
+	require.Contains(t, query, "ORDER BY created_at DESC, id DESC")
+	require.Len(t, args, 5)
+}
+
+func TestStore_SearchTransfers(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account := createRandomAccountWithCurrency(t, "USD")
+	other1 := createRandomAccountWithCurrency(t, "USD")
+	other2 := createRandomAccountWithCurrency(t, "USD")
+
+	transfer := func(from, to Account, amount int64, reference string) Transfer {
+		result, err := store.TransferTX(ctx, TransferTxParams{
+			FromAccountId: from.ID,
+			ToAccountId:   to.ID,
+			Amount:        amount,
+			Reference:     reference,
+		})
+		require.NoError(t, err)
+		return result.Transfer
+	}
+
+	rent := transfer(account, other1, 10, "Rent March")
+	salary := transfer(other2, account, 50, "Salary")
+	refund := transfer(other1, account, 5, "rent refund")
+
+	testCases := []struct {
+		name     string
+		params   SearchTransfersParams
+		expected []Transfer
+	}{
+		{name: "All", params: SearchTransfersParams{AccountID: account.ID}, expected: []Transfer{rent, salary, refund}},
+		{name: "Outgoing", params: SearchTransfersParams{AccountID: account.ID, Direction: DirectionOutgoing}, expected: []Transfer{rent}},
+		{name: "Incoming", params: SearchTransfersParams{AccountID: account.ID, Direction: DirectionIncoming}, expected: []Transfer{salary, refund}},
+		{name: "Counterparty", params: SearchTransfersParams{AccountID: account.ID, CounterpartyID: other1.ID}, expected: []Transfer{rent, refund}},
+		{name: "MinAmount", params: SearchTransfersParams{AccountID: account.ID, MinAmount: 10}, expected: []Transfer{rent, salary}},
+		{name: "AmountRange", params: SearchTransfersParams{AccountID: account.ID, MinAmount: 1, MaxAmount: 10}, expected: []Transfer{rent, refund}},
+		{name: "Reference", params: SearchTransfersParams{AccountID: account.ID, Reference: "RENT"}, expected: []Transfer{rent, refund}},
+		{
+			name:     "ReferenceAndDirection",
+			params:   SearchTransfersParams{AccountID: account.ID, Reference: "rent", Direction: DirectionIncoming},
+			expected: []Transfer{refund},
+		},
+		{
+			name:     "DateRange",
+			params:   SearchTransfersParams{AccountID: account.ID, From: salary.CreatedAt, To: refund.CreatedAt.Add(time.Microsecond)},
+			expected: []Transfer{salary, refund},
+		},
+		{name: "Wildcard", params: SearchTransfersParams{AccountID: account.ID, Reference: "%"}, expected: nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			page, err := store.SearchTransfers(ctx, tc.params)
+			require.NoError(t, err)
+			require.Empty(t, page.NextCursor)
+
+			var ids, expected []int64
+			for _, transfer := range page.Transfers {
+				ids = append(ids, transfer.ID)
+			}
+			for _, transfer := range tc.expected {
+				expected = append(expected, transfer.ID)
+			}
+			require.Equal(t, expected, ids)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+)
+
+// WithSlowQueryLog logs a warning for every query taking longer than threshold, with the name of the sqlc query.
+// The arguments of the queries are never logged, they can hold personal data.
+func WithSlowQueryLog(logger *slog.Logger, threshold time.Duration) StoreOption {
+	return func(store *Store) {
+		store.logger = logger
+		store.slowQueryThreshold = threshold
+	}
+}
+
+// slowQueryDBTX logs the queries slower than its threshold
+type slowQueryDBTX struct {
+	db        DBTX
+	logger    *slog.Logger
+	threshold time.Duration
+}
+
+func (s *slowQueryDBTX) log(ctx context.Context, query string, start time.Time, err error) {
+	duration := time.Since(start)
+	if duration < s.threshold {
+		return
+	}
+
+	attrs := []slog.Attr{
+		slog.String("query", queryName(query)),
+		slog.Float64("duration_ms", float64(duration.Microseconds())/1000),
+		slog.Float64("threshold_ms", float64(s.threshold.Microseconds())/1000),
+	}
+	if err != nil && err != sql.ErrNoRows {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	s.logger.LogAttrs(ctx, slog.LevelWarn, "slow query", attrs...)
+}
+
+func (s *slowQueryDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	result, err := s.db.ExecContext(ctx, query, args...)
+	s.log(ctx, query, start, err)
+	return result, err
+}
+
+func (s *slowQueryDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	start := time.Now()
+	stmt, err := s.db.PrepareContext(ctx, query)
+	s.log(ctx, query, start, err)
+	return stmt, err
+}
+
+func (s *slowQueryDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	s.log(ctx, query, start, err)
+	return rows, err
+}
+
+func (s *slowQueryDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := s.db.QueryRowContext(ctx, query, args...)
+	s.log(ctx, query, start, row.Err())
+	return row
+}
@@ -0,0 +1,49 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/logging"
+)
+
+// sleepyDBTX takes delay to execute any statement
+type sleepyDBTX struct {
+	DBTX
+	delay time.Duration
+}
+
+func (s sleepyDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	time.Sleep(s.delay)
+	return nil, nil
+}
+
+func TestSlowQueryLog(t *testing.T) {
+	var out bytes.Buffer
+	store := NewStore(nil, WithSlowQueryLog(logging.New(&out, slog.LevelInfo), 5*time.Millisecond))
+
+	ctx := logging.WithRequestID(context.Background(), "req-1")
+
+	fast := store.conn(sleepyDBTX{}, nil)
+	_, err := fast.ExecContext(ctx, deleteAccount, int64(1))
+	require.NoError(t, err)
+	require.Empty(t, out.String())
+
+	slow := store.conn(sleepyDBTX{delay: 10 * time.Millisecond}, nil)
+	_, err = slow.ExecContext(ctx, deleteAccount, "secret-argument")
+	require.NoError(t, err)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	require.Equal(t, "slow query", record["msg"])
+	require.Equal(t, "DeleteAccount", record["query"])
+	require.Equal(t, "req-1", record["request_id"])
+	require.GreaterOrEqual(t, record["duration_ms"], float64(10))
+	require.NotContains(t, out.String(), "secret-argument")
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type Statement struct {
+	Account        Account         `json:"account"`
+	PeriodStart    time.Time       `json:"period_start"`
+	PeriodEnd      time.Time       `json:"period_end"`
+	OpeningBalance int64           `json:"opening_balance"`
+	ClosingBalance int64           `json:"closing_balance"`
+	Lines          []StatementLine `json:"lines"`
+}
+
+// StatementLine is an entry of the statement with the reference and description of its transfer
+type StatementLine struct {
+	ListStatementLinesRow
+	// Balance is the balance of the account right after the line
+	Balance int64 `json:"balance"`
+}
+
+// AccountStatement returns the entries of an account between periodStart, inclusive, and periodEnd, exclusive,
+// with the balance of the account before and after the period and after every entry.
+// Everything is read from a single snapshot, so the balances match the lines.
+func (store *Store) AccountStatement(
+	ctx context.Context,
+	accountId int64,
+	periodStart time.Time,
+	periodEnd time.Time,
+) (Statement, error) {
+	statement := Statement{PeriodStart: periodStart, PeriodEnd: periodEnd}
+
+	if !periodStart.Before(periodEnd) {
+		return statement, ErrInvalidDateRange
+	}
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return statement, err
+	}
+	defer tx.Rollback()
+
+	q := New(store.conn(tx, nil))
+
+	statement.Account, err = q.GetAccount(ctx, accountId)
+	if err != nil {
+		return statement, err
+	}
+
+	sinceStart, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{AccountID: accountId, CreatedAt: periodStart})
+	if err != nil {
+		return statement, err
+	}
+
+	sinceEnd, err := q.SumEntriesSince(ctx, SumEntriesSinceParams{AccountID: accountId, CreatedAt: periodEnd})
+	if err != nil {
+		return statement, err
+	}
+
+	rows, err := q.ListStatementLines(ctx, ListStatementLinesParams{
+		AccountID:   accountId,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+	})
+	if err != nil {
+		return statement, err
+	}
+
+	statement.OpeningBalance = statement.Account.Balance - sinceStart
+	statement.ClosingBalance = statement.Account.Balance - sinceEnd
+
+	balance := statement.OpeningBalance
+	statement.Lines = make([]StatementLine, len(rows))
+	for i, row := range rows {
+		balance += row.Amount
+		statement.Lines[i] = StatementLine{ListStatementLinesRow: row, Balance: balance}
+	}
+
+	if balance != statement.ClosingBalance {
+		return statement, fmt.Errorf("statement of account %d doesn't add up: %d != %d", accountId, balance, statement.ClosingBalance)
+	}
+
+	return statement, tx.Commit()
+}
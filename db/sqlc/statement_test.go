@@ -0,0 +1,56 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_AccountStatement(t *testing.T) {
+	store := NewStore(testDB)
+	ctx := context.Background()
+
+	account := createRandomAccountWithCurrency(t, "USD")
+	other := createRandomAccountWithCurrency(t, "USD")
+	periodStart := time.Now().Add(-time.Minute)
+
+	paid, err := store.TransferTX(ctx, TransferTxParams{
+		FromAccountId: account.ID,
+		ToAccountId:   other.ID,
+		Amount:        10,
+		Reference:     "INV-7",
+		Description:   "Rent",
+	})
+	require.NoError(t, err)
+
+	received, err := store.TransferTX(ctx, TransferTxParams{
+		FromAccountId: other.ID,
+		ToAccountId:   account.ID,
+		Amount:        3,
+		Reference:     "Refund",
+	})
+	require.NoError(t, err)
+
+	statement, err := store.AccountStatement(ctx, account.ID, periodStart, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+
+	require.Equal(t, account.Balance, statement.OpeningBalance)
+	require.Equal(t, account.Balance-7, statement.ClosingBalance)
+	require.Len(t, statement.Lines, 2)
+
+	require.Equal(t, paid.FromEntry.ID, statement.Lines[0].ID)
+	require.Equal(t, "INV-7", statement.Lines[0].Reference)
+	require.Equal(t, "Rent", statement.Lines[0].Description)
+	require.Equal(t, other.ID, statement.Lines[0].CounterpartyID)
+	require.Equal(t, account.Balance-10, statement.Lines[0].Balance)
+
+	require.Equal(t, received.ToEntry.ID, statement.Lines[1].ID)
+	require.Equal(t, "Refund", statement.Lines[1].Reference)
+	require.Equal(t, other.ID, statement.Lines[1].CounterpartyID)
+	require.Equal(t, account.Balance-7, statement.Lines[1].Balance)
+
+	_, err = store.AccountStatement(ctx, account.ID, periodStart, periodStart)
+	require.ErrorIs(t, err, ErrInvalidDateRange)
+}
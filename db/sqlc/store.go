@@ -3,7 +3,13 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // Store provides all functions to execute db queries and transactions
@@ -14,28 +20,120 @@ type Store struct {
 
 	//Required to create a new db transaction
 	db *sql.DB
+
+	fees FeeSchedule
+
+	// suspenseAccounts are the counterpart of the balance adjustments, by currency
+	suspenseAccounts map[string]int64
+
+	// internalAccounts are the accounts of the bank, debited without KYC
+	internalAccounts map[int64]bool
+
+	// fxAccounts are the counterpart of the currency conversions, by currency
+	fxAccounts map[string]int64
+
+	// fxBaseCurrency is the currency the cross rates are derived through
+	fxBaseCurrency string
+
+	// fxMaxAge is how old the rates of an FX quote can be, zero accepts any age
+	fxMaxAge time.Duration
+
+	// trails holds the audit trail of every running transaction, by its queries
+	trails sync.Map
+
+	// approvalTTL is how long a transfer request waits for its approval before expiring
+	approvalTTL time.Duration
+
+	observer Observer
+
+	// tracer traces the transactions and the queries, nil traces nothing
+	tracer trace.Tracer
+
+	// logger logs the queries slower than slowQueryThreshold, nil logs nothing
+	logger             *slog.Logger
+	slowQueryThreshold time.Duration
+
+	// lifecycle guards runningTxs and drained, which is made by Close and closed once the transactions are over
+	lifecycle  sync.Mutex
+	runningTxs int
+	drained    chan struct{}
+}
+
+// StoreOption configures optional behaviour of a Store
+type StoreOption func(store *Store)
+
+func NewStore(db *sql.DB, opts ...StoreOption) *Store {
+	store := &Store{
+		db:             db,
+		approvalTTL:    DefaultApprovalTTL,
+		fxBaseCurrency: DefaultFXBaseCurrency,
+		fxMaxAge:       DefaultFXMaxAge,
+		observer:       nopObserver{},
+	}
+
+	for _, opt := range opts {
+		opt(store)
+	}
+
+	store.Queries = New(store.conn(db, nil))
+	return store
 }
 
-func NewStore(db *sql.DB) *Store {
-	return &Store{
-		db:      db,
-		Queries: New(db),
+// conn returns the connection the queries go through, decorated by the slow query log and the tracer.
+// The queries of a transaction are children of the span of the transaction.
+func (store *Store) conn(db DBTX, tx trace.Span) DBTX {
+	if store.logger != nil {
+		db = &slowQueryDBTX{db: db, logger: store.logger, threshold: store.slowQueryThreshold}
+	}
+	if store.tracer != nil {
+		traced := &tracedDBTX{db: db, tracer: store.tracer}
+		if tx != nil {
+			traced.parent = tx.SpanContext()
+		}
+		db = traced
 	}
+	return db
 }
 
 // execTx executes a function within a database transaction
 // func(queries *Queries) Its a callback function
+// The mutations audited by the function are appended to the audit log right before the commit.
+// It fails with ErrStoreClosed once Close was called.
 func (store *Store) execTx(ctx context.Context, fn func(queries *Queries) error) error {
+	if err := store.enterTx(); err != nil {
+		return err
+	}
+	defer store.leaveTx()
+
+	start := time.Now()
+	ctx, span := store.startTxSpan(ctx)
+
+	err := store.runTx(ctx, span, fn)
+
+	endTxSpan(span, err)
+	store.observer.ObserveTx(time.Since(start), err)
+	return err
+}
+
+func (store *Store) runTx(ctx context.Context, span trace.Span, fn func(queries *Queries) error) error {
 	tx, err := store.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 
-	q := New(tx)
+	q := New(store.conn(tx, span))
+	trail := &auditTrail{}
+	store.trails.Store(q, trail)
+	defer store.trails.Delete(q)
+
 	err = fn(q)
+	if err == nil {
+		// the audit log stays locked until the commit, so nothing may run between the two
+		err = appendAuditTrail(q, ctx, trail)
+	}
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
-			return fmt.Errorf("tx err: %v, rb err: %v", err, rbErr)
+			return fmt.Errorf("tx err: %w, rb err: %v", err, rbErr)
 		}
 		return err
 	}
@@ -48,6 +146,11 @@ type TransferTxParams struct {
 	FromAccountId int64 `json:"from_account_id"`
 	ToAccountId   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// Reference is a free text the sender gives to the transfer, it can be searched
+	Reference   string `json:"reference"`
+	Description string `json:"description"`
+	// Metadata must be a JSON object, it's stored as an empty object when it's nil
+	Metadata json.RawMessage `json:"metadata"`
 }
 
 type TransferTxResult struct {
@@ -56,43 +159,112 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// Fee is charged to the sender on top of the amount, it's zero when the transfer has no fee
+	Fee int64 `json:"fee"`
+	// FeeEntry debits the fee from the sender
+	FeeEntry Entry `json:"fee_entry"`
+	// RevenueEntry credits the fee to the bank revenue account
+	RevenueEntry   Entry   `json:"revenue_entry"`
+	RevenueAccount Account `json:"revenue_account"`
 }
 
 // TransferTX performs a money transfer from one account to the other
 // It creates a transfer record, add account entries, and update accounts´balance within a single database transaction
+// When the store has a fee schedule for the sender currency, the fee is debited from the sender and credited to the bank revenue account
+// When the context has an actor, it must be allowed to send the amount by its role on the sender account,
+// and an amount above the approval threshold of the account returns ErrApprovalRequired: use RequestTransfer instead
 func (store *Store) TransferTX(
 	ctx context.Context,
 	params TransferTxParams,
 ) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
-		var err error
-
-		result.Transfer, err = createNewTransfer(q, ctx, params)
+	txCtx := withTxTrace(ctx, "TransferTX", transferAttributes(params)...)
+	err := store.execTx(txCtx, func(q *Queries) error {
+		err := checkApprovalThreshold(q, ctx, params.FromAccountId, params.Amount)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = createNewEntry(q, ctx, params.FromAccountId, -params.Amount)
-		if err != nil {
-			return err
-		}
+		result, err = store.transferTx(q, ctx, params)
+		return err
+	})
 
-		result.ToEntry, err = createNewEntry(q, ctx, params.ToAccountId, params.Amount)
+	store.observeTransfer(result, err)
+	return result, err
+}
+
+// transferTx runs the statements of a transfer using the given queries,
+// so it can be part of a bigger database transaction
+func (store *Store) transferTx(
+	q *Queries,
+	ctx context.Context,
+	params TransferTxParams,
+) (result TransferTxResult, err error) {
+	params.Metadata, err = validateTransferDetails(params)
+	if err != nil {
+		return
+	}
+
+	err = checkDebitRole(q, ctx, params.FromAccountId, params.Amount)
+	if err != nil {
+		return
+	}
+
+	err = store.checkDebitKYC(q, ctx, params.FromAccountId)
+	if err != nil {
+		return
+	}
+
+	var revenueAccountId int64
+	result.Fee, revenueAccountId, err = store.transferFee(q, ctx, params)
+	if err != nil {
+		return
+	}
+
+	result.Transfer, err = createNewTransfer(q, ctx, params, result.Fee)
+	if err != nil {
+		return
+	}
+
+	result.FromEntry, err = createTransferEntry(q, ctx, params.FromAccountId, -params.Amount, result.Transfer.ID)
+	if err != nil {
+		return
+	}
+
+	result.ToEntry, err = createTransferEntry(q, ctx, params.ToAccountId, params.Amount, result.Transfer.ID)
+	if err != nil {
+		return
+	}
+
+	if result.Fee == 0 {
+		err = updateToAndFromAccountsBalance(q, params, &result, ctx)
+	} else {
+		err = createFeeEntries(q, ctx, params, revenueAccountId, &result)
 		if err != nil {
-			return err
+			return
 		}
 
-		err2 := updateToAndFromAccountsBalance(q, params, &result, ctx)
-		if err2 != nil {
-			return err2
-		}
+		err = updateBalancesWithFee(q, params, revenueAccountId, &result, ctx)
+	}
+	if err != nil {
+		return
+	}
 
-		return nil
-	})
+	// the updates locked both accounts
+	err = checkNotFrozen(result.FromAccount, result.ToAccount)
+	if err != nil {
+		return
+	}
 
-	return result, err
+	err = createTransferEvents(q, ctx, result)
+	if err != nil {
+		return
+	}
+
+	store.audit(q, AuditTransferCreate, AuditEntityTransfer, result.Transfer.ID, nil, result.Transfer)
+	store.auditEntries(q, result.FromEntry, result.ToEntry, result.FeeEntry, result.RevenueEntry)
+	return
 }
 
 func updateToAndFromAccountsBalance(
@@ -125,16 +297,36 @@ func addAmountToAccounts(
 	return
 }
 
+// addAccountBalance is written by hand instead of in db/query, so it's not exported with Queries:
+// a balance only changes through the store, along with the entries that explain it
+const addAccountBalance = `-- name: addAccountBalance :one
+UPDATE accounts
+SET balance = balance + $1
+WHERE id = $2
+RETURNING id, owner, balance, currency, created_at, product_id, customer_id, approval_threshold, wallet_id, frozen_at
+`
+
 func updateAccountBalance(
 	q *Queries,
 	ctx context.Context,
 	accountId int64,
 	amount int64,
 ) (Account, error) {
-	return q.AddAccountBalance(ctx, AddAccountBalanceParams{
-		ID:     accountId,
-		Amount: amount,
-	})
+	row := q.db.QueryRowContext(ctx, addAccountBalance, amount, accountId)
+	var account Account
+	err := row.Scan(
+		&account.ID,
+		&account.Owner,
+		&account.Balance,
+		&account.Currency,
+		&account.CreatedAt,
+		&account.ProductID,
+		&account.CustomerID,
+		&account.ApprovalThreshold,
+		&account.WalletID,
+		&account.FrozenAt,
+	)
+	return account, err
 }
 
 func createNewEntry(
@@ -149,14 +341,49 @@ func createNewEntry(
 	})
 }
 
+// createMultiTransferEntry creates an entry linked to the multi transfer it belongs to
+func createMultiTransferEntry(
+	q *Queries,
+	ctx context.Context,
+	accountId int64,
+	amount int64,
+	multiTransferId int64,
+) (Entry, error) {
+	return q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:       accountId,
+		Amount:          amount,
+		MultiTransferID: sql.NullInt64{Int64: multiTransferId, Valid: true},
+	})
+}
+
+// createTransferEntry creates an entry linked to the transfer it belongs to
+func createTransferEntry(
+	q *Queries,
+	ctx context.Context,
+	accountId int64,
+	amount int64,
+	transferId int64,
+) (Entry, error) {
+	return q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  accountId,
+		Amount:     amount,
+		TransferID: sql.NullInt64{Int64: transferId, Valid: true},
+	})
+}
+
 func createNewTransfer(
 	q *Queries,
 	ctx context.Context,
 	params TransferTxParams,
+	fee int64,
 ) (Transfer, error) {
 	return q.CreateTransfer(ctx, CreateTransferParams{
 		FromAccountID: params.FromAccountId,
 		ToAccountID:   params.ToAccountId,
 		Amount:        params.Amount,
+		Fee:           fee,
+		Reference:     params.Reference,
+		Description:   params.Description,
+		Metadata:      params.Metadata,
 	})
 }
@@ -0,0 +1,150 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+	"simple_bank/tracing"
+)
+
+// WithTracer traces the transactions of the store and every query, in and out of the transactions
+func WithTracer(provider trace.TracerProvider) StoreOption {
+	return func(store *Store) {
+		store.tracer = provider.Tracer(tracing.InstrumentationName)
+	}
+}
+
+type txTraceKey struct{}
+
+// txTrace names the span of the next transaction of the context and gives its attributes
+type txTrace struct {
+	name  string
+	attrs []attribute.KeyValue
+}
+
+// withTxTrace names the span of the transactions run with the context, like TransferTX, and adds attributes to it
+func withTxTrace(ctx context.Context, name string, attrs ...attribute.KeyValue) context.Context {
+	return context.WithValue(ctx, txTraceKey{}, txTrace{name: name, attrs: attrs})
+}
+
+// startTxSpan starts the span of a transaction, named by withTxTrace or execTx
+func (store *Store) startTxSpan(ctx context.Context) (context.Context, trace.Span) {
+	if store.tracer == nil {
+		return ctx, noop.Span{}
+	}
+
+	named, ok := ctx.Value(txTraceKey{}).(txTrace)
+	if !ok {
+		named.name = "execTx"
+	}
+	attrs := append([]attribute.KeyValue{attribute.String("db.system", "postgresql")}, named.attrs...)
+	return store.tracer.Start(ctx, named.name, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+// endTxSpan records the outcome of the transaction and ends its span
+func endTxSpan(span trace.Span, err error) {
+	outcome := "committed"
+	if err != nil {
+		outcome = "rolled_back"
+	}
+	span.SetAttributes(attribute.String("db.transaction.outcome", outcome))
+	recordError(span, err)
+	span.End()
+}
+
+// recordError sets the status of the span to error, a nil error is ignored
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// transferAttributes are the span attributes of a transfer
+func transferAttributes(params TransferTxParams) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.Int64("bank.from_account_id", params.FromAccountId),
+		attribute.Int64("bank.to_account_id", params.ToAccountId),
+		attribute.Int64("bank.amount", params.Amount),
+	}
+}
+
+// tracedDBTX starts a client span for every query, named after the sqlc query
+type tracedDBTX struct {
+	db     DBTX
+	tracer trace.Tracer
+	parent trace.SpanContext
+}
+
+func (t *tracedDBTX) start(ctx context.Context, query string) trace.Span {
+	if t.parent.IsValid() {
+		ctx = trace.ContextWithSpanContext(ctx, t.parent)
+	}
+
+	name := queryName(query)
+	_, span := t.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "postgresql"),
+			attribute.String("db.operation.name", name),
+			attribute.String("db.query.text", query),
+		),
+	)
+	return span
+}
+
+func endQuerySpan(span trace.Span, err error) {
+	if err != sql.ErrNoRows {
+		recordError(span, err)
+	}
+	span.End()
+}
+
+func (t *tracedDBTX) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	span := t.start(ctx, query)
+	result, err := t.db.ExecContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return result, err
+}
+
+func (t *tracedDBTX) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	span := t.start(ctx, query)
+	stmt, err := t.db.PrepareContext(ctx, query)
+	endQuerySpan(span, err)
+	return stmt, err
+}
+
+func (t *tracedDBTX) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	span := t.start(ctx, query)
+	rows, err := t.db.QueryContext(ctx, query, args...)
+	endQuerySpan(span, err)
+	return rows, err
+}
+
+func (t *tracedDBTX) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	span := t.start(ctx, query)
+	row := t.db.QueryRowContext(ctx, query, args...)
+	endQuerySpan(span, row.Err())
+	return row
+}
+
+// queryName returns the name of a sqlc query from its "-- name: GetAccount :one" header, or its first word
+func queryName(query string) string {
+	query = strings.TrimSpace(query)
+	if header, ok := strings.CutPrefix(query, "-- name: "); ok {
+		if name, _, found := strings.Cut(header, " "); found {
+			return name
+		}
+	}
+
+	if fields := strings.Fields(query); len(fields) > 0 {
+		return strings.ToUpper(fields[0])
+	}
+	return "query"
+}
@@ -0,0 +1,50 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestStore_TraceTransfer(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	store := NewStore(testDB, WithTracer(provider))
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	ctx, request := provider.Tracer("test").Start(context.Background(), "POST /transfers", trace.WithSpanKind(trace.SpanKindServer))
+	_, err := store.TransferTX(ctx, TransferTxParams{FromAccountId: account1.ID, ToAccountId: account2.ID, Amount: 10})
+	require.NoError(t, err)
+	request.End()
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	tx := spans["TransferTX"]
+	require.Equal(t, request.SpanContext().SpanID(), tx.Parent().SpanID())
+	require.Contains(t, tx.Attributes(), attribute.Int64("bank.from_account_id", account1.ID))
+	require.Contains(t, tx.Attributes(), attribute.Int64("bank.to_account_id", account2.ID))
+	require.Contains(t, tx.Attributes(), attribute.String("db.transaction.outcome", "committed"))
+
+	query := spans["CreateTransfer"]
+	require.Equal(t, tx.SpanContext().SpanID(), query.Parent().SpanID())
+	require.Equal(t, request.SpanContext().TraceID(), query.SpanContext().TraceID())
+	require.Equal(t, trace.SpanKindClient, query.SpanKind())
+	require.Contains(t, query.Attributes(), attribute.String("db.operation.name", "CreateTransfer"))
+}
+
+func TestQueryName(t *testing.T) {
+	require.Equal(t, "GetAccount", queryName(getAccount))
+	require.Equal(t, "CreateTransfer", queryName(createTransfer))
+	require.Equal(t, "addAccountBalance", queryName(addAccountBalance))
+	require.Equal(t, "SELECT", queryName("\n  select 1"))
+	require.Equal(t, "query", queryName(" "))
+}
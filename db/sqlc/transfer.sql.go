@@ -7,26 +7,44 @@ package db
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 )
 
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    fee,
+    reference,
+    description,
+    metadata
 ) VALUES (
-             $1, $2, $3
-         ) RETURNING id, from_account_id, to_account_id, amount, created_at
+             $1, $2, $3, $4, $5, $6, $7
+         ) RETURNING id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64           `json:"from_account_id"`
+	ToAccountID   int64           `json:"to_account_id"`
+	Amount        int64           `json:"amount"`
+	Fee           int64           `json:"fee"`
+	Reference     string          `json:"reference"`
+	Description   string          `json:"description"`
+	Metadata      json.RawMessage `json:"metadata"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Reference,
+		arg.Description,
+		arg.Metadata,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +52,16 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Reference,
+		&i.Description,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +74,16 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Reference,
+		&i.Description,
+		&i.Metadata,
 	)
 	return i, err
 }
 
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata FROM transfers
 WHERE
         from_account_id = $1 OR
         to_account_id = $2
@@ -93,6 +119,117 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Reference,
+			&i.Description,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersAsc = `-- name: ListTransfersAsc :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+    AND (created_at, id) > ($2::timestamptz, $3::bigint)
+ORDER BY created_at ASC, id ASC
+LIMIT $4
+`
+
+type ListTransfersAscParams struct {
+	AccountID       int64     `json:"account_id"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+// Keyset pagination of the transfers from or to an account, resuming after the cursor
+func (q *Queries) ListTransfersAsc(ctx context.Context, arg ListTransfersAscParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersAsc,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Reference,
+			&i.Description,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersDesc = `-- name: ListTransfersDesc :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, reference, description, metadata FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+    AND (created_at, id) < ($2::timestamptz, $3::bigint)
+ORDER BY created_at DESC, id DESC
+LIMIT $4
+`
+
+type ListTransfersDescParams struct {
+	AccountID       int64     `json:"account_id"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        int64     `json:"cursor_id"`
+	MaxRows         int32     `json:"max_rows"`
+}
+
+func (q *Queries) ListTransfersDesc(ctx context.Context, arg ListTransfersDescParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersDesc,
+		arg.AccountID,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.MaxRows,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Transfer
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Reference,
+			&i.Description,
+			&i.Metadata,
 		); err != nil {
 			return nil, err
 		}
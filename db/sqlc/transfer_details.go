@@ -0,0 +1,51 @@
+package db
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// Limits of the free text fields of a transfer, the lengths are counted in characters
+const (
+	MaxReferenceLength   = 140
+	MaxDescriptionLength = 500
+	MaxMetadataSize      = 4096
+)
+
+var (
+	ErrReferenceTooLong   = fmt.Errorf("reference must be at most %d characters", MaxReferenceLength)
+	ErrDescriptionTooLong = fmt.Errorf("description must be at most %d characters", MaxDescriptionLength)
+	ErrInvalidMetadata    = errors.New("metadata must be a JSON object")
+	ErrMetadataTooLarge   = fmt.Errorf("metadata must be at most %d bytes", MaxMetadataSize)
+)
+
+// validateTransferDetails checks the reference, description and metadata of a transfer
+// and returns the metadata to store, an empty object when there is none
+func validateTransferDetails(params TransferTxParams) (json.RawMessage, error) {
+	if utf8.RuneCountInString(params.Reference) > MaxReferenceLength {
+		return nil, ErrReferenceTooLong
+	}
+
+	if utf8.RuneCountInString(params.Description) > MaxDescriptionLength {
+		return nil, ErrDescriptionTooLong
+	}
+
+	metadata := bytes.TrimSpace(params.Metadata)
+	if len(metadata) == 0 || bytes.Equal(metadata, []byte("null")) {
+		return json.RawMessage("{}"), nil
+	}
+
+	if len(metadata) > MaxMetadataSize {
+		return nil, ErrMetadataTooLarge
+	}
+
+	var object map[string]json.RawMessage
+	if metadata[0] != '{' || json.Unmarshal(metadata, &object) != nil {
+		return nil, ErrInvalidMetadata
+	}
+
+	return json.RawMessage(metadata), nil
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateTransferDetails(t *testing.T) {
+	testCases := []struct {
+		name     string
+		params   TransferTxParams
+		metadata string
+		err      error
+	}{
+		{name: "Empty", params: TransferTxParams{}, metadata: "{}"},
+		{name: "NullMetadata", params: TransferTxParams{Metadata: json.RawMessage("null")}, metadata: "{}"},
+		{
+			name:     "OK",
+			params:   TransferTxParams{Reference: "INV-1", Description: "March rent", Metadata: json.RawMessage(` {"invoice": 1} `)},
+			metadata: `{"invoice": 1}`,
+		},
+		{
+			name:     "MultiByteReference",
+			params:   TransferTxParams{Reference: strings.Repeat("é", MaxReferenceLength)},
+			metadata: "{}",
+		},
+		{name: "ReferenceTooLong", params: TransferTxParams{Reference: strings.Repeat("a", MaxReferenceLength+1)}, err: ErrReferenceTooLong},
+		{name: "DescriptionTooLong", params: TransferTxParams{Description: strings.Repeat("a", MaxDescriptionLength+1)}, err: ErrDescriptionTooLong},
+		{name: "MetadataArray", params: TransferTxParams{Metadata: json.RawMessage(`[1]`)}, err: ErrInvalidMetadata},
+		{name: "MetadataInvalid", params: TransferTxParams{Metadata: json.RawMessage(`{"a":`)}, err: ErrInvalidMetadata},
+		{
+			name:   "MetadataTooLarge",
+			params: TransferTxParams{Metadata: json.RawMessage(`{"a":"` + strings.Repeat("a", MaxMetadataSize) + `"}`)},
+			err:    ErrMetadataTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			metadata, err := validateTransferDetails(tc.params)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.metadata, string(metadata))
+		})
+	}
+}
+
+func TestStore_TransferTXWithDetails(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := createRandomAccountWithCurrency(t, "USD")
+	account2 := createRandomAccountWithCurrency(t, "USD")
+
+	result, err := store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+		Reference:     "INV-2023-001",
+		Description:   "Consulting, March",
+		Metadata:      json.RawMessage(`{"invoice": "2023-001", "project": "atlas"}`),
+	})
+	require.NoError(t, err)
+
+	transfer, err := store.GetTransfer(context.Background(), result.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, "INV-2023-001", transfer.Reference)
+	require.Equal(t, "Consulting, March", transfer.Description)
+	require.JSONEq(t, `{"invoice": "2023-001", "project": "atlas"}`, string(transfer.Metadata))
+
+	for _, entry := range []Entry{result.FromEntry, result.ToEntry} {
+		require.True(t, entry.TransferID.Valid)
+		require.Equal(t, transfer.ID, entry.TransferID.Int64)
+	}
+
+	page, err := store.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID:   account1.ID,
+		Description: "consulting",
+		Metadata:    json.RawMessage(`{"project": "atlas"}`),
+	})
+	require.NoError(t, err)
+	require.Len(t, page.Transfers, 1)
+	require.Equal(t, transfer.ID, page.Transfers[0].ID)
+
+	_, err = store.TransferTX(context.Background(), TransferTxParams{
+		FromAccountId: account1.ID,
+		ToAccountId:   account2.ID,
+		Amount:        10,
+		Metadata:      json.RawMessage(`"not an object"`),
+	})
+	require.ErrorIs(t, err, ErrInvalidMetadata)
+}
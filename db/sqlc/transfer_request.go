@@ -0,0 +1,300 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Statuses of a transfer request
+const (
+	TransferRequestPending  = "pending_approval"
+	TransferRequestApproved = "approved"
+	TransferRequestRejected = "rejected"
+	TransferRequestExpired  = "expired"
+)
+
+// DefaultApprovalTTL is how long a transfer request waits for its approval by default
+const DefaultApprovalTTL = 48 * time.Hour
+
+var (
+	ErrApprovalRequired          = errors.New("the amount is above the approval threshold of the account, the transfer needs an approval")
+	ErrTransferRequestNotPending = errors.New("the transfer request is not pending approval")
+	ErrTransferRequestExpired    = errors.New("the transfer request has expired")
+	ErrSelfApproval              = errors.New("a transfer request must be reviewed by another user than its requester")
+	ErrNoReviewer                = errors.New("a transfer request must be reviewed by a user")
+	ErrNoRejectionReason         = errors.New("a rejection needs a reason")
+	ErrInvalidApprovalThreshold  = errors.New("approval threshold must be positive")
+	ErrNotAccountOwner           = errors.New("only the owner of the account can change it")
+)
+
+// WithApprovalTTL sets how long a transfer request waits for its approval before expiring
+func WithApprovalTTL(ttl time.Duration) StoreOption {
+	return func(store *Store) {
+		store.approvalTTL = ttl
+	}
+}
+
+// RequestTransferResult is the result of RequestTransfer, only one of its fields is set
+type RequestTransferResult struct {
+	// Transfer is the executed transfer when no approval was needed
+	Transfer *TransferTxResult `json:"transfer,omitempty"`
+	// Request waits for an approval, no money moved yet
+	Request *TransferRequest `json:"request,omitempty"`
+}
+
+// RequestTransfer executes the transfer when its amount is up to the approval threshold of the sender account.
+// Above it, the transfer is stored as a request pending approval by another holder of the account, without moving money.
+// The actor of the context is the requester, it must be allowed to send the amount by its role.
+func (store *Store) RequestTransfer(ctx context.Context, params TransferTxParams) (RequestTransferResult, error) {
+	var result RequestTransferResult
+
+	txCtx := withTxTrace(ctx, "RequestTransfer", transferAttributes(params)...)
+	err := store.execTx(txCtx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, params.FromAccountId)
+		if err != nil {
+			return err
+		}
+
+		if !needsApproval(account, params.Amount) {
+			transfer, err := store.transferTx(q, ctx, params)
+			result.Transfer = &transfer
+			return err
+		}
+
+		params.Metadata, err = validateTransferDetails(params)
+		if err != nil {
+			return err
+		}
+
+		err = checkDebitRole(q, ctx, params.FromAccountId, params.Amount)
+		if err != nil {
+			return err
+		}
+
+		// no money moves yet, the approval checks both accounts again once they're locked
+		err = checkNotFrozen(account)
+		if err != nil {
+			return err
+		}
+
+		request, err := q.CreateTransferRequest(ctx, CreateTransferRequestParams{
+			FromAccountID: params.FromAccountId,
+			ToAccountID:   params.ToAccountId,
+			Amount:        params.Amount,
+			Reference:     params.Reference,
+			Description:   params.Description,
+			Metadata:      params.Metadata,
+			RequestedBy:   ActorFromContext(ctx),
+			ExpiresAt:     time.Now().Add(store.approvalTTL),
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Request = &request
+		store.audit(q, AuditTransferRequestCreate, AuditEntityTransferRequest, request.ID, nil, request)
+		return nil
+	})
+
+	switch {
+	case err == nil && result.Request != nil:
+		store.observer.ObserveTransfer(TransferApprovalRequired, "", 0)
+	case result.Transfer != nil:
+		store.observeTransfer(*result.Transfer, err)
+	default:
+		store.observeTransfer(TransferTxResult{}, err)
+	}
+	return result, err
+}
+
+// ApproveTransferRequestResult is the result of ApproveTransferRequest
+type ApproveTransferRequestResult struct {
+	Request  TransferRequest  `json:"request"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// ApproveTransferRequest executes a pending transfer request.
+// The actor of the context is the approver: it must not be the requester and must be allowed to send the amount itself.
+// The request and the transfer are committed together, a failed transfer leaves the request pending.
+func (store *Store) ApproveTransferRequest(ctx context.Context, id int64) (ApproveTransferRequestResult, error) {
+	var result ApproveTransferRequestResult
+
+	txCtx := withTxTrace(ctx, "ApproveTransferRequest", attribute.Int64("bank.transfer_request_id", id))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		request, reviewer, err := reviewableTransferRequest(q, ctx, id)
+		if err != nil {
+			return err
+		}
+
+		// transferTx checks the role of the approver on the sender account
+		result.Transfer, err = store.transferTx(q, ctx, TransferTxParams{
+			FromAccountId: request.FromAccountID,
+			ToAccountId:   request.ToAccountID,
+			Amount:        request.Amount,
+			Reference:     request.Reference,
+			Description:   request.Description,
+			Metadata:      request.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Request, err = q.ReviewTransferRequest(ctx, ReviewTransferRequestParams{
+			ID:         id,
+			Status:     TransferRequestApproved,
+			ReviewedBy: reviewer,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditTransferRequestApprove, AuditEntityTransferRequest, id, request, result.Request)
+		return nil
+	})
+
+	store.observeTransfer(result.Transfer, err)
+	return result, err
+}
+
+// RejectTransferRequest rejects a pending transfer request with a reason.
+// The actor of the context must not be the requester and must be a holder allowed to transfer from the account.
+func (store *Store) RejectTransferRequest(ctx context.Context, id int64, reason string) (TransferRequest, error) {
+	var result TransferRequest
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return result, ErrNoRejectionReason
+	}
+
+	txCtx := withTxTrace(ctx, "RejectTransferRequest", attribute.Int64("bank.transfer_request_id", id))
+	err := store.execTx(txCtx, func(q *Queries) error {
+		request, reviewer, err := reviewableTransferRequest(q, ctx, id)
+		if err != nil {
+			return err
+		}
+
+		err = checkDebitRole(q, ctx, request.FromAccountID, 0)
+		if err != nil {
+			return err
+		}
+
+		result, err = q.ReviewTransferRequest(ctx, ReviewTransferRequestParams{
+			ID:              id,
+			Status:          TransferRequestRejected,
+			ReviewedBy:      reviewer,
+			RejectionReason: reason,
+		})
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditTransferRequestReject, AuditEntityTransferRequest, id, request, result)
+		return nil
+	})
+
+	return result, err
+}
+
+// ExpireTransferRequests marks expired the requests still pending approval at now, it returns them
+func (store *Store) ExpireTransferRequests(ctx context.Context, now time.Time) ([]TransferRequest, error) {
+	var expired []TransferRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		expired, err = q.ExpireTransferRequests(ctx, now)
+		if err != nil {
+			return err
+		}
+
+		for _, request := range expired {
+			store.audit(q, AuditTransferRequestExpire, AuditEntityTransferRequest, request.ID, nil, request)
+		}
+		return nil
+	})
+
+	return expired, err
+}
+
+// SetAccountApprovalThreshold sets the amount above which the transfers from the account need an approval,
+// a null threshold removes the approvals. Only the owner of the account can change it.
+func (store *Store) SetAccountApprovalThreshold(ctx context.Context, arg SetAccountApprovalThresholdParams) (Account, error) {
+	if arg.ApprovalThreshold.Valid && arg.ApprovalThreshold.Int64 <= 0 {
+		return Account{}, ErrInvalidApprovalThreshold
+	}
+
+	return store.updateAudited(ctx, arg.ID, AuditAccountSetApprovalThreshold, func(q *Queries) (Account, error) {
+		role, err := checkActorRole(q, ctx, arg.ID)
+		if err != nil {
+			return Account{}, err
+		}
+		if role != "" && role != RoleOwner {
+			return Account{}, ErrNotAccountOwner
+		}
+
+		return q.SetAccountApprovalThreshold(ctx, arg)
+	})
+}
+
+// checkApprovalThreshold returns ErrApprovalRequired when a user sends more than the approval threshold of the account,
+// the transfers of the system aren't checked
+func checkApprovalThreshold(q *Queries, ctx context.Context, accountId int64, amount int64) error {
+	if _, ok := userActor(ctx); !ok {
+		return nil
+	}
+
+	account, err := q.GetAccount(ctx, accountId)
+	if err != nil {
+		return err
+	}
+
+	if needsApproval(account, amount) {
+		return fmt.Errorf("%w: account %d: %d > %d", ErrApprovalRequired, accountId, amount, account.ApprovalThreshold.Int64)
+	}
+	return nil
+}
+
+func needsApproval(account Account, amount int64) bool {
+	return account.ApprovalThreshold.Valid && amount > account.ApprovalThreshold.Int64
+}
+
+// reviewableTransferRequest locks a pending and unexpired transfer request and returns it with its reviewer,
+// the actor of the context, which must be a user other than the requester
+func reviewableTransferRequest(q *Queries, ctx context.Context, id int64) (TransferRequest, string, error) {
+	reviewer, ok := userActor(ctx)
+	if !ok {
+		return TransferRequest{}, "", ErrNoReviewer
+	}
+
+	request, err := q.GetTransferRequestForUpdate(ctx, id)
+	if err != nil {
+		return request, "", err
+	}
+
+	switch {
+	case request.Status != TransferRequestPending:
+		return request, "", fmt.Errorf("%w: %s", ErrTransferRequestNotPending, request.Status)
+	case !time.Now().Before(request.ExpiresAt):
+		return request, "", ErrTransferRequestExpired
+	case request.RequestedBy == reviewer:
+		return request, "", ErrSelfApproval
+	}
+	return request, reviewer, nil
+}
+
+// checkActorRole returns the role of the actor of the context on the account, empty for the system
+func checkActorRole(q *Queries, ctx context.Context, accountId int64) (string, error) {
+	actor, ok := userActor(ctx)
+	if !ok {
+		return "", nil
+	}
+
+	role, err := accountRole(q, ctx, accountId, actor)
+	return role.Role, err
+}
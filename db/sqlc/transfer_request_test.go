@@ -0,0 +1,178 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestStore_TransferRequestApproval(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, "USD")
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	owner := WithActor(context.Background(), account.Owner)
+	coOwnerName := util.RandomOwner()
+	coOwner := WithActor(context.Background(), coOwnerName)
+
+	_, err := store.AddAccountHolder(owner, AddAccountHolderParams{AccountID: account.ID, Username: coOwnerName, Role: RoleCoOwner})
+	require.NoError(t, err)
+
+	_, err = store.SetAccountApprovalThreshold(coOwner, SetAccountApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.ErrorIs(t, err, ErrNotAccountOwner)
+
+	updated, err := store.SetAccountApprovalThreshold(owner, SetAccountApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), updated.ApprovalThreshold.Int64)
+
+	result, err := store.RequestTransfer(owner, TransferTxParams{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 100})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	require.Nil(t, result.Request)
+
+	params := TransferTxParams{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 101, Reference: "INV-9"}
+
+	_, err = store.TransferTX(owner, params)
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	result, err = store.RequestTransfer(owner, params)
+	require.NoError(t, err)
+	require.Nil(t, result.Transfer)
+	require.NotNil(t, result.Request)
+	require.Equal(t, TransferRequestPending, result.Request.Status)
+	require.Equal(t, account.Owner, result.Request.RequestedBy)
+
+	before, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-100, before.Balance)
+
+	_, err = store.ApproveTransferRequest(owner, result.Request.ID)
+	require.ErrorIs(t, err, ErrSelfApproval)
+
+	_, err = store.ApproveTransferRequest(context.Background(), result.Request.ID)
+	require.ErrorIs(t, err, ErrNoReviewer)
+
+	approved, err := store.ApproveTransferRequest(coOwner, result.Request.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferRequestApproved, approved.Request.Status)
+	require.Equal(t, coOwnerName, approved.Request.ReviewedBy)
+	require.Equal(t, approved.Transfer.Transfer.ID, approved.Request.TransferID.Int64)
+	require.Equal(t, "INV-9", approved.Transfer.Transfer.Reference)
+	require.Equal(t, before.Balance-101, approved.Transfer.FromAccount.Balance)
+
+	_, err = store.RejectTransferRequest(coOwner, result.Request.ID, "too late")
+	require.ErrorIs(t, err, ErrTransferRequestNotPending)
+}
+
+func TestStore_TransferRequestRejectAndExpire(t *testing.T) {
+	store := NewStore(testDB, WithApprovalTTL(time.Minute))
+	account := createRandomAccountWithCurrency(t, "USD")
+	other := createRandomAccountWithCurrency(t, "USD")
+
+	owner := WithActor(context.Background(), account.Owner)
+	coOwnerName := util.RandomOwner()
+	coOwner := WithActor(context.Background(), coOwnerName)
+
+	_, err := store.AddAccountHolder(owner, AddAccountHolderParams{AccountID: account.ID, Username: coOwnerName, Role: RoleCoOwner})
+	require.NoError(t, err)
+
+	_, err = store.SetAccountApprovalThreshold(owner, SetAccountApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: sql.NullInt64{Int64: 1, Valid: true},
+	})
+	require.NoError(t, err)
+
+	params := TransferTxParams{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 10}
+	rejected, err := store.RequestTransfer(coOwner, params)
+	require.NoError(t, err)
+	expired, err := store.RequestTransfer(coOwner, params)
+	require.NoError(t, err)
+
+	_, err = store.RejectTransferRequest(owner, rejected.Request.ID, " ")
+	require.ErrorIs(t, err, ErrNoRejectionReason)
+
+	request, err := store.RejectTransferRequest(owner, rejected.Request.ID, "unknown payee")
+	require.NoError(t, err)
+	require.Equal(t, TransferRequestRejected, request.Status)
+	require.Equal(t, "unknown payee", request.RejectionReason)
+	require.False(t, request.TransferID.Valid)
+
+	pending, err := store.ListPendingTransferRequests(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, expired.Request.ID, pending[0].ID)
+
+	requests, err := store.ExpireTransferRequests(context.Background(), time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.NotEmpty(t, requests)
+
+	request, err = store.GetTransferRequest(context.Background(), expired.Request.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferRequestExpired, request.Status)
+
+	_, err = store.ApproveTransferRequest(owner, expired.Request.ID)
+	require.ErrorIs(t, err, ErrTransferRequestNotPending)
+
+	after, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, after.Balance)
+}
+
+func TestStore_ApprovalThresholdOfMultiTransferAndBatch(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccountWithCurrency(t, "USD")
+	other := createRandomAccountWithCurrency(t, "USD")
+	owner := WithActor(context.Background(), account.Owner)
+
+	_, err := store.SetAccountApprovalThreshold(owner, SetAccountApprovalThresholdParams{
+		ID:                account.ID,
+		ApprovalThreshold: sql.NullInt64{Int64: 100, Valid: true},
+	})
+	require.NoError(t, err)
+
+	// the debits of the account are summed over its legs
+	_, err = store.MultiTransferTX(owner, MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: account.ID, Amount: -60},
+		{AccountId: account.ID, Amount: -41},
+		{AccountId: other.ID, Amount: 101},
+	}})
+	require.ErrorIs(t, err, ErrApprovalRequired)
+
+	_, err = store.MultiTransferTX(owner, MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: account.ID, Amount: -100},
+		{AccountId: other.ID, Amount: 100},
+	}})
+	require.NoError(t, err)
+
+	batch, err := store.ExecuteBatch(owner, ExecuteBatchParams{
+		Mode: BatchAllOrNothing,
+		Transfers: []BatchTransfer{
+			{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 10},
+			{FromAccountId: account.ID, ToAccountId: other.ID, Amount: 101},
+		},
+	})
+	require.ErrorIs(t, err, ErrBatchFailed)
+	require.ErrorIs(t, batch.Results[0].Err, ErrBatchRolledBack)
+	require.ErrorIs(t, batch.Results[1].Err, ErrApprovalRequired)
+
+	// the system isn't limited by the threshold
+	_, err = store.MultiTransferTX(context.Background(), MultiTransferTxParams{Legs: []TransferLeg{
+		{AccountId: account.ID, Amount: -101},
+		{AccountId: other.ID, Amount: 101},
+	}})
+	require.NoError(t, err)
+
+	after, err := store.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance-201, after.Balance)
+}
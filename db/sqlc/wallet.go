@@ -0,0 +1,344 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"simple_bank/money"
+)
+
+var (
+	ErrNoWalletCurrencies     = errors.New("a wallet needs at least one currency")
+	ErrWalletCurrencyExists   = errors.New("the wallet already has a balance in the currency")
+	ErrNoWalletCurrency       = errors.New("the wallet has no balance in the currency")
+	ErrSameCurrencyConversion = errors.New("a conversion needs two different currencies")
+	ErrNonPositiveConversion  = errors.New("conversion amount must be positive")
+	ErrConversionTooSmall     = errors.New("the converted amount rounds to zero")
+	ErrNoFXAccount            = errors.New("no FX account for the currency")
+)
+
+// WithFXAccounts sets the accounts of the bank that are the counterpart of the conversions, by currency
+func WithFXAccounts(accounts map[string]int64) StoreOption {
+	return func(store *Store) {
+		store.fxAccounts = accounts
+	}
+}
+
+// OpenWalletParams contains the input parameters of OpenWallet
+type OpenWalletParams struct {
+	CustomerID int64  `json:"customer_id"`
+	Owner      string `json:"owner"`
+	// Currencies are the currencies of the balances the wallet is opened with
+	Currencies []string `json:"currencies"`
+}
+
+type OpenWalletResult struct {
+	Wallet Wallet `json:"wallet"`
+	// Accounts are the balances of the wallet, in the order of the currencies
+	Accounts []Account `json:"accounts"`
+}
+
+// OpenWallet creates a wallet of the customer with an account per currency, all owned by the owner.
+// The accounts are linked to the customer, so they can only be debited once the customer is KYC verified.
+func (store *Store) OpenWallet(ctx context.Context, params OpenWalletParams) (OpenWalletResult, error) {
+	var result OpenWalletResult
+
+	if len(params.Currencies) == 0 {
+		return result, ErrNoWalletCurrencies
+	}
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		_, err := q.GetCustomer(ctx, params.CustomerID)
+		if err != nil {
+			return err
+		}
+
+		result.Wallet, err = q.CreateWallet(ctx, CreateWalletParams{CustomerID: params.CustomerID, Owner: params.Owner})
+		if err != nil {
+			return err
+		}
+		store.audit(q, AuditWalletCreate, AuditEntityWallet, result.Wallet.ID, nil, result.Wallet)
+
+		result.Accounts = nil
+		for _, currency := range params.Currencies {
+			account, err := store.createWalletAccount(q, ctx, result.Wallet, currency)
+			if err != nil {
+				return err
+			}
+			result.Accounts = append(result.Accounts, account)
+		}
+		return nil
+	})
+
+	return result, err
+}
+
+// AddWalletCurrency opens a balance in currency in the wallet
+func (store *Store) AddWalletCurrency(ctx context.Context, walletId int64, currency string) (Account, error) {
+	var account Account
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		wallet, err := q.GetWallet(ctx, walletId)
+		if err != nil {
+			return err
+		}
+
+		account, err = store.createWalletAccount(q, ctx, wallet, currency)
+		return err
+	})
+
+	return account, err
+}
+
+func (store *Store) createWalletAccount(q *Queries, ctx context.Context, wallet Wallet, currency string) (Account, error) {
+	if _, err := money.LookupCurrency(currency); err != nil {
+		return Account{}, err
+	}
+
+	account, err := q.CreateWalletAccount(ctx, CreateWalletAccountParams{
+		Owner:      wallet.Owner,
+		Currency:   currency,
+		CustomerID: sql.NullInt64{Int64: wallet.CustomerID, Valid: true},
+		WalletID:   sql.NullInt64{Int64: wallet.ID, Valid: true},
+	})
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Constraint == "accounts_wallet_currency_key" {
+		return account, fmt.Errorf("%w: %s", ErrWalletCurrencyExists, currency)
+	}
+	if err != nil {
+		return account, err
+	}
+
+	store.audit(q, AuditAccountCreate, AuditEntityAccount, account.ID, nil, account)
+	return account, nil
+}
+
+// ConvertCurrencyParams contains the input parameters of ConvertCurrency
+type ConvertCurrencyParams struct {
+	WalletID     int64  `json:"wallet_id"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	// Amount is debited from the balance in FromCurrency, in its minor units
+	Amount int64 `json:"amount"`
+}
+
+type ConvertCurrencyResult struct {
+	Conversion Conversion `json:"conversion"`
+	// FromAccount and ToAccount have their updated balances
+	FromAccount Account `json:"from_account"`
+	ToAccount   Account `json:"to_account"`
+	FromEntry   Entry   `json:"from_entry"`
+	ToEntry     Entry   `json:"to_entry"`
+}
+
+// ConvertCurrency moves money between two balances of a wallet at the current FX rate, see FXRate.
+// The converted amount is rounded to the minor unit with banker's rounding.
+// Every currency stays balanced in the ledger: the amount is credited to the FX account of the from currency
+// and the converted amount debited from the FX account of the to currency.
+// The actor of the context must be allowed to send the amount from the from balance, as for a transfer.
+func (store *Store) ConvertCurrency(ctx context.Context, params ConvertCurrencyParams) (ConvertCurrencyResult, error) {
+	var result ConvertCurrencyResult
+
+	switch {
+	case params.Amount <= 0:
+		return result, ErrNonPositiveConversion
+	case params.FromCurrency == params.ToCurrency:
+		return result, ErrSameCurrencyConversion
+	}
+
+	fromFXAccountId, ok := store.fxAccounts[params.FromCurrency]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrNoFXAccount, params.FromCurrency)
+	}
+	toFXAccountId, ok := store.fxAccounts[params.ToCurrency]
+	if !ok {
+		return result, fmt.Errorf("%w: %s", ErrNoFXAccount, params.ToCurrency)
+	}
+
+	txCtx := withTxTrace(ctx, "ConvertCurrency",
+		attribute.Int64("bank.wallet_id", params.WalletID),
+		attribute.String("bank.from_currency", params.FromCurrency),
+		attribute.String("bank.to_currency", params.ToCurrency),
+		attribute.Int64("bank.amount", params.Amount),
+	)
+	err := store.execTx(txCtx, func(q *Queries) error {
+		from, err := walletAccount(q, ctx, params.WalletID, params.FromCurrency)
+		if err != nil {
+			return err
+		}
+		to, err := walletAccount(q, ctx, params.WalletID, params.ToCurrency)
+		if err != nil {
+			return err
+		}
+
+		err = checkDebitRole(q, ctx, from.ID, params.Amount)
+		if err != nil {
+			return err
+		}
+		err = store.checkDebitKYC(q, ctx, from.ID)
+		if err != nil {
+			return err
+		}
+
+		quote, err := store.fxQuote(q, ctx, params.FromCurrency, params.ToCurrency, time.Now())
+		if err != nil {
+			return err
+		}
+		converted, err := money.Money{Amount: params.Amount, Currency: params.FromCurrency}.Convert(params.ToCurrency, quote.rate)
+		if err != nil {
+			return err
+		}
+		if converted.Amount <= 0 {
+			return ErrConversionTooSmall
+		}
+
+		legs := []TransferLeg{
+			{AccountId: from.ID, Amount: -params.Amount},
+			{AccountId: to.ID, Amount: converted.Amount},
+			{AccountId: fromFXAccountId, Amount: params.Amount},
+			{AccountId: toFXAccountId, Amount: -converted.Amount},
+		}
+
+		locked, err := lockAccounts(q, ctx, sortedLegAccountIds(legs))
+		if err != nil {
+			return err
+		}
+		err = checkLegsBalance(legs, locked)
+		if err != nil {
+			return err
+		}
+		err = checkNotFrozen(locked[from.ID], locked[to.ID])
+		if err != nil {
+			return err
+		}
+
+		entries := make([]Entry, len(legs))
+		for i, leg := range legs {
+			entries[i], err = createNewEntry(q, ctx, leg.AccountId, leg.Amount)
+			if err != nil {
+				return err
+			}
+		}
+		result.FromEntry, result.ToEntry = entries[0], entries[1]
+
+		accounts, err := updateLegsBalance(q, ctx, legs)
+		if err != nil {
+			return err
+		}
+		result.FromAccount, result.ToAccount = accounts[from.ID], accounts[to.ID]
+
+		err = createLegsEvents(q, ctx, entries, accounts)
+		if err != nil {
+			return err
+		}
+
+		result.Conversion, err = q.CreateConversion(ctx, CreateConversionParams{
+			WalletID:      params.WalletID,
+			FromAccountID: from.ID,
+			ToAccountID:   to.ID,
+			FromAmount:    params.Amount,
+			ToAmount:      converted.Amount,
+			Rate:          quote.Rate,
+			Actor:         ActorFromContext(ctx),
+			FromEntryID:   result.FromEntry.ID,
+			ToEntryID:     result.ToEntry.ID,
+		})
+		if err != nil {
+			return err
+		}
+
+		store.audit(q, AuditWalletConvert, AuditEntityWallet, params.WalletID, nil, result.Conversion)
+		store.auditEntries(q, entries...)
+		return nil
+	})
+
+	return result, err
+}
+
+// walletAccount returns the balance of the wallet in currency, ErrNoWalletCurrency when it has none
+func walletAccount(q *Queries, ctx context.Context, walletId int64, currency string) (Account, error) {
+	account, err := q.GetWalletAccount(ctx, GetWalletAccountParams{
+		WalletID: sql.NullInt64{Int64: walletId, Valid: true},
+		Currency: currency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return account, fmt.Errorf("%w: wallet %d in %s", ErrNoWalletCurrency, walletId, currency)
+	}
+	return account, err
+}
+
+// WalletBalance is the balance of every currency of a wallet and their total in a reporting currency
+type WalletBalance struct {
+	Wallet            Wallet `json:"wallet"`
+	ReportingCurrency string `json:"reporting_currency"`
+	// Total is the sum of the converted balances, in the minor units of the reporting currency
+	Total    int64                   `json:"total"`
+	Balances []WalletCurrencyBalance `json:"balances"`
+}
+
+type WalletCurrencyBalance struct {
+	Account Account `json:"account"`
+	// Rate converts the currency of the account into the reporting currency
+	Rate string `json:"rate"`
+	// Converted is the balance in the minor units of the reporting currency
+	Converted int64 `json:"converted"`
+}
+
+// ConsolidatedBalance returns the balances of the wallet converted into the reporting currency at the current FX rates.
+// Every balance is rounded to the minor unit before the total is summed, all of them are read from a single snapshot.
+func (store *Store) ConsolidatedBalance(ctx context.Context, walletId int64, reportingCurrency string) (WalletBalance, error) {
+	result := WalletBalance{ReportingCurrency: reportingCurrency, Balances: []WalletCurrencyBalance{}}
+
+	if _, err := money.LookupCurrency(reportingCurrency); err != nil {
+		return result, err
+	}
+
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback()
+
+	q := New(store.conn(tx, nil))
+
+	result.Wallet, err = q.GetWallet(ctx, walletId)
+	if err != nil {
+		return result, err
+	}
+
+	accounts, err := q.ListWalletAccounts(ctx, sql.NullInt64{Int64: walletId, Valid: true})
+	if err != nil {
+		return result, err
+	}
+
+	now := time.Now()
+	total := money.Money{Currency: reportingCurrency}
+	for _, account := range accounts {
+		quote, err := store.fxQuote(q, ctx, account.Currency, reportingCurrency, now)
+		if err != nil {
+			return result, err
+		}
+		converted, err := money.Money{Amount: account.Balance, Currency: account.Currency}.Convert(reportingCurrency, quote.rate)
+		if err != nil {
+			return result, err
+		}
+		total, err = total.Add(converted)
+		if err != nil {
+			return result, err
+		}
+
+		result.Balances = append(result.Balances, WalletCurrencyBalance{
+			Account:   account,
+			Rate:      quote.Rate,
+			Converted: converted.Amount,
+		})
+	}
+
+	result.Total = total.Amount
+	return result, nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/util"
+)
+
+func TestStore_ConvertCurrency(t *testing.T) {
+	fxCHF := createRandomAccountWithCurrency(t, "CHF")
+	fxSEK := createRandomAccountWithCurrency(t, "SEK")
+	store := NewStore(testDB, WithFXAccounts(map[string]int64{"CHF": fxCHF.ID, "SEK": fxSEK.ID}))
+	ctx := context.Background()
+
+	customer := createRandomCustomer(t, store)
+	_, err := store.RecordKYCOutcome(ctx, RecordKYCOutcomeParams{CustomerID: customer.ID, Status: KYCVerified, Reviewer: "admin"})
+	require.NoError(t, err)
+
+	owner := util.RandomOwner()
+	opened, err := store.OpenWallet(ctx, OpenWalletParams{CustomerID: customer.ID, Owner: owner, Currencies: []string{"CHF", "SEK"}})
+	require.NoError(t, err)
+	require.Len(t, opened.Accounts, 2)
+	for _, account := range opened.Accounts {
+		require.Equal(t, owner, account.Owner)
+		require.Equal(t, opened.Wallet.ID, account.WalletID.Int64)
+		require.Equal(t, customer.ID, account.CustomerID.Int64)
+	}
+
+	_, err = store.AddWalletCurrency(ctx, opened.Wallet.ID, "CHF")
+	require.ErrorIs(t, err, ErrWalletCurrencyExists)
+
+	_, err = store.AddFXRate(ctx, CreateFXRateParams{BaseCurrency: "SEK", QuoteCurrency: "CHF", Rate: "0.08"})
+	require.NoError(t, err)
+
+	// the CHF to SEK rate is the inverse of the stored SEK to CHF rate
+	result, err := store.ConvertCurrency(WithActor(ctx, owner), ConvertCurrencyParams{
+		WalletID:     opened.Wallet.ID,
+		FromCurrency: "CHF",
+		ToCurrency:   "SEK",
+		Amount:       1000,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(12500), result.Conversion.ToAmount)
+	require.Equal(t, "12.5", result.Conversion.Rate)
+	require.Equal(t, owner, result.Conversion.Actor)
+	require.Equal(t, int64(-1000), result.FromAccount.Balance)
+	require.Equal(t, int64(12500), result.ToAccount.Balance)
+	require.Equal(t, result.FromEntry.ID, result.Conversion.FromEntryID)
+
+	checkBalance(t, fxCHF, 1000)
+	checkBalance(t, fxSEK, -12500)
+
+	balance, err := store.ConsolidatedBalance(ctx, opened.Wallet.ID, "CHF")
+	require.NoError(t, err)
+	require.Len(t, balance.Balances, 2)
+	require.Equal(t, int64(0), balance.Total)
+
+	_, err = store.ConvertCurrency(ctx, ConvertCurrencyParams{WalletID: opened.Wallet.ID, FromCurrency: "CHF", ToCurrency: "USD", Amount: 100})
+	require.ErrorIs(t, err, ErrNoFXAccount)
+
+	_, err = store.ConvertCurrency(ctx, ConvertCurrencyParams{WalletID: opened.Wallet.ID, FromCurrency: "CHF", ToCurrency: "CHF", Amount: 100})
+	require.ErrorIs(t, err, ErrSameCurrencyConversion)
+
+	_, err = store.ConvertCurrency(WithActor(ctx, util.RandomOwner()), ConvertCurrencyParams{
+		WalletID:     opened.Wallet.ID,
+		FromCurrency: "CHF",
+		ToCurrency:   "SEK",
+		Amount:       100,
+	})
+	require.ErrorIs(t, err, ErrNotAccountHolder)
+}
+
+func TestStore_OpenWalletUnknownCurrency(t *testing.T) {
+	store := NewStore(testDB)
+	customer := createRandomCustomer(t, store)
+
+	_, err := store.OpenWallet(context.Background(), OpenWalletParams{
+		CustomerID: customer.ID,
+		Owner:      util.RandomOwner(),
+		Currencies: []string{"EUR", "XYZ"},
+	})
+	require.Error(t, err)
+
+	_, err = store.OpenWallet(context.Background(), OpenWalletParams{CustomerID: customer.ID, Owner: util.RandomOwner()})
+	require.ErrorIs(t, err, ErrNoWalletCurrencies)
+}
@@ -0,0 +1,45 @@
+// Package httputil holds the HTTP helpers shared by the middlewares of the server
+package httputil
+
+import "net/http"
+
+// StatusRecorder keeps the status code of the response, it's still a flusher for the event streams
+type StatusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+// NewStatusRecorder wraps the writer, the status is 200 until a handler writes another one
+func NewStatusRecorder(w http.ResponseWriter) *StatusRecorder {
+	return &StatusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+// Status returns the status code written by the handler
+func (recorder *StatusRecorder) Status() int {
+	return recorder.status
+}
+
+func (recorder *StatusRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status, recorder.wroteHeader = status, true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *StatusRecorder) Write(b []byte) (int, error) {
+	recorder.wroteHeader = true
+	return recorder.ResponseWriter.Write(b)
+}
+
+func (recorder *StatusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		recorder.wroteHeader = true
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (recorder *StatusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
@@ -0,0 +1,29 @@
+package httputil
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatusRecorder(t *testing.T) {
+	recorder := NewStatusRecorder(httptest.NewRecorder())
+	require.Equal(t, http.StatusOK, recorder.Status())
+
+	recorder.WriteHeader(http.StatusNotFound)
+	recorder.WriteHeader(http.StatusInternalServerError)
+	require.Equal(t, http.StatusNotFound, recorder.Status())
+
+	// the status of a response written without header is 200
+	written := NewStatusRecorder(httptest.NewRecorder())
+	_, err := written.Write([]byte("ok"))
+	require.NoError(t, err)
+	written.WriteHeader(http.StatusInternalServerError)
+	require.Equal(t, http.StatusOK, written.Status())
+
+	flushed := httptest.NewRecorder()
+	NewStatusRecorder(flushed).Flush()
+	require.True(t, flushed.Flushed)
+}
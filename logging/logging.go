@@ -0,0 +1,99 @@
+// Package logging writes the structured logs of the bank as JSON lines with log/slog.
+// Every record logged with a context carries the request ID of the context, and sensitive values are redacted.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Redacted replaces the sensitive values
+const Redacted = "[REDACTED]"
+
+// sensitiveKeys are redacted in any attribute whose key contains one of them, like user_password or access_token
+var sensitiveKeys = []string{"password", "token", "secret", "authorization", "api_key", "national_id"}
+
+// New returns a logger writing JSON lines to out from the level up
+func New(out io.Writer, level slog.Leveler) *slog.Logger {
+	handler := slog.NewJSONHandler(out, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redact,
+	})
+	return slog.New(&contextHandler{handler})
+}
+
+// ParseLevel parses debug, info, warn or error, an empty level is info
+func ParseLevel(level string) (slog.Level, error) {
+	var l slog.Level
+	if level == "" {
+		return slog.LevelInfo, nil
+	}
+	err := l.UnmarshalText([]byte(level))
+	return l, err
+}
+
+// redact hides the values of the sensitive keys and of the bearer credentials, in groups too
+func redact(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Value.Kind() == slog.KindGroup {
+		return attr
+	}
+
+	if IsSensitive(attr.Key) {
+		return slog.String(attr.Key, Redacted)
+	}
+
+	if attr.Value.Kind() == slog.KindString {
+		if scheme, _, found := strings.Cut(attr.Value.String(), " "); found && strings.EqualFold(scheme, "bearer") {
+			return slog.String(attr.Key, Redacted)
+		}
+	}
+	return attr
+}
+
+// IsSensitive reports whether the values of the key must not be logged
+func IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+type contextKey int
+
+const requestIDKey contextKey = iota
+
+// WithRequestID returns a context whose records are logged with the request ID
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID returns the request ID of the context, empty when there's none
+func RequestID(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// contextHandler adds the request ID of the context to the records
+type contextHandler struct {
+	slog.Handler
+}
+
+func (handler *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if requestID := RequestID(ctx); requestID != "" {
+		record.AddAttrs(slog.String("request_id", requestID))
+	}
+	return handler.Handler.Handle(ctx, record)
+}
+
+func (handler *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{handler.Handler.WithAttrs(attrs)}
+}
+
+func (handler *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{handler.Handler.WithGroup(name)}
+}
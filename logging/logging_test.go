@@ -0,0 +1,66 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedaction(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, slog.LevelInfo)
+
+	logger.Info("login",
+		"username", "alice",
+		"password", "hunter2",
+		"access_token", "v2.local.abc",
+		"header", "Bearer abc.def",
+		slog.Group("customer", "legal_name", "Alice", "national_id", "123"),
+	)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	require.Equal(t, "alice", record["username"])
+	require.Equal(t, Redacted, record["password"])
+	require.Equal(t, Redacted, record["access_token"])
+	require.Equal(t, Redacted, record["header"])
+	require.Equal(t, map[string]interface{}{"legal_name": "Alice", "national_id": Redacted}, record["customer"])
+	require.NotContains(t, out.String(), "hunter2")
+}
+
+func TestRequestID(t *testing.T) {
+	var out bytes.Buffer
+	logger := New(&out, slog.LevelWarn).With("component", "test")
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	require.Equal(t, "req-1", RequestID(ctx))
+	require.Empty(t, RequestID(context.Background()))
+
+	logger.InfoContext(ctx, "below the level")
+	require.Empty(t, out.String())
+
+	logger.WarnContext(ctx, "slow")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	require.Equal(t, "req-1", record["request_id"])
+	require.Equal(t, "test", record["component"])
+	require.Equal(t, "WARN", record["level"])
+}
+
+func TestParseLevel(t *testing.T) {
+	level, err := ParseLevel("")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelInfo, level)
+
+	level, err = ParseLevel("debug")
+	require.NoError(t, err)
+	require.Equal(t, slog.LevelDebug, level)
+
+	_, err = ParseLevel("verbose")
+	require.Error(t, err)
+}
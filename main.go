@@ -0,0 +1,259 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"simple_bank/api"
+	db "simple_bank/db/sqlc"
+	"simple_bank/logging"
+	"simple_bank/metrics"
+	"simple_bank/outbox"
+	"simple_bank/stream"
+	"simple_bank/tracing"
+	"simple_bank/util"
+	"simple_bank/webhook"
+)
+
+// defaultShutdownTimeout is how long the shutdown waits for the requests and the transactions in flight
+const defaultShutdownTimeout = 30 * time.Second
+
+func main() {
+	config, err := util.LoadConfig(".")
+	if err != nil {
+		log.Fatal("cannot load config:", err)
+	}
+
+	level, err := logging.ParseLevel(config.LogLevel)
+	if err != nil {
+		log.Fatal("invalid LOG_LEVEL:", err)
+	}
+	logger := logging.New(os.Stdout, level)
+	slog.SetDefault(logger)
+
+	conn, err := sql.Open(config.DBDriver, config.DBSource)
+	if err != nil {
+		fatal("cannot connect to db", err)
+	}
+
+	tracerProvider, err := newTracerProvider(config)
+	if err != nil {
+		fatal("cannot create tracer provider", err)
+	}
+
+	feeSchedule, err := db.NewFeeSchedule(config.TransferFees, config.FeeRevenueAccounts)
+	if err != nil {
+		fatal("invalid fee schedule", err)
+	}
+
+	bankMetrics := metrics.New(conn)
+	storeOptions := []db.StoreOption{
+		db.WithFeeSchedule(feeSchedule),
+		db.WithInternalAccounts(config.InternalAccounts),
+		db.WithFXAccounts(config.FXAccounts),
+		db.WithSuspenseAccounts(config.SuspenseAccounts),
+		db.WithObserver(bankMetrics),
+	}
+	if tracerProvider != nil {
+		storeOptions = append(storeOptions, db.WithTracer(tracerProvider))
+	}
+	if config.SlowQueryThreshold > 0 {
+		storeOptions = append(storeOptions, db.WithSlowQueryLog(logger, config.SlowQueryThreshold))
+	}
+	store := db.NewStore(conn, storeOptions...)
+
+	// ctx is cancelled by SIGINT or SIGTERM, it stops the background workers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	runWorker := func(worker func()) {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			worker()
+		}()
+	}
+
+	broker := stream.NewBroker()
+	runWorker(func() {
+		if err := broker.Listen(ctx, config.DBSource); err != nil && ctx.Err() == nil {
+			fatal("cannot listen to outbox notifications", err)
+		}
+	})
+
+	dispatcher := webhook.NewDispatcher(store)
+	dispatcher.OnError = func(err error) {
+		slog.Error("webhook dispatcher", "error", err)
+	}
+	runWorker(func() {
+		_ = dispatcher.Run(ctx)
+	})
+
+	relay := outbox.NewRelay(store, dispatcher)
+	relay.OnError = func(err error) {
+		slog.Error("outbox relay", "error", err)
+	}
+	runWorker(func() {
+		_ = relay.Run(ctx)
+	})
+
+	runWorker(func() {
+		expireTransferRequests(ctx, store)
+	})
+
+	runWorker(func() {
+		payInterest(ctx, store, config.InterestExpenseAccounts)
+	})
+
+	serverOptions := []api.ServerOption{
+		api.WithMetrics(bankMetrics),
+		api.WithLogger(logger),
+	}
+	if tracerProvider != nil {
+		serverOptions = append(serverOptions, api.WithTracer(tracerProvider))
+	}
+	server, err := api.NewServer(config, store, broker, serverOptions...)
+	if err != nil {
+		fatal("cannot create server", err)
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		slog.Info("starting server", "address", config.HTTPServerAddress)
+		serverErr <- server.Start(config.HTTPServerAddress)
+	}()
+
+	select {
+	case err = <-serverErr:
+		if err != nil {
+			fatal("cannot start server", err)
+		}
+	case <-ctx.Done():
+	}
+
+	shutdown(server, stop, &workers, store, tracerProvider, config.ShutdownTimeout)
+}
+
+// shutdown stops the server gracefully within the timeout: the server stops accepting requests and drains
+// the ones in flight, the background workers stop, then the store waits for its transactions and closes the database
+func shutdown(server *api.Server, stopWorkers func(), workers *sync.WaitGroup, store *db.Store, tracerProvider *sdktrace.TracerProvider, timeout time.Duration) {
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+	slog.Info("shutting down", "timeout", timeout.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		slog.Error("server shutdown", "error", err)
+	}
+
+	stopWorkers()
+	workers.Wait()
+
+	if err := store.Close(ctx); err != nil {
+		slog.Error("store close", "error", err)
+	}
+	if tracerProvider != nil {
+		if err := tracerProvider.Shutdown(ctx); err != nil {
+			slog.Error("tracer provider shutdown", "error", err)
+		}
+	}
+
+	slog.Info("server stopped")
+}
+
+// fatal logs the error and exits
+func fatal(msg string, err error) {
+	slog.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// newTracerProvider returns the tracer provider of the TRACING_EXPORTER config, nil when tracing is disabled
+func newTracerProvider(config util.Config) (*sdktrace.TracerProvider, error) {
+	if config.TracingExporter == "" {
+		return nil, nil
+	}
+
+	exporter, err := tracing.NewExporter(context.Background(), config.TracingExporter, config.OTLPEndpoint, os.Stdout)
+	if err != nil {
+		return nil, err
+	}
+
+	otel.SetErrorHandler(otel.ErrorHandlerFunc(func(err error) {
+		slog.Error("tracing", "error", err)
+	}))
+	return tracing.NewTracerProvider("simple_bank", exporter), nil
+}
+
+// expireTransferRequests marks expired the transfer requests left unapproved, every minute until the context is cancelled
+func expireTransferRequests(ctx context.Context, store *db.Store) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		_, err := store.ExpireTransferRequests(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			slog.Error("transfer request expiry", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// payInterest accrues the interest of every day since the last accrued one up to the previous day and posts the interest of the previous month, checking every hour
+// until the context is cancelled. Both are safe to re-run, so after a restart they run again and skip what was done.
+func payInterest(ctx context.Context, store *db.Store, expenseAccounts map[string]int64) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	var accrued, posted time.Time
+	for {
+		today := time.Now().UTC().Truncate(24 * time.Hour)
+		yesterday := today.AddDate(0, 0, -1)
+		lastMonth := time.Date(today.Year(), today.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+
+		if !accrued.Equal(yesterday) {
+			result, err := store.AccrueInterestUntil(ctx, yesterday)
+			if err == nil {
+				accrued = yesterday
+				slog.Info("interest accrued", "date", yesterday.Format(time.DateOnly), "accounts", result.Accrued)
+			} else if ctx.Err() == nil {
+				slog.Error("interest accrual", "error", err)
+			}
+		}
+
+		// the month is posted once its last day is accrued
+		if accrued.Equal(yesterday) && !posted.Equal(lastMonth) {
+			result, err := store.PostInterest(ctx, db.PostInterestParams{Period: lastMonth, ExpenseAccounts: expenseAccounts})
+			if err == nil {
+				posted = lastMonth
+				slog.Info("interest posted", "month", lastMonth.Format("2006-01"), "accounts", len(result.Postings))
+			} else if ctx.Err() == nil {
+				slog.Error("interest posting", "error", err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,117 @@
+// Package metrics exposes the metrics of the bank to Prometheus
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"simple_bank/internal/httputil"
+	"simple_bank/money"
+)
+
+// DurationBuckets are the bounds in seconds of the latency histograms, from 1ms to about 16s
+var DurationBuckets = prometheus.ExponentialBuckets(0.001, 2, 15)
+
+// AmountBuckets are the bounds of the transfer amount histograms, in major units of the currency, from 1 to 10 million
+var AmountBuckets = prometheus.ExponentialBuckets(1, 10, 8)
+
+// Metrics are the metrics of the bank: the transactions and the transfers of the store,
+// the connection pool of the database and the HTTP requests of the API.
+// It implements db.Observer.
+type Metrics struct {
+	registry *prometheus.Registry
+
+	transfers       *prometheus.CounterVec
+	transferAmounts *prometheus.HistogramVec
+	txDuration      *prometheus.HistogramVec
+	httpRequests    *prometheus.CounterVec
+	httpDuration    *prometheus.HistogramVec
+}
+
+// New registers the metrics of the bank in a registry of their own,
+// the connection pool of conn is reported when it's not nil
+func New(conn *sql.DB) *Metrics {
+	registry := prometheus.NewRegistry()
+	factory := promauto.With(registry)
+
+	metrics := &Metrics{
+		registry: registry,
+		transfers: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "simple_bank_transfers_total",
+			Help: "Transfers by outcome: succeeded, approval_required, denied, invalid or failed.",
+		}, []string{"outcome"}),
+		transferAmounts: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "simple_bank_transfer_amount",
+			Help:    "Amounts of the succeeded transfers in major units of their currency.",
+			Buckets: AmountBuckets,
+		}, []string{"currency"}),
+		txDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "simple_bank_db_tx_duration_seconds",
+			Help:    "Duration of the database transactions of the store.",
+			Buckets: DurationBuckets,
+		}, []string{"outcome"}),
+		httpRequests: factory.NewCounterVec(prometheus.CounterOpts{
+			Name: "simple_bank_http_requests_total",
+			Help: "HTTP requests by route and status code.",
+		}, []string{"method", "route", "code"}),
+		httpDuration: factory.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "simple_bank_http_request_duration_seconds",
+			Help:    "Duration of the HTTP requests by route.",
+			Buckets: DurationBuckets,
+		}, []string{"method", "route"}),
+	}
+
+	if conn != nil {
+		registry.MustRegister(collectors.NewDBStatsCollector(conn, "simple_bank"))
+	}
+
+	return metrics
+}
+
+// Handler serves the metrics of the registry
+func (metrics *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(metrics.registry, promhttp.HandlerOpts{})
+}
+
+// ObserveTx records the duration of a database transaction
+func (metrics *Metrics) ObserveTx(duration time.Duration, err error) {
+	outcome := "committed"
+	if err != nil {
+		outcome = "rolled_back"
+	}
+
+	metrics.txDuration.WithLabelValues(outcome).Observe(duration.Seconds())
+}
+
+// ObserveTransfer counts a transfer by outcome and records the amount of the succeeded ones
+func (metrics *Metrics) ObserveTransfer(outcome string, currency string, amount int64) {
+	metrics.transfers.WithLabelValues(outcome).Inc()
+	if currency == "" {
+		return
+	}
+
+	major, err := strconv.ParseFloat(money.Money{Amount: amount, Currency: currency}.FormatAmount(), 64)
+	if err == nil {
+		metrics.transferAmounts.WithLabelValues(currency).Observe(major)
+	}
+}
+
+// InstrumentHTTP counts the requests of a route and records their duration.
+// The route is the pattern of the handler, so the path values don't make a series each.
+func (metrics *Metrics) InstrumentHTTP(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := httputil.NewStatusRecorder(w)
+
+		next(recorder, r)
+
+		metrics.httpRequests.WithLabelValues(r.Method, route, strconv.Itoa(recorder.Status())).Inc()
+		metrics.httpDuration.WithLabelValues(r.Method, route).Observe(time.Since(start).Seconds())
+	}
+}
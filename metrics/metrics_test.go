@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+var _ db.Observer = (*Metrics)(nil)
+
+func scrape(t *testing.T, handler http.Handler) string {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	return recorder.Body.String()
+}
+
+func TestObserveTransfersAndTx(t *testing.T) {
+	metrics := New(nil)
+
+	metrics.ObserveTransfer(db.TransferSucceeded, "EUR", 1250)
+	metrics.ObserveTransfer(db.TransferSucceeded, "JPY", 5)
+	metrics.ObserveTransfer(db.TransferDenied, "", 0)
+	metrics.ObserveTx(20*time.Millisecond, nil)
+	metrics.ObserveTx(time.Millisecond, errors.New("boom"))
+
+	body := scrape(t, metrics.Handler())
+	require.Contains(t, body, `simple_bank_transfers_total{outcome="succeeded"} 2`)
+	require.Contains(t, body, `simple_bank_transfers_total{outcome="denied"} 1`)
+	require.Contains(t, body, `simple_bank_transfer_amount_bucket{currency="EUR",le="10"} 0`)
+	require.Contains(t, body, `simple_bank_transfer_amount_bucket{currency="EUR",le="100"} 1`)
+	require.Contains(t, body, `simple_bank_transfer_amount_sum{currency="EUR"} 12.5`)
+	require.Contains(t, body, `simple_bank_transfer_amount_sum{currency="JPY"} 5`)
+	require.Contains(t, body, `simple_bank_db_tx_duration_seconds_count{outcome="committed"} 1`)
+	require.Contains(t, body, `simple_bank_db_tx_duration_seconds_count{outcome="rolled_back"} 1`)
+	require.NotContains(t, body, "go_sql_open_connections")
+}
+
+func TestInstrumentHTTP(t *testing.T) {
+	metrics := New(nil)
+
+	handler := metrics.InstrumentHTTP("/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/accounts/2" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte("{}"))
+		w.(http.Flusher).Flush()
+	})
+
+	for _, path := range []string{"/accounts/1", "/accounts/1", "/accounts/2"} {
+		handler(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	body := scrape(t, metrics.Handler())
+	require.Contains(t, body, `simple_bank_http_requests_total{code="200",method="GET",route="/accounts/{id}"} 2`)
+	require.Contains(t, body, `simple_bank_http_requests_total{code="404",method="GET",route="/accounts/{id}"} 1`)
+	require.Contains(t, body, `simple_bank_http_request_duration_seconds_count{method="GET",route="/accounts/{id}"} 3`)
+}
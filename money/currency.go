@@ -0,0 +1,108 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Currency is an ISO 4217 currency
+type Currency struct {
+	// Code is the alphabetic code, like EUR
+	Code string
+	// Number is the numeric code, like 978 for EUR
+	Number int
+	// MinorUnits is the number of decimals of the currency, 2 for EUR and 0 for JPY
+	MinorUnits int
+}
+
+var ErrUnknownCurrency = errors.New("money: unknown currency")
+
+// currencies is the ISO 4217 registry of the supported currencies, by code
+var currencies = map[string]Currency{
+	"AED": {Code: "AED", Number: 784, MinorUnits: 2},
+	"ARS": {Code: "ARS", Number: 32, MinorUnits: 2},
+	"AUD": {Code: "AUD", Number: 36, MinorUnits: 2},
+	"BHD": {Code: "BHD", Number: 48, MinorUnits: 3},
+	"BRL": {Code: "BRL", Number: 986, MinorUnits: 2},
+	"CAD": {Code: "CAD", Number: 124, MinorUnits: 2},
+	"CHF": {Code: "CHF", Number: 756, MinorUnits: 2},
+	"CLF": {Code: "CLF", Number: 990, MinorUnits: 4},
+	"CLP": {Code: "CLP", Number: 152, MinorUnits: 0},
+	"CNY": {Code: "CNY", Number: 156, MinorUnits: 2},
+	"COP": {Code: "COP", Number: 170, MinorUnits: 2},
+	"CZK": {Code: "CZK", Number: 203, MinorUnits: 2},
+	"DKK": {Code: "DKK", Number: 208, MinorUnits: 2},
+	"EGP": {Code: "EGP", Number: 818, MinorUnits: 2},
+	"EUR": {Code: "EUR", Number: 978, MinorUnits: 2},
+	"GBP": {Code: "GBP", Number: 826, MinorUnits: 2},
+	"HKD": {Code: "HKD", Number: 344, MinorUnits: 2},
+	"HUF": {Code: "HUF", Number: 348, MinorUnits: 2},
+	"IDR": {Code: "IDR", Number: 360, MinorUnits: 2},
+	"ILS": {Code: "ILS", Number: 376, MinorUnits: 2},
+	"INR": {Code: "INR", Number: 356, MinorUnits: 2},
+	"ISK": {Code: "ISK", Number: 352, MinorUnits: 0},
+	"JOD": {Code: "JOD", Number: 400, MinorUnits: 3},
+	"JPY": {Code: "JPY", Number: 392, MinorUnits: 0},
+	"KRW": {Code: "KRW", Number: 410, MinorUnits: 0},
+	"KWD": {Code: "KWD", Number: 414, MinorUnits: 3},
+	"MXN": {Code: "MXN", Number: 484, MinorUnits: 2},
+	"MYR": {Code: "MYR", Number: 458, MinorUnits: 2},
+	"NGN": {Code: "NGN", Number: 566, MinorUnits: 2},
+	"NOK": {Code: "NOK", Number: 578, MinorUnits: 2},
+	"NZD": {Code: "NZD", Number: 554, MinorUnits: 2},
+	"OMR": {Code: "OMR", Number: 512, MinorUnits: 3},
+	"PHP": {Code: "PHP", Number: 608, MinorUnits: 2},
+	"PKR": {Code: "PKR", Number: 586, MinorUnits: 2},
+	"PLN": {Code: "PLN", Number: 985, MinorUnits: 2},
+	"QAR": {Code: "QAR", Number: 634, MinorUnits: 2},
+	"RON": {Code: "RON", Number: 946, MinorUnits: 2},
+	"SAR": {Code: "SAR", Number: 682, MinorUnits: 2},
+	"SEK": {Code: "SEK", Number: 752, MinorUnits: 2},
+	"SGD": {Code: "SGD", Number: 702, MinorUnits: 2},
+	"THB": {Code: "THB", Number: 764, MinorUnits: 2},
+	"TND": {Code: "TND", Number: 788, MinorUnits: 3},
+	"TRY": {Code: "TRY", Number: 949, MinorUnits: 2},
+	"TWD": {Code: "TWD", Number: 901, MinorUnits: 2},
+	"UAH": {Code: "UAH", Number: 980, MinorUnits: 2},
+	"UGX": {Code: "UGX", Number: 800, MinorUnits: 0},
+	"USD": {Code: "USD", Number: 840, MinorUnits: 2},
+	"VND": {Code: "VND", Number: 704, MinorUnits: 0},
+	"XAF": {Code: "XAF", Number: 950, MinorUnits: 0},
+	"XOF": {Code: "XOF", Number: 952, MinorUnits: 0},
+	"ZAR": {Code: "ZAR", Number: 710, MinorUnits: 2},
+}
+
+// LookupCurrency returns the currency of an ISO 4217 alphabetic code
+func LookupCurrency(code string) (Currency, error) {
+	currency, ok := currencies[code]
+	if !ok {
+		return currency, fmt.Errorf("%w %q", ErrUnknownCurrency, code)
+	}
+	return currency, nil
+}
+
+// IsSupported reports whether the currency code is in the registry
+func IsSupported(code string) bool {
+	_, ok := currencies[code]
+	return ok
+}
+
+// Codes returns the codes of the supported currencies in alphabetical order
+func Codes() []string {
+	codes := make([]string, 0, len(currencies))
+	for code := range currencies {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// factor returns how many minor units make a major unit, 100 for EUR
+func (currency Currency) factor() int64 {
+	factor := int64(1)
+	for i := 0; i < currency.MinorUnits; i++ {
+		factor *= 10
+	}
+	return factor
+}
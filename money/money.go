@@ -0,0 +1,241 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Money is an amount in the minor units of a currency, 1234 EUR is 12.34 EUR
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+var (
+	ErrOverflow         = errors.New("money: amount overflows")
+	ErrCurrencyMismatch = errors.New("money: currency mismatch")
+	ErrInvalidAmount    = errors.New("money: invalid amount")
+	ErrTooManyDecimals  = errors.New("money: more decimals than the currency has")
+	ErrDivisionByZero   = errors.New("money: division by zero")
+)
+
+// New returns the money of an amount in minor units, the currency must be supported
+func New(amount int64, currency string) (Money, error) {
+	if _, err := LookupCurrency(currency); err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: amount, Currency: currency}, nil
+}
+
+// Parse parses an amount followed by its currency code, like "12.34 EUR"
+func Parse(s string) (Money, error) {
+	amount, currency, found := strings.Cut(strings.TrimSpace(s), " ")
+	if !found {
+		return Money{}, fmt.Errorf("%w %q: expected an amount and a currency", ErrInvalidAmount, s)
+	}
+	return ParseAmount(amount, strings.TrimSpace(currency))
+}
+
+// ParseAmount parses a decimal amount of the currency, like "12.34" or "-0.5".
+// The decimals beyond the minor units of the currency must be zeros, nothing is rounded.
+func ParseAmount(amount string, currency string) (Money, error) {
+	cur, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	sign := ""
+	digits := amount
+	if strings.HasPrefix(digits, "-") || strings.HasPrefix(digits, "+") {
+		sign, digits = digits[:1], digits[1:]
+	}
+
+	whole, fraction, hasPoint := strings.Cut(digits, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+
+	if len(fraction) > cur.MinorUnits {
+		if strings.Trim(fraction[cur.MinorUnits:], "0") != "" {
+			return Money{}, fmt.Errorf("%w: %q in %s", ErrTooManyDecimals, amount, currency)
+		}
+		fraction = fraction[:cur.MinorUnits]
+	}
+	fraction += strings.Repeat("0", cur.MinorUnits-len(fraction))
+
+	value, err := strconv.ParseInt(sign+whole+fraction, 10, 64)
+	if errors.Is(err, strconv.ErrRange) {
+		return Money{}, fmt.Errorf("%w: %q", ErrOverflow, amount)
+	}
+	if err != nil {
+		return Money{}, fmt.Errorf("%w %q", ErrInvalidAmount, amount)
+	}
+
+	return Money{Amount: value, Currency: currency}, nil
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAmount returns the amount as a decimal with the minor units of the currency, like "12.34".
+// The amount of an unknown currency is formatted without decimals.
+func (m Money) FormatAmount() string {
+	cur := currencies[m.Currency]
+
+	// the magnitude of math.MinInt64 only fits in an uint64
+	magnitude := uint64(m.Amount)
+	sign := ""
+	if m.Amount < 0 {
+		magnitude = -magnitude
+		sign = "-"
+	}
+
+	digits := strconv.FormatUint(magnitude, 10)
+	if cur.MinorUnits == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= cur.MinorUnits {
+		digits = strings.Repeat("0", cur.MinorUnits-len(digits)+1) + digits
+	}
+	point := len(digits) - cur.MinorUnits
+	return sign + digits[:point] + "." + digits[point:]
+}
+
+// String returns the amount followed by the currency, like "12.34 EUR"
+func (m Money) String() string {
+	return m.FormatAmount() + " " + m.Currency
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// IsNegative reports whether the amount is below zero
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add returns m + other, both must have the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+
+	if (other.Amount > 0 && m.Amount > math.MaxInt64-other.Amount) ||
+		(other.Amount < 0 && m.Amount < math.MinInt64-other.Amount) {
+		return Money{}, fmt.Errorf("%w: %s + %s", ErrOverflow, m, other)
+	}
+	return Money{Amount: m.Amount + other.Amount, Currency: m.Currency}, nil
+}
+
+// Sub returns m - other, both must have the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Add(negated)
+}
+
+// Neg returns -m
+func (m Money) Neg() (Money, error) {
+	if m.Amount == math.MinInt64 {
+		return Money{}, fmt.Errorf("%w: -(%s)", ErrOverflow, m)
+	}
+	return Money{Amount: -m.Amount, Currency: m.Currency}, nil
+}
+
+// Mul returns m * factor
+func (m Money) Mul(factor int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(factor))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d", ErrOverflow, m, factor)
+	}
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// MulRatio returns m * numerator / denominator rounded to the minor unit with banker's rounding,
+// the halves are rounded to the even minor unit: 0.125 EUR is 0.12 EUR and 0.135 EUR is 0.14 EUR
+func (m Money) MulRatio(numerator, denominator int64) (Money, error) {
+	if denominator == 0 {
+		return Money{}, ErrDivisionByZero
+	}
+
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(numerator))
+	rounded := RoundHalfEven(product, big.NewInt(denominator))
+	if !rounded.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s * %d / %d", ErrOverflow, m, numerator, denominator)
+	}
+	return Money{Amount: rounded.Int64(), Currency: m.Currency}, nil
+}
+
+// RoundHalfEven returns numerator / denominator rounded to the nearest integer, the halves to the even one
+func RoundHalfEven(numerator, denominator *big.Int) *big.Int {
+	quotient, remainder := new(big.Int).QuoRem(numerator, denominator, new(big.Int))
+	if remainder.Sign() == 0 {
+		return quotient
+	}
+
+	// compare 2*|remainder| with |denominator| to know whether the result is past the half
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	half := twice.Cmp(new(big.Int).Abs(denominator))
+
+	if half > 0 || (half == 0 && quotient.Bit(0) == 1) {
+		// QuoRem truncates toward zero, so round away from zero in the direction of the result
+		if (numerator.Sign() < 0) != (denominator.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	return quotient
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+type moneyJSON struct {
+	Amount   string `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// MarshalJSON encodes the money as {"amount": "12.34", "currency": "EUR"}, the amount is a string to keep it exact
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(moneyJSON{Amount: m.FormatAmount(), Currency: m.Currency})
+}
+
+// UnmarshalJSON decodes the money encoded by MarshalJSON
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var value moneyJSON
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := ParseAmount(value.Amount, value.Currency)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
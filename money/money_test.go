@@ -0,0 +1,210 @@
+package money
+
+import (
+	"encoding/json"
+	"math"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		input string
+		money Money
+		err   error
+	}{
+		{input: "12.34 EUR", money: Money{Amount: 1234, Currency: "EUR"}},
+		{input: "12 EUR", money: Money{Amount: 1200, Currency: "EUR"}},
+		{input: "12.3 EUR", money: Money{Amount: 1230, Currency: "EUR"}},
+		{input: "-0.05 USD", money: Money{Amount: -5, Currency: "USD"}},
+		{input: "+1.500 USD", money: Money{Amount: 150, Currency: "USD"}},
+		{input: "1200 JPY", money: Money{Amount: 1200, Currency: "JPY"}},
+		{input: "1.234 KWD", money: Money{Amount: 1234, Currency: "KWD"}},
+		{input: "92233720368547758.07 USD", money: Money{Amount: math.MaxInt64, Currency: "USD"}},
+		{input: "92233720368547758.08 USD", err: ErrOverflow},
+		{input: "12.345 EUR", err: ErrTooManyDecimals},
+		{input: "1.5 JPY", err: ErrTooManyDecimals},
+		{input: "12.34 XYZ", err: ErrUnknownCurrency},
+		{input: "12.34", err: ErrInvalidAmount},
+		{input: "1,5 EUR", err: ErrInvalidAmount},
+		{input: ".5 EUR", err: ErrInvalidAmount},
+		{input: "5. EUR", err: ErrInvalidAmount},
+		{input: "1e3 EUR", err: ErrInvalidAmount},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			money, err := Parse(tc.input)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.money, money)
+		})
+	}
+}
+
+func TestFormat(t *testing.T) {
+	require.Equal(t, "12.34 EUR", Money{Amount: 1234, Currency: "EUR"}.String())
+	require.Equal(t, "0.05", Money{Amount: 5, Currency: "USD"}.FormatAmount())
+	require.Equal(t, "-0.05", Money{Amount: -5, Currency: "USD"}.FormatAmount())
+	require.Equal(t, "0.00", Money{Currency: "USD"}.FormatAmount())
+	require.Equal(t, "1200", Money{Amount: 1200, Currency: "JPY"}.FormatAmount())
+	require.Equal(t, "0.001", Money{Amount: 1, Currency: "BHD"}.FormatAmount())
+	require.Equal(t, "-92233720368547758.08", Money{Amount: math.MinInt64, Currency: "USD"}.FormatAmount())
+
+	for _, amount := range []int64{0, 1, -1, 99, 100, -12345, math.MaxInt64, math.MinInt64} {
+		money := Money{Amount: amount, Currency: "EUR"}
+		parsed, err := Parse(money.String())
+		require.NoError(t, err)
+		require.Equal(t, money, parsed)
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	eur := func(amount int64) Money { return Money{Amount: amount, Currency: "EUR"} }
+
+	sum, err := eur(150).Add(eur(-200))
+	require.NoError(t, err)
+	require.Equal(t, eur(-50), sum)
+
+	difference, err := eur(150).Sub(eur(200))
+	require.NoError(t, err)
+	require.Equal(t, eur(-50), difference)
+
+	product, err := eur(150).Mul(-3)
+	require.NoError(t, err)
+	require.Equal(t, eur(-450), product)
+
+	_, err = eur(1).Add(Money{Amount: 1, Currency: "USD"})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = eur(math.MaxInt64).Add(eur(1))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = eur(math.MinInt64).Sub(eur(1))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = eur(0).Sub(eur(math.MinInt64))
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = eur(math.MinInt64).Neg()
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = eur(math.MaxInt64 / 2).Mul(3)
+	require.ErrorIs(t, err, ErrOverflow)
+
+	_, err = eur(1).MulRatio(1, 0)
+	require.ErrorIs(t, err, ErrDivisionByZero)
+}
+
+func TestMulRatioRoundsHalfToEven(t *testing.T) {
+	testCases := []struct {
+		amount      int64
+		numerator   int64
+		denominator int64
+		result      int64
+	}{
+		{amount: 125, numerator: 1, denominator: 10, result: 12},
+		{amount: 135, numerator: 1, denominator: 10, result: 14},
+		{amount: 126, numerator: 1, denominator: 10, result: 13},
+		{amount: 124, numerator: 1, denominator: 10, result: 12},
+		{amount: -125, numerator: 1, denominator: 10, result: -12},
+		{amount: -135, numerator: 1, denominator: 10, result: -14},
+		{amount: 135, numerator: -1, denominator: 10, result: -14},
+		{amount: 5, numerator: 1, denominator: 2, result: 2},
+		{amount: 7, numerator: 1, denominator: 2, result: 4},
+		{amount: 1000, numerator: 3, denominator: 7, result: 429},
+		// the intermediate product overflows int64 but the result doesn't
+		{amount: math.MaxInt64, numerator: 3, denominator: 4, result: 6917529027641081855},
+	}
+
+	for _, tc := range testCases {
+		result, err := Money{Amount: tc.amount, Currency: "EUR"}.MulRatio(tc.numerator, tc.denominator)
+		require.NoError(t, err)
+		require.Equal(t, tc.result, result.Amount, "%d * %d / %d", tc.amount, tc.numerator, tc.denominator)
+	}
+
+	require.Equal(t, int64(-2), RoundHalfEven(big.NewInt(5), big.NewInt(-2)).Int64())
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(Money{Amount: 1234, Currency: "EUR"})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"amount": "12.34", "currency": "EUR"}`, string(data))
+
+	var money Money
+	require.NoError(t, json.Unmarshal(data, &money))
+	require.Equal(t, Money{Amount: 1234, Currency: "EUR"}, money)
+
+	require.ErrorIs(t, json.Unmarshal([]byte(`{"amount": "1.234", "currency": "EUR"}`), &money), ErrTooManyDecimals)
+}
+
+func TestRegistry(t *testing.T) {
+	currency, err := LookupCurrency("JPY")
+	require.NoError(t, err)
+	require.Equal(t, Currency{Code: "JPY", Number: 392, MinorUnits: 0}, currency)
+
+	_, err = LookupCurrency("usd")
+	require.ErrorIs(t, err, ErrUnknownCurrency)
+
+	codes := Codes()
+	require.Contains(t, codes, "USD")
+	require.IsIncreasing(t, codes)
+
+	for code, currency := range currencies {
+		require.Equal(t, code, currency.Code)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	testCases := []struct {
+		name     string
+		money    Money
+		currency string
+		rate     string
+		result   Money
+		err      error
+	}{
+		{name: "EURToUSD", money: Money{Amount: 10000, Currency: "EUR"}, currency: "USD", rate: "1.0845", result: Money{Amount: 10845, Currency: "USD"}},
+		{name: "HalfToEven", money: Money{Amount: 25, Currency: "EUR"}, currency: "USD", rate: "0.5", result: Money{Amount: 12, Currency: "USD"}},
+		{name: "ToFewerMinorUnits", money: Money{Amount: 1050, Currency: "EUR"}, currency: "JPY", rate: "160", result: Money{Amount: 1680, Currency: "JPY"}},
+		{name: "ToMoreMinorUnits", money: Money{Amount: 1, Currency: "JPY"}, currency: "KWD", rate: "0.00205", result: Money{Amount: 2, Currency: "KWD"}},
+		{name: "Negative", money: Money{Amount: -10000, Currency: "EUR"}, currency: "USD", rate: "1.0845", result: Money{Amount: -10845, Currency: "USD"}},
+		{name: "Overflow", money: Money{Amount: math.MaxInt64, Currency: "EUR"}, currency: "USD", rate: "2", err: ErrOverflow},
+		{name: "UnknownCurrency", money: Money{Amount: 100, Currency: "EUR"}, currency: "XYZ", rate: "2", err: ErrUnknownCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := ParseRate(tc.rate)
+			require.NoError(t, err)
+
+			result, err := tc.money.Convert(tc.currency, rate)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.result, result)
+		})
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	rate, err := ParseRate("1.0845")
+	require.NoError(t, err)
+	require.Equal(t, big.NewRat(10845, 10000), rate)
+	require.Equal(t, "1.0845", FormatRate(rate))
+	require.Equal(t, "0.9220839096", FormatRate(new(big.Rat).Inv(rate)))
+	require.Equal(t, "2", FormatRate(big.NewRat(2, 1)))
+
+	for _, invalid := range []string{"0", "-1.2", "1e3", "1/3", "", ".5", "abc"} {
+		_, err = ParseRate(invalid)
+		require.ErrorIs(t, err, ErrInvalidRate, invalid)
+	}
+}
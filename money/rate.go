@@ -0,0 +1,58 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidRate = errors.New("money: rate must be a positive decimal")
+
+// ParseRate parses an exchange rate, like "1.0845", exactly
+func ParseRate(rate string) (*big.Rat, error) {
+	whole, fraction, hasPoint := strings.Cut(rate, ".")
+	if !isDigits(whole) || (hasPoint && !isDigits(fraction)) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+
+	value, ok := new(big.Rat).SetString(rate)
+	if !ok || value.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, rate)
+	}
+	return value, nil
+}
+
+// FormatRate returns the rate as a decimal rounded to 10 decimals, without the trailing zeros
+func FormatRate(rate *big.Rat) string {
+	formatted := rate.FloatString(10)
+	formatted = strings.TrimRight(formatted, "0")
+	return strings.TrimSuffix(formatted, ".")
+}
+
+// Convert returns m in currency at rate, the units of currency for one unit of the currency of m.
+// The result is rounded to the minor unit of currency with banker's rounding.
+func (m Money) Convert(currency string, rate *big.Rat) (Money, error) {
+	from, err := LookupCurrency(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	to, err := LookupCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+	if rate.Sign() <= 0 {
+		return Money{}, ErrInvalidRate
+	}
+
+	// amount / from.factor major units * rate * to.factor minor units
+	numerator := new(big.Int).Mul(big.NewInt(m.Amount), rate.Num())
+	numerator.Mul(numerator, big.NewInt(to.factor()))
+	denominator := new(big.Int).Mul(rate.Denom(), big.NewInt(from.factor()))
+
+	converted := RoundHalfEven(numerator, denominator)
+	if !converted.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s to %s", ErrOverflow, m, currency)
+	}
+	return Money{Amount: converted.Int64(), Currency: currency}, nil
+}
@@ -0,0 +1,42 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+
+	db "simple_bank/db/sqlc"
+)
+
+// Publisher sends an outbox event to the downstream services.
+// Events are delivered at least once, so a publisher may see the same event ID again after a failure.
+type Publisher interface {
+	Publish(ctx context.Context, event db.Outbox) error
+}
+
+// MemoryPublisher keeps the published events in memory, it's meant for tests
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []db.Outbox
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(_ context.Context, event db.Outbox) error {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	publisher.events = append(publisher.events, event)
+	return nil
+}
+
+// Events returns a copy of the events published so far, in publish order
+func (publisher *MemoryPublisher) Events() []db.Outbox {
+	publisher.mu.Lock()
+	defer publisher.mu.Unlock()
+
+	events := make([]db.Outbox, len(publisher.events))
+	copy(events, publisher.events)
+	return events
+}
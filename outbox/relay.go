@@ -0,0 +1,82 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+const (
+	defaultBatchSize = 100
+	defaultInterval  = time.Second
+)
+
+// Store is the part of db.Store used by the relay
+type Store interface {
+	RelayOutbox(ctx context.Context, limit int32, publish func(ctx context.Context, event db.Outbox) error) (int, error)
+}
+
+// Relay publishes the pending outbox events to a Publisher and marks them delivered
+type Relay struct {
+	store     Store
+	publisher Publisher
+
+	// BatchSize is the maximum number of events claimed at once, defaultBatchSize when it's not positive
+	BatchSize int32
+	// Interval is how long Run waits before polling again when there are no pending events
+	Interval time.Duration
+	// OnError is called by Run when a pass fails, the relay keeps running after it
+	OnError func(err error)
+}
+
+func NewRelay(store Store, publisher Publisher) *Relay {
+	return &Relay{
+		store:     store,
+		publisher: publisher,
+		BatchSize: defaultBatchSize,
+		Interval:  defaultInterval,
+	}
+}
+
+// RelayPending publishes the pending events until there are none left or a publish fails.
+// It returns how many events were delivered.
+func (relay *Relay) RelayPending(ctx context.Context) (int, error) {
+	total := 0
+
+	batchSize := relay.BatchSize
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+
+	for {
+		delivered, err := relay.store.RelayOutbox(ctx, batchSize, relay.publisher.Publish)
+		total += delivered
+		if err != nil {
+			return total, err
+		}
+
+		if delivered < int(batchSize) {
+			return total, nil
+		}
+	}
+}
+
+// Run relays the pending events every Interval until the context is cancelled
+func (relay *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(relay.Interval)
+	defer ticker.Stop()
+
+	for {
+		_, err := relay.RelayPending(ctx)
+		if err != nil && ctx.Err() == nil && relay.OnError != nil {
+			relay.OnError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeStore mimics db.Store.RelayOutbox on an in-memory outbox
+type fakeStore struct {
+	mu      sync.Mutex
+	pending []db.Outbox
+}
+
+func (store *fakeStore) RelayOutbox(
+	ctx context.Context,
+	limit int32,
+	publish func(ctx context.Context, event db.Outbox) error,
+) (int, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delivered := 0
+	for delivered < int(limit) && len(store.pending) > 0 {
+		if err := publish(ctx, store.pending[0]); err != nil {
+			return delivered, err
+		}
+		store.pending = store.pending[1:]
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+func newFakeStore(n int) *fakeStore {
+	store := &fakeStore{}
+	for i := 1; i <= n; i++ {
+		store.pending = append(store.pending, db.Outbox{ID: int64(i), EventType: db.EventTransferCreated})
+	}
+	return store
+}
+
+type failingPublisher struct {
+	failAt int64
+	*MemoryPublisher
+}
+
+func (publisher failingPublisher) Publish(ctx context.Context, event db.Outbox) error {
+	if event.ID == publisher.failAt {
+		return errors.New("broker unavailable")
+	}
+	return publisher.MemoryPublisher.Publish(ctx, event)
+}
+
+func TestRelay_RelayPending(t *testing.T) {
+	store := newFakeStore(25)
+	publisher := NewMemoryPublisher()
+
+	relay := NewRelay(store, publisher)
+	relay.BatchSize = 10
+
+	delivered, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 25, delivered)
+
+	events := publisher.Events()
+	require.Len(t, events, 25)
+	for i, event := range events {
+		require.Equal(t, int64(i+1), event.ID)
+	}
+	require.Empty(t, store.pending)
+}
+
+func TestRelay_RelayPendingWithoutBatchSize(t *testing.T) {
+	store := newFakeStore(defaultBatchSize + 5)
+	publisher := NewMemoryPublisher()
+
+	relay := NewRelay(store, publisher)
+	relay.BatchSize = 0
+
+	delivered, err := relay.RelayPending(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, defaultBatchSize+5, delivered)
+	require.Empty(t, store.pending)
+}
+
+func TestRelay_RelayPendingStopsAtFailure(t *testing.T) {
+	store := newFakeStore(5)
+	publisher := failingPublisher{failAt: 3, MemoryPublisher: NewMemoryPublisher()}
+
+	relay := NewRelay(store, publisher)
+
+	delivered, err := relay.RelayPending(context.Background())
+	require.Error(t, err)
+	require.Equal(t, 2, delivered)
+	require.Len(t, publisher.Events(), 2)
+
+	// the failed event stays pending
+	require.Len(t, store.pending, 3)
+	require.Equal(t, int64(3), store.pending[0].ID)
+}
+
+func TestRelay_Run(t *testing.T) {
+	store := newFakeStore(3)
+	publisher := NewMemoryPublisher()
+
+	relay := NewRelay(store, publisher)
+	relay.Interval = 10 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- relay.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(publisher.Events()) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
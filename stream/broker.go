@@ -0,0 +1,88 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	db "simple_bank/db/sqlc"
+)
+
+// subscriberBuffer is enough to not drop notifications of bursts of transfers.
+// A dropped notification is harmless, subscribers read the events from the outbox after any wake up.
+const subscriberBuffer = 64
+
+// Broker fans out the outbox notifications to its subscribers.
+// A notification with a zero ID means notifications may have been lost, subscribers should catch up.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan db.OutboxNotification]struct{}
+}
+
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan db.OutboxNotification]struct{})}
+}
+
+// Subscribe returns a channel receiving the notifications and a function to unsubscribe
+func (broker *Broker) Subscribe() (<-chan db.OutboxNotification, func()) {
+	ch := make(chan db.OutboxNotification, subscriberBuffer)
+
+	broker.mu.Lock()
+	broker.subscribers[ch] = struct{}{}
+	broker.mu.Unlock()
+
+	unsubscribe := func() {
+		broker.mu.Lock()
+		delete(broker.subscribers, ch)
+		broker.mu.Unlock()
+	}
+	return ch, unsubscribe
+}
+
+// Broadcast sends the notification to every subscriber without blocking
+func (broker *Broker) Broadcast(notification db.OutboxNotification) {
+	broker.mu.Lock()
+	defer broker.mu.Unlock()
+
+	for ch := range broker.subscribers {
+		select {
+		case ch <- notification:
+		default:
+		}
+	}
+}
+
+// Listen listens to db.OutboxChannel and broadcasts the notifications until the context is cancelled
+func (broker *Broker) Listen(ctx context.Context, dbSource string) error {
+	listener := pq.NewListener(dbSource, time.Second, time.Minute, nil)
+	defer listener.Close()
+
+	if err := listener.Listen(db.OutboxChannel); err != nil {
+		return err
+	}
+
+	return broker.run(ctx, listener.Notify)
+}
+
+func (broker *Broker) run(ctx context.Context, notifications <-chan *pq.Notification) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case n := <-notifications:
+			// pq sends nil after a reconnection, notifications may have been lost meanwhile
+			if n == nil {
+				broker.Broadcast(db.OutboxNotification{})
+				continue
+			}
+
+			var notification db.OutboxNotification
+			if err := json.Unmarshal([]byte(n.Extra), &notification); err != nil {
+				continue
+			}
+			broker.Broadcast(notification)
+		}
+	}
+}
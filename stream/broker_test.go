@@ -0,0 +1,61 @@
+package stream
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+
+	ch1, unsubscribe1 := broker.Subscribe()
+	ch2, unsubscribe2 := broker.Subscribe()
+	defer unsubscribe2()
+
+	notifications := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- broker.run(ctx, notifications)
+	}()
+
+	notifications <- &pq.Notification{Channel: db.OutboxChannel, Extra: `{"id":3,"event_type":"AccountCredited","aggregate_id":9}`}
+	for _, ch := range []<-chan db.OutboxNotification{ch1, ch2} {
+		select {
+		case notification := <-ch:
+			require.Equal(t, db.OutboxNotification{ID: 3, EventType: db.EventAccountCredited, AggregateID: 9}, notification)
+		case <-time.After(time.Second):
+			t.Fatal("notification not received")
+		}
+	}
+
+	unsubscribe1()
+
+	// a reconnection asks the subscribers to catch up
+	notifications <- nil
+	select {
+	case notification := <-ch2:
+		require.Zero(t, notification.ID)
+	case <-time.After(time.Second):
+		t.Fatal("catch up notification not received")
+	}
+	require.Empty(t, ch1)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestBrokerDoesNotBlock(t *testing.T) {
+	broker := NewBroker()
+	_, unsubscribe := broker.Subscribe()
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBuffer*2; i++ {
+		broker.Broadcast(db.OutboxNotification{ID: int64(i + 1)})
+	}
+}
@@ -0,0 +1,79 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const minSecretKeySize = 32
+
+// header of the tokens, they are JWTs signed with HS256
+var header = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+
+// HMACMaker is a token maker signing the tokens with HMAC-SHA256
+type HMACMaker struct {
+	secretKey []byte
+}
+
+// NewHMACMaker creates a new HMACMaker
+func NewHMACMaker(secretKey string) (Maker, error) {
+	if len(secretKey) < minSecretKeySize {
+		return nil, fmt.Errorf("invalid key size: must be at least %d characters", minSecretKeySize)
+	}
+	return &HMACMaker{secretKey: []byte(secretKey)}, nil
+}
+
+// CreateToken creates a new token for a specific username and duration
+func (maker *HMACMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
+	payload, err := NewPayload(username, duration)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", nil, err
+	}
+
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(data)
+	return unsigned + "." + maker.sign(unsigned), payload, nil
+}
+
+// VerifyToken checks if the token is valid or not
+func (maker *HMACMaker) VerifyToken(token string) (*Payload, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 || parts[0] != header {
+		return nil, ErrInvalidToken
+	}
+
+	unsigned := parts[0] + "." + parts[1]
+	if !hmac.Equal([]byte(maker.sign(unsigned)), []byte(parts[2])) {
+		return nil, ErrInvalidToken
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	payload := &Payload{}
+	if err := json.Unmarshal(data, payload); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	if err := payload.Valid(); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func (maker *HMACMaker) sign(unsigned string) string {
+	mac := hmac.New(sha256.New, maker.secretKey)
+	mac.Write([]byte(unsigned))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
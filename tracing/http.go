@@ -0,0 +1,24 @@
+package tracing
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Middleware starts a server span for every request of the route, continuing the trace of its traceparent header.
+// The route is the pattern of the handler, like /accounts/{id}, so the spans of a route share their name.
+func Middleware(provider trace.TracerProvider, route string, next http.HandlerFunc) http.HandlerFunc {
+	handler := otelhttp.NewHandler(next, route,
+		otelhttp.WithTracerProvider(provider),
+		otelhttp.WithPropagators(propagation.TraceContext{}),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return r.Method + " " + route
+		}),
+		otelhttp.WithSpanOptions(trace.WithAttributes(attribute.String("http.route", route))),
+	)
+	return handler.ServeHTTP
+}
@@ -0,0 +1,55 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	var handlerContext trace.SpanContext
+	handler := Middleware(provider, "/accounts/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerContext = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/accounts/7", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	handler(httptest.NewRecorder(), request)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+
+	span := spans[0]
+	require.Equal(t, "GET /accounts/{id}", span.Name())
+	require.Equal(t, trace.SpanKindServer, span.SpanKind())
+	require.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", span.SpanContext().TraceID().String())
+	require.Equal(t, "00f067aa0ba902b7", span.Parent().SpanID().String())
+	require.Equal(t, span.SpanContext(), handlerContext)
+	require.Contains(t, span.Attributes(), attribute.String("http.route", "/accounts/{id}"))
+	require.Contains(t, span.Attributes(), attribute.Int("http.status_code", http.StatusInternalServerError))
+	require.Equal(t, codes.Error, span.Status().Code)
+}
+
+func TestNewExporter(t *testing.T) {
+	_, err := NewExporter(context.Background(), ExporterOTLP, "", nil)
+	require.Error(t, err)
+
+	_, err = NewExporter(context.Background(), "jaeger", "", nil)
+	require.Error(t, err)
+
+	exporter, err := NewExporter(context.Background(), ExporterOTLP, "http://localhost:4318/", nil)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Shutdown(context.Background()))
+}
@@ -0,0 +1,50 @@
+// Package tracing sets up the OpenTelemetry tracing of the requests, the transactions and the queries of the bank.
+// The trace context is read from the W3C traceparent header, the spans are exported in batches to stdout or to an OTLP/HTTP collector.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// InstrumentationName is the name of the tracers of the bank
+const InstrumentationName = "simple_bank"
+
+// Exporters of NewExporter
+const (
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// NewExporter returns the exporter of its name: stdout writes the spans to out as JSON,
+// otlp posts them to the collector of the endpoint, like http://localhost:4318
+func NewExporter(ctx context.Context, name string, endpoint string, out io.Writer) (sdktrace.SpanExporter, error) {
+	switch name {
+	case ExporterStdout:
+		return stdouttrace.New(stdouttrace.WithWriter(out))
+	case ExporterOTLP:
+		if endpoint == "" {
+			return nil, fmt.Errorf("the %s exporter needs an endpoint", name)
+		}
+		return otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(strings.TrimSuffix(endpoint, "/")+"/v1/traces"))
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q: must be %s or %s", name, ExporterStdout, ExporterOTLP)
+	}
+}
+
+// NewTracerProvider returns a provider exporting the spans of the service in batches.
+// Shutdown must be called on it to export the last spans.
+func NewTracerProvider(service string, exporter sdktrace.SpanExporter) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", service))),
+	)
+}
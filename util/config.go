@@ -0,0 +1,188 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config stores all configuration of the application.
+// The values are read from the app.env file and can be overridden by environment variables.
+type Config struct {
+	DBDriver            string
+	DBSource            string
+	HTTPServerAddress   string
+	TokenSymmetricKey   string
+	AccessTokenDuration time.Duration
+	// AdminUsernames are the users allowed to call the admin API, ADMIN_USERNAMES is comma separated
+	AdminUsernames []string
+	// InternalAccounts are the accounts of the bank that have no customer but can be debited, INTERNAL_ACCOUNTS is like 1,2,3
+	InternalAccounts []int64
+	// FXAccounts are the bank accounts the currency conversions are booked against, FX_ACCOUNTS is like EUR:1,USD:2
+	FXAccounts map[string]int64
+	// TransferFees is the fee schedule, parsed by db.ParseFeeRules, like EUR:25:50:100:1000, empty charges no fee
+	TransferFees string
+	// FeeRevenueAccounts are the bank accounts receiving the fees, FEE_REVENUE_ACCOUNTS is like EUR:3,USD:4
+	FeeRevenueAccounts map[string]int64
+	// SuspenseAccounts are the counterpart of the balance adjustments, SUSPENSE_ACCOUNTS is like EUR:7,USD:8
+	SuspenseAccounts map[string]int64
+	// InterestExpenseAccounts pay the interest of the savings accounts, INTEREST_EXPENSE_ACCOUNTS is like EUR:5,USD:6
+	InterestExpenseAccounts map[string]int64
+	// TracingExporter is stdout or otlp to export the spans of the requests, transactions and queries, empty disables tracing
+	TracingExporter string
+	// OTLPEndpoint is the OpenTelemetry collector of the otlp exporter, like http://localhost:4318
+	OTLPEndpoint string
+	// LogLevel is debug, info, warn or error
+	LogLevel string
+	// SlowQueryThreshold is the duration above which the queries are logged, zero disables the slow query log
+	SlowQueryThreshold time.Duration
+	// ShutdownTimeout is how long the shutdown waits for the requests and the transactions in flight
+	ShutdownTimeout time.Duration
+}
+
+// LoadConfig reads the app.env file in path, then overrides its values with the environment variables of the same name
+func LoadConfig(path string) (config Config, err error) {
+	values, err := readEnvFile(filepath.Join(path, "app.env"))
+	if err != nil {
+		return
+	}
+
+	get := func(key string) string {
+		if value, ok := os.LookupEnv(key); ok {
+			return value
+		}
+		return values[key]
+	}
+
+	config.DBDriver = get("DB_DRIVER")
+	config.DBSource = get("DB_SOURCE")
+	config.HTTPServerAddress = get("HTTP_SERVER_ADDRESS")
+	config.TokenSymmetricKey = get("TOKEN_SYMMETRIC_KEY")
+	config.AdminUsernames = parseList(get("ADMIN_USERNAMES"))
+	config.TracingExporter = get("TRACING_EXPORTER")
+	config.OTLPEndpoint = get("OTEL_EXPORTER_OTLP_ENDPOINT")
+	config.LogLevel = get("LOG_LEVEL")
+	config.TransferFees = get("TRANSFER_FEES")
+
+	config.InternalAccounts, err = parseIDs("INTERNAL_ACCOUNTS", get("INTERNAL_ACCOUNTS"))
+	if err != nil {
+		return
+	}
+
+	config.FXAccounts, err = parseAccounts("FX_ACCOUNTS", get("FX_ACCOUNTS"))
+	if err != nil {
+		return
+	}
+
+	config.FeeRevenueAccounts, err = parseAccounts("FEE_REVENUE_ACCOUNTS", get("FEE_REVENUE_ACCOUNTS"))
+	if err != nil {
+		return
+	}
+
+	config.SuspenseAccounts, err = parseAccounts("SUSPENSE_ACCOUNTS", get("SUSPENSE_ACCOUNTS"))
+	if err != nil {
+		return
+	}
+
+	config.InterestExpenseAccounts, err = parseAccounts("INTEREST_EXPENSE_ACCOUNTS", get("INTEREST_EXPENSE_ACCOUNTS"))
+	if err != nil {
+		return
+	}
+
+	config.SlowQueryThreshold, err = parseDuration("SLOW_QUERY_THRESHOLD", get("SLOW_QUERY_THRESHOLD"))
+	if err != nil {
+		return
+	}
+
+	config.ShutdownTimeout, err = parseDuration("SHUTDOWN_TIMEOUT", get("SHUTDOWN_TIMEOUT"))
+	if err != nil {
+		return
+	}
+
+	config.AccessTokenDuration, err = parseDuration("ACCESS_TOKEN_DURATION", get("ACCESS_TOKEN_DURATION"))
+	return
+}
+
+// readEnvFile reads KEY=VALUE lines, ignoring blank lines and # comments. A missing file is not an error.
+func readEnvFile(name string) (map[string]string, error) {
+	values := make(map[string]string)
+
+	file, err := os.Open(name)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", name, lineNumber)
+		}
+		values[strings.TrimSpace(key)] = strings.Trim(strings.TrimSpace(value), `"`)
+	}
+
+	return values, scanner.Err()
+}
+
+// parseList splits a comma separated value, dropping the blank items
+func parseList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseAccounts parses a comma separated list of CURRENCY:ACCOUNT_ID
+func parseAccounts(key string, value string) (map[string]int64, error) {
+	accounts := make(map[string]int64)
+	for _, item := range parseList(value) {
+		currency, id, found := strings.Cut(item, ":")
+		accountId, err := strconv.ParseInt(strings.TrimSpace(id), 10, 64)
+		if !found || err != nil {
+			return nil, fmt.Errorf("invalid %s: expected CURRENCY:ACCOUNT_ID, got %q", key, item)
+		}
+		accounts[strings.TrimSpace(currency)] = accountId
+	}
+	return accounts, nil
+}
+
+// parseIDs parses a comma separated list of account IDs
+func parseIDs(key string, value string) ([]int64, error) {
+	var ids []int64
+	for _, item := range parseList(value) {
+		id, err := strconv.ParseInt(item, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: expected ACCOUNT_ID, got %q", key, item)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func parseDuration(key string, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	return duration, nil
+}
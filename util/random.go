@@ -1,45 +1,117 @@
 package util
 
 import (
+	"fmt"
 	"math/rand"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"simple_bank/money"
 )
 
 const alphabet = "abcdefghijklmnopqrstuvwxyz"
 
-// this function will be called automatically when the package is first used
-func init() {
-	seed := time.Now().UnixNano()
-	rand.New(rand.NewSource(seed))
+// SeedEnv is the environment variable seeding the package functions, to replay a run with the seed it printed
+const SeedEnv = "RANDOM_SEED"
+
+// Generator draws random values from its own source, so the same seed always draws the same values.
+// It's safe for concurrent use, the values drawn concurrently then depend on the scheduling.
+type Generator struct {
+	seed  int64
+	mutex sync.Mutex
+	rand  *rand.Rand
 }
 
-func RandomInt(min, max int64) int64 {
-	return min + rand.Int63n(max-min+1)
+// NewGenerator returns a generator seeded with seed
+func NewGenerator(seed int64) *Generator {
+	return &Generator{seed: seed, rand: rand.New(rand.NewSource(seed))}
 }
 
-func RandomString(stringSize int) string {
+// Seed returns the seed of the generator
+func (g *Generator) Seed() int64 {
+	return g.seed
+}
+
+// RandomInt returns a random integer between min and max, both included
+func (g *Generator) RandomInt(min, max int64) int64 {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	return min + g.rand.Int63n(max-min+1)
+}
+
+// RandomString returns a random string of stringSize lowercase letters
+func (g *Generator) RandomString(stringSize int) string {
 	var sb strings.Builder
-	k := len(alphabet)
+	k := int64(len(alphabet))
 
 	for i := 0; i < stringSize; i++ {
-		letter := alphabet[rand.Intn(k)]
+		letter := alphabet[g.RandomInt(0, k-1)]
 		sb.WriteByte(letter)
 	}
 
 	return sb.String()
 }
 
+func (g *Generator) RandomOwner() string {
+	return g.RandomString(6)
+}
+
+func (g *Generator) RandomMoney() int64 {
+	return g.RandomInt(0, 1000)
+}
+
+func (g *Generator) RandomCurrency() string {
+	currencies := money.Codes()
+	n := int64(len(currencies))
+	return currencies[g.RandomInt(0, n-1)]
+}
+
+// defaultGenerator backs the package functions, it's seeded by RANDOM_SEED when it's an integer or else by the time
+var defaultGenerator = NewGenerator(defaultSeed())
+
+func defaultSeed() int64 {
+	if value, ok := os.LookupEnv(SeedEnv); ok {
+		if seed, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return seed
+		}
+	}
+	return time.Now().UnixNano()
+}
+
+// Seed returns the seed of the package functions
+func Seed() int64 {
+	return defaultGenerator.Seed()
+}
+
+func RandomInt(min, max int64) int64 {
+	return defaultGenerator.RandomInt(min, max)
+}
+
+func RandomString(stringSize int) string {
+	return defaultGenerator.RandomString(stringSize)
+}
+
 func RandomOwner() string {
-	return RandomString(6)
+	return defaultGenerator.RandomOwner()
 }
 
 func RandomMoney() int64 {
-	return RandomInt(0, 1000)
+	return defaultGenerator.RandomMoney()
 }
 
 func RandomCurrency() string {
-	currencies := []string{"EUR", "USD", "CAD"}
-	n := len(currencies)
-	return currencies[rand.Intn(n)]
+	return defaultGenerator.RandomCurrency()
+}
+
+// RunTests runs the tests of a TestMain, m is its *testing.M, and returns their exit code. When they fail,
+// it prints the seed of the package functions, so the failed run can be replayed with RANDOM_SEED.
+func RunTests(m interface{ Run() int }) int {
+	code := m.Run()
+	if code != 0 {
+		fmt.Fprintf(os.Stderr, "random seed %d, replay with %s=%d\n", Seed(), SeedEnv, Seed())
+	}
+	return code
 }
@@ -0,0 +1,55 @@
+package util
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"simple_bank/money"
+)
+
+func TestGenerator_SameSeed(t *testing.T) {
+	draw := func(g *Generator) []interface{} {
+		return []interface{}{g.RandomInt(0, 1000), g.RandomString(10), g.RandomOwner(), g.RandomMoney(), g.RandomCurrency()}
+	}
+
+	g := NewGenerator(42)
+	require.Equal(t, int64(42), g.Seed())
+	require.Equal(t, draw(g), draw(NewGenerator(42)))
+	require.NotEqual(t, draw(NewGenerator(42)), draw(NewGenerator(43)))
+}
+
+func TestGenerator_RandomInt(t *testing.T) {
+	g := NewGenerator(1)
+	for i := 0; i < 1000; i++ {
+		n := g.RandomInt(-3, 3)
+		require.GreaterOrEqual(t, n, int64(-3))
+		require.LessOrEqual(t, n, int64(3))
+	}
+	require.Equal(t, int64(5), g.RandomInt(5, 5))
+}
+
+func TestGenerator_RandomString(t *testing.T) {
+	s := NewGenerator(1).RandomString(32)
+	require.Len(t, s, 32)
+	require.Empty(t, strings.Trim(s, alphabet))
+}
+
+func TestGenerator_RandomCurrency(t *testing.T) {
+	g := NewGenerator(1)
+	for i := 0; i < 100; i++ {
+		_, err := money.LookupCurrency(g.RandomCurrency())
+		require.NoError(t, err)
+	}
+}
+
+type runner int
+
+func (r runner) Run() int {
+	return int(r)
+}
+
+func TestRunTests(t *testing.T) {
+	require.Equal(t, 0, RunTests(runner(0)))
+	require.Equal(t, 1, RunTests(runner(1)))
+}
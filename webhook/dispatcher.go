@@ -0,0 +1,354 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	db "simple_bank/db/sqlc"
+)
+
+const (
+	defaultMaxAttempts            = 3
+	defaultBackoff                = time.Second
+	defaultMaxConsecutiveFailures = 5
+	defaultTimeout                = 10 * time.Second
+	defaultClaimTTL               = time.Minute
+	defaultInterval               = time.Second
+	defaultMaxConcurrency         = 16
+)
+
+// Store is the part of db.Store used by the dispatcher
+type Store interface {
+	GetAccount(ctx context.Context, id int64) (db.Account, error)
+	GetOutboxEvent(ctx context.Context, id int64) (db.Outbox, error)
+	GetWebhookSubscription(ctx context.Context, id int64) (db.WebhookSubscription, error)
+	ListActiveWebhookSubscriptions(ctx context.Context, arg db.ListActiveWebhookSubscriptionsParams) ([]db.WebhookSubscription, error)
+	EnqueueWebhookJob(ctx context.Context, arg db.EnqueueWebhookJobParams) error
+	ListDueWebhookSubscriptions(ctx context.Context, arg db.ListDueWebhookSubscriptionsParams) ([]int64, error)
+	ClaimWebhookJob(ctx context.Context, arg db.ClaimWebhookJobParams) (db.WebhookJob, error)
+	RetryWebhookJob(ctx context.Context, arg db.RetryWebhookJobParams) error
+	DeleteWebhookJob(ctx context.Context, id int64) error
+	DeleteWebhookJobsBySubscription(ctx context.Context, subscriptionID int64) error
+	CreateWebhookDelivery(ctx context.Context, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error)
+	RecordWebhookFailure(ctx context.Context, arg db.RecordWebhookFailureParams) (db.WebhookSubscription, error)
+	ResetWebhookFailures(ctx context.Context, id int64) error
+}
+
+// Delivery is the JSON body posted to the subscriptions
+type Delivery struct {
+	ID          int64           `json:"id"`
+	Type        string          `json:"type"`
+	AggregateID int64           `json:"aggregate_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Payload     json.RawMessage `json:"payload"`
+}
+
+// Dispatcher posts outbox events to the webhook subscriptions of the owners of the accounts involved.
+// It implements outbox.Publisher: publishing an event only queues it for every matching subscription,
+// and Run delivers the queues, so a slow or broken endpoint never holds the outbox relay back.
+type Dispatcher struct {
+	store Store
+
+	// Client posts the deliveries, the default one refuses to connect to addresses that aren't public
+	Client *http.Client
+	// MaxAttempts is the number of times a delivery is tried before it counts as a failure of the subscription
+	MaxAttempts int
+	// Backoff is the wait after the first failed attempt, it doubles after every other failed attempt
+	Backoff time.Duration
+	// MaxConsecutiveFailures disables a subscription when that many events in a row failed to be delivered
+	MaxConsecutiveFailures int32
+	// ClaimTTL is how long an attempt can take before another dispatcher retries it, it must be longer than the Client timeout
+	ClaimTTL time.Duration
+	// Interval is how long Run waits before looking for due deliveries again
+	Interval time.Duration
+	// MaxConcurrency is the number of subscriptions Run delivers to at the same time
+	MaxConcurrency int
+	// OnError is called by Run when a delivery fails on a database error, the dispatcher keeps running after it
+	OnError func(err error)
+
+	now func() time.Time
+}
+
+func NewDispatcher(store Store) *Dispatcher {
+	return &Dispatcher{
+		store:                  store,
+		Client:                 newClient(),
+		MaxAttempts:            defaultMaxAttempts,
+		Backoff:                defaultBackoff,
+		MaxConsecutiveFailures: defaultMaxConsecutiveFailures,
+		ClaimTTL:               defaultClaimTTL,
+		Interval:               defaultInterval,
+		MaxConcurrency:         defaultMaxConcurrency,
+		now:                    time.Now,
+	}
+}
+
+// Publish queues the event for every matching subscription, the queue is delivered by Run.
+// Queuing the same event twice is a no-op, so the relay can publish it again after a failure.
+func (dispatcher *Dispatcher) Publish(ctx context.Context, event db.Outbox) error {
+	owners, err := dispatcher.eventOwners(ctx, event)
+	if err != nil {
+		return err
+	}
+
+	for _, owner := range owners {
+		subscriptions, err := dispatcher.store.ListActiveWebhookSubscriptions(ctx, db.ListActiveWebhookSubscriptionsParams{
+			Owner:     owner,
+			EventType: event.EventType,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, subscription := range subscriptions {
+			err = dispatcher.store.EnqueueWebhookJob(ctx, db.EnqueueWebhookJobParams{
+				SubscriptionID: subscription.ID,
+				EventID:        event.ID,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Run delivers the queued events until the context is cancelled. Every subscription with due deliveries is
+// delivered by its own goroutine, one event at a time, so a slow endpoint only delays its own deliveries.
+func (dispatcher *Dispatcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(dispatcher.Interval)
+	defer ticker.Stop()
+
+	var workers sync.WaitGroup
+	defer workers.Wait()
+
+	// busy are the subscriptions being delivered, done receives them once their queue is empty
+	busy := make(map[int64]bool)
+	done := make(chan int64, dispatcher.MaxConcurrency)
+
+	for {
+		if len(busy) < dispatcher.MaxConcurrency {
+			subscriptionIds, err := dispatcher.store.ListDueWebhookSubscriptions(ctx, db.ListDueWebhookSubscriptionsParams{
+				Now:              dispatcher.now(),
+				MaxSubscriptions: int32(dispatcher.MaxConcurrency + len(busy)),
+			})
+			if err != nil {
+				dispatcher.onError(ctx, err)
+			}
+
+			for _, subscriptionId := range subscriptionIds {
+				if busy[subscriptionId] || len(busy) == dispatcher.MaxConcurrency {
+					continue
+				}
+
+				busy[subscriptionId] = true
+				workers.Add(1)
+				go func() {
+					defer workers.Done()
+					dispatcher.onError(ctx, dispatcher.deliverQueue(ctx, subscriptionId))
+					done <- subscriptionId
+				}()
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case subscriptionId := <-done:
+			delete(busy, subscriptionId)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (dispatcher *Dispatcher) onError(ctx context.Context, err error) {
+	if err != nil && ctx.Err() == nil && dispatcher.OnError != nil {
+		dispatcher.OnError(err)
+	}
+}
+
+// deliverQueue attempts the due deliveries of the subscription, in event order, until none is left
+func (dispatcher *Dispatcher) deliverQueue(ctx context.Context, subscriptionId int64) error {
+	for {
+		now := dispatcher.now()
+		job, err := dispatcher.store.ClaimWebhookJob(ctx, db.ClaimWebhookJobParams{
+			ClaimedUntil:   now.Add(dispatcher.ClaimTTL),
+			SubscriptionID: subscriptionId,
+			Now:            now,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err = dispatcher.attempt(ctx, job); err != nil {
+			return err
+		}
+	}
+}
+
+// eventOwners returns the owners of the accounts involved in the event
+func (dispatcher *Dispatcher) eventOwners(ctx context.Context, event db.Outbox) ([]string, error) {
+	var accountIds []int64
+
+	switch event.EventType {
+	case db.EventTransferCreated:
+		var payload db.TransferCreatedPayload
+		if err := json.Unmarshal(event.Payload, &payload); err != nil {
+			return nil, fmt.Errorf("event %d: %w", event.ID, err)
+		}
+		accountIds = []int64{payload.Transfer.FromAccountID, payload.Transfer.ToAccountID}
+	case db.EventAccountCredited, db.EventAccountDebited:
+		accountIds = []int64{event.AggregateID}
+	default:
+		return nil, nil
+	}
+
+	var owners []string
+	seen := make(map[string]bool)
+
+	for _, accountId := range accountIds {
+		account, err := dispatcher.store.GetAccount(ctx, accountId)
+		if err != nil {
+			return nil, fmt.Errorf("event %d: account %d: %w", event.ID, accountId, err)
+		}
+
+		if !seen[account.Owner] {
+			seen[account.Owner] = true
+			owners = append(owners, account.Owner)
+		}
+	}
+
+	return owners, nil
+}
+
+// attempt posts the event of the job and records the attempt. A failed attempt is retried after a backoff,
+// until MaxAttempts, then it counts as a failure of the subscription, which may disable it.
+func (dispatcher *Dispatcher) attempt(ctx context.Context, job db.WebhookJob) error {
+	subscription, err := dispatcher.store.GetWebhookSubscription(ctx, job.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if !subscription.Active {
+		return dispatcher.store.DeleteWebhookJob(ctx, job.ID)
+	}
+
+	event, err := dispatcher.store.GetOutboxEvent(ctx, job.EventID)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(Delivery{
+		ID:          event.ID,
+		Type:        event.EventType,
+		AggregateID: event.AggregateID,
+		CreatedAt:   event.CreatedAt,
+		Payload:     event.Payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	statusCode, postErr := dispatcher.post(ctx, subscription, event, body)
+	if ctx.Err() != nil {
+		// the job is attempted again once its claim expires
+		return ctx.Err()
+	}
+
+	attempt := job.Attempts + 1
+	delivery := db.CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+		Attempt:        attempt,
+		StatusCode:     sql.NullInt32{Int32: int32(statusCode), Valid: statusCode != 0},
+		Succeeded:      postErr == nil,
+	}
+	if postErr != nil {
+		delivery.Error = postErr.Error()
+	}
+
+	if _, err := dispatcher.store.CreateWebhookDelivery(ctx, delivery); err != nil {
+		return err
+	}
+
+	if postErr == nil {
+		if err := dispatcher.store.DeleteWebhookJob(ctx, job.ID); err != nil {
+			return err
+		}
+		if subscription.ConsecutiveFailures > 0 {
+			return dispatcher.store.ResetWebhookFailures(ctx, subscription.ID)
+		}
+		return nil
+	}
+
+	if int(attempt) < dispatcher.MaxAttempts {
+		return dispatcher.store.RetryWebhookJob(ctx, db.RetryWebhookJobParams{
+			Attempts:      attempt,
+			NextAttemptAt: dispatcher.now().Add(dispatcher.Backoff << (attempt - 1)),
+			ID:            job.ID,
+		})
+	}
+
+	if err := dispatcher.store.DeleteWebhookJob(ctx, job.ID); err != nil {
+		return err
+	}
+
+	subscription, err = dispatcher.store.RecordWebhookFailure(ctx, db.RecordWebhookFailureParams{
+		MaxFailures: dispatcher.MaxConsecutiveFailures,
+		ID:          subscription.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// a disabled subscription drops its queue, it starts again from the events after it's enabled
+	if !subscription.Active {
+		return dispatcher.store.DeleteWebhookJobsBySubscription(ctx, subscription.ID)
+	}
+	return nil
+}
+
+// post sends a signed delivery, any non 2xx response is an error
+func (dispatcher *Dispatcher) post(
+	ctx context.Context,
+	subscription db.WebhookSubscription,
+	event db.Outbox,
+	body []byte,
+) (statusCode int, err error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := dispatcher.now().Unix()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEventID, strconv.FormatInt(event.ID, 10))
+	request.Header.Set(HeaderEventType, event.EventType)
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	request.Header.Set(HeaderSignature, Sign(subscription.Secret, timestamp, body))
+
+	response, err := dispatcher.Client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer response.Body.Close()
+	_, _ = io.Copy(io.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return response.StatusCode, fmt.Errorf("unexpected status %s", response.Status)
+	}
+
+	return response.StatusCode, nil
+}
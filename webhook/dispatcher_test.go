@@ -0,0 +1,520 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	db "simple_bank/db/sqlc"
+)
+
+// fakeStore keeps accounts, subscriptions, outbox events, jobs and deliveries in memory
+type fakeStore struct {
+	mu            sync.Mutex
+	accounts      map[int64]db.Account
+	subscriptions map[int64]*db.WebhookSubscription
+	deliveries    []db.WebhookDelivery
+	events        map[int64]db.Outbox
+	jobs          []db.WebhookJob
+	lastJobID     int64
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		accounts:      make(map[int64]db.Account),
+		subscriptions: make(map[int64]*db.WebhookSubscription),
+		events:        make(map[int64]db.Outbox),
+	}
+}
+
+func (store *fakeStore) GetAccount(_ context.Context, id int64) (db.Account, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return store.accounts[id], nil
+}
+
+func (store *fakeStore) CreateWebhookSubscription(_ context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	subscription := db.WebhookSubscription{
+		ID:         int64(len(store.subscriptions) + 1),
+		Owner:      arg.Owner,
+		Url:        arg.Url,
+		Secret:     arg.Secret,
+		EventTypes: arg.EventTypes,
+		Active:     true,
+	}
+	store.subscriptions[subscription.ID] = &subscription
+	return subscription, nil
+}
+
+func (store *fakeStore) ListActiveWebhookSubscriptions(_ context.Context, arg db.ListActiveWebhookSubscriptionsParams) ([]db.WebhookSubscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var subscriptions []db.WebhookSubscription
+	for id := int64(1); id <= int64(len(store.subscriptions)); id++ {
+		subscription := store.subscriptions[id]
+		if subscription.Owner != arg.Owner || !subscription.Active {
+			continue
+		}
+
+		matches := len(subscription.EventTypes) == 0
+		for _, eventType := range subscription.EventTypes {
+			matches = matches || eventType == arg.EventType
+		}
+		if matches {
+			subscriptions = append(subscriptions, *subscription)
+		}
+	}
+	return subscriptions, nil
+}
+
+func (store *fakeStore) CreateWebhookDelivery(_ context.Context, arg db.CreateWebhookDeliveryParams) (db.WebhookDelivery, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	delivery := db.WebhookDelivery{
+		ID:             int64(len(store.deliveries) + 1),
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+		Attempt:        arg.Attempt,
+		StatusCode:     arg.StatusCode,
+		Error:          arg.Error,
+		Succeeded:      arg.Succeeded,
+	}
+	store.deliveries = append(store.deliveries, delivery)
+	return delivery, nil
+}
+
+func (store *fakeStore) RecordWebhookFailure(_ context.Context, arg db.RecordWebhookFailureParams) (db.WebhookSubscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	subscription := store.subscriptions[arg.ID]
+	subscription.ConsecutiveFailures++
+	subscription.Active = subscription.ConsecutiveFailures < arg.MaxFailures
+	return *subscription, nil
+}
+
+func (store *fakeStore) ResetWebhookFailures(_ context.Context, id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.subscriptions[id].ConsecutiveFailures = 0
+	return nil
+}
+
+func (store *fakeStore) GetWebhookSubscription(_ context.Context, id int64) (db.WebhookSubscription, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	subscription, ok := store.subscriptions[id]
+	if !ok {
+		return db.WebhookSubscription{}, sql.ErrNoRows
+	}
+	return *subscription, nil
+}
+
+func (store *fakeStore) GetOutboxEvent(_ context.Context, id int64) (db.Outbox, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	event, ok := store.events[id]
+	if !ok {
+		return db.Outbox{}, sql.ErrNoRows
+	}
+	return event, nil
+}
+
+func (store *fakeStore) EnqueueWebhookJob(_ context.Context, arg db.EnqueueWebhookJobParams) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for _, job := range store.jobs {
+		if job.SubscriptionID == arg.SubscriptionID && job.EventID == arg.EventID {
+			return nil
+		}
+	}
+
+	store.lastJobID++
+	store.jobs = append(store.jobs, db.WebhookJob{
+		ID:             store.lastJobID,
+		SubscriptionID: arg.SubscriptionID,
+		EventID:        arg.EventID,
+	})
+	return nil
+}
+
+func (store *fakeStore) ListDueWebhookSubscriptions(_ context.Context, arg db.ListDueWebhookSubscriptionsParams) ([]int64, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, job := range store.jobs {
+		if store.isDue(job, arg.Now) && store.subscriptions[job.SubscriptionID].Active && !seen[job.SubscriptionID] {
+			seen[job.SubscriptionID] = true
+			ids = append(ids, job.SubscriptionID)
+		}
+	}
+	return ids, nil
+}
+
+func (store *fakeStore) ClaimWebhookJob(_ context.Context, arg db.ClaimWebhookJobParams) (db.WebhookJob, error) {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i, job := range store.jobs {
+		if job.SubscriptionID == arg.SubscriptionID && store.isDue(job, arg.Now) {
+			store.jobs[i].ClaimedUntil = sql.NullTime{Time: arg.ClaimedUntil, Valid: true}
+			return store.jobs[i], nil
+		}
+	}
+	return db.WebhookJob{}, sql.ErrNoRows
+}
+
+func (store *fakeStore) isDue(job db.WebhookJob, now time.Time) bool {
+	return !job.NextAttemptAt.After(now) && (!job.ClaimedUntil.Valid || job.ClaimedUntil.Time.Before(now))
+}
+
+func (store *fakeStore) RetryWebhookJob(_ context.Context, arg db.RetryWebhookJobParams) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	for i, job := range store.jobs {
+		if job.ID == arg.ID {
+			store.jobs[i].Attempts = arg.Attempts
+			store.jobs[i].NextAttemptAt = arg.NextAttemptAt
+			store.jobs[i].ClaimedUntil = sql.NullTime{}
+		}
+	}
+	return nil
+}
+
+func (store *fakeStore) DeleteWebhookJob(_ context.Context, id int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.jobs = slices.DeleteFunc(store.jobs, func(job db.WebhookJob) bool { return job.ID == id })
+	return nil
+}
+
+func (store *fakeStore) DeleteWebhookJobsBySubscription(_ context.Context, subscriptionID int64) error {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.jobs = slices.DeleteFunc(store.jobs, func(job db.WebhookJob) bool { return job.SubscriptionID == subscriptionID })
+	return nil
+}
+
+func (store *fakeStore) pendingJobs() []db.WebhookJob {
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	return slices.Clone(store.jobs)
+}
+
+// creditedEvent adds an AccountCredited event to the outbox of the store
+func creditedEvent(t *testing.T, store *fakeStore, id int64, accountId int64) db.Outbox {
+	payload, err := json.Marshal(db.BalanceChangedPayload{AccountID: accountId, Amount: 10, Balance: 110})
+	require.NoError(t, err)
+
+	event := db.Outbox{
+		ID:          id,
+		EventType:   db.EventAccountCredited,
+		AggregateID: accountId,
+		Payload:     payload,
+		CreatedAt:   time.Now(),
+	}
+
+	store.mu.Lock()
+	store.events[id] = event
+	store.mu.Unlock()
+	return event
+}
+
+// newTestDispatcher retries the failed attempts right away
+func newTestDispatcher(store Store) *Dispatcher {
+	dispatcher := NewDispatcher(store)
+	dispatcher.Backoff = 0
+	dispatcher.MaxAttempts = 3
+	dispatcher.MaxConsecutiveFailures = 2
+	dispatcher.Interval = 10 * time.Millisecond
+	// the test servers listen on the loopback address
+	dispatcher.Client = &http.Client{}
+	return dispatcher
+}
+
+// createTestSubscription subscribes a test server, which CreateSubscription refuses since it isn't public
+func createTestSubscription(t *testing.T, store *fakeStore, url string) (db.WebhookSubscription, error) {
+	secret, err := newSecret()
+	require.NoError(t, err)
+
+	return store.CreateWebhookSubscription(context.Background(), db.CreateWebhookSubscriptionParams{
+		Owner:      "alice",
+		Url:        url,
+		Secret:     secret,
+		EventTypes: []string{},
+	})
+}
+
+func TestDispatcher_PublishQueuesEvent(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	_, err := CreateSubscription(context.Background(), store, "alice", "https://example.com/all", nil)
+	require.NoError(t, err)
+	_, err = CreateSubscription(context.Background(), store, "alice", "https://example.com/debits", []string{db.EventAccountDebited})
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher(store)
+	event := creditedEvent(t, store, 7, 1)
+
+	// the relay may publish an event twice, it's queued once
+	require.NoError(t, dispatcher.Publish(context.Background(), event))
+	require.NoError(t, dispatcher.Publish(context.Background(), event))
+
+	jobs := store.pendingJobs()
+	require.Len(t, jobs, 1)
+	require.Equal(t, int64(1), jobs[0].SubscriptionID)
+	require.Equal(t, event.ID, jobs[0].EventID)
+	require.Empty(t, store.deliveries)
+}
+
+func TestDispatcher_DeliverSignsDelivery(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header, body: body}
+	}))
+	defer server.Close()
+
+	subscription, err := createTestSubscription(t, store, server.URL)
+	require.NoError(t, err)
+	require.NotEmpty(t, subscription.Secret)
+
+	dispatcher := newTestDispatcher(store)
+	event := creditedEvent(t, store, 7, 1)
+	require.NoError(t, dispatcher.Publish(context.Background(), event))
+	require.NoError(t, dispatcher.deliverQueue(context.Background(), subscription.ID))
+
+	request := <-requests
+	require.Equal(t, "7", request.header.Get(HeaderEventID))
+	require.Equal(t, db.EventAccountCredited, request.header.Get(HeaderEventType))
+
+	timestamp, err := strconv.ParseInt(request.header.Get(HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	require.True(t, Verify(subscription.Secret, timestamp, request.body, request.header.Get(HeaderSignature)))
+	require.False(t, Verify("wrong secret", timestamp, request.body, request.header.Get(HeaderSignature)))
+
+	var delivery Delivery
+	require.NoError(t, json.Unmarshal(request.body, &delivery))
+	require.Equal(t, event.ID, delivery.ID)
+	require.Equal(t, event.EventType, delivery.Type)
+	require.JSONEq(t, string(event.Payload), string(delivery.Payload))
+
+	require.Len(t, store.deliveries, 1)
+	require.True(t, store.deliveries[0].Succeeded)
+	require.Equal(t, int32(http.StatusOK), store.deliveries[0].StatusCode.Int32)
+	require.Empty(t, store.pendingJobs())
+}
+
+func TestDispatcher_DeliverRetries(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	subscription, err := createTestSubscription(t, store, server.URL)
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher(store)
+	require.NoError(t, dispatcher.Publish(context.Background(), creditedEvent(t, store, 1, 1)))
+	require.NoError(t, dispatcher.deliverQueue(context.Background(), subscription.ID))
+
+	require.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	require.Len(t, store.deliveries, 3)
+	require.False(t, store.deliveries[0].Succeeded)
+	require.Equal(t, int32(http.StatusServiceUnavailable), store.deliveries[0].StatusCode.Int32)
+	require.NotEmpty(t, store.deliveries[0].Error)
+	require.Equal(t, int32(3), store.deliveries[2].Attempt)
+	require.True(t, store.deliveries[2].Succeeded)
+	require.Zero(t, store.subscriptions[1].ConsecutiveFailures)
+	require.Empty(t, store.pendingJobs())
+}
+
+func TestDispatcher_DeliverBacksOff(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	subscription, err := createTestSubscription(t, store, server.URL)
+	require.NoError(t, err)
+
+	now := time.Now()
+	dispatcher := newTestDispatcher(store)
+	dispatcher.Backoff = time.Minute
+	dispatcher.now = func() time.Time { return now }
+
+	require.NoError(t, dispatcher.Publish(context.Background(), creditedEvent(t, store, 1, 1)))
+	require.NoError(t, dispatcher.deliverQueue(context.Background(), subscription.ID))
+
+	// the failed attempt waits for its backoff instead of blocking the queue
+	jobs := store.pendingJobs()
+	require.Len(t, jobs, 1)
+	require.Equal(t, int32(1), jobs[0].Attempts)
+	require.Equal(t, now.Add(time.Minute), jobs[0].NextAttemptAt)
+	require.False(t, jobs[0].ClaimedUntil.Valid)
+
+	now = now.Add(time.Minute)
+	require.NoError(t, dispatcher.deliverQueue(context.Background(), subscription.ID))
+	jobs = store.pendingJobs()
+	require.Len(t, jobs, 1)
+	require.Equal(t, now.Add(2*time.Minute), jobs[0].NextAttemptAt)
+	require.Len(t, store.deliveries, 2)
+}
+
+func TestDispatcher_DeliverDisablesFailingSubscription(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	subscription, err := createTestSubscription(t, store, server.URL)
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher(store)
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, dispatcher.Publish(context.Background(), creditedEvent(t, store, i, 1)))
+	}
+	require.NoError(t, dispatcher.deliverQueue(context.Background(), subscription.ID))
+
+	// the third event isn't sent, the subscription was disabled after two failed events and its queue dropped
+	require.Equal(t, int32(6), atomic.LoadInt32(&calls))
+	require.False(t, store.subscriptions[1].Active)
+	require.Equal(t, int32(2), store.subscriptions[1].ConsecutiveFailures)
+	require.Empty(t, store.pendingJobs())
+}
+
+func TestDispatcher_RunDoesNotWaitForSlowEndpoints(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	var fastCalls int32
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fastCalls, 1)
+	}))
+	defer fast.Close()
+
+	_, err := createTestSubscription(t, store, slow.URL)
+	require.NoError(t, err)
+	_, err = createTestSubscription(t, store, fast.URL)
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher(store)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- dispatcher.Run(ctx)
+	}()
+
+	for i := int64(1); i <= 3; i++ {
+		require.NoError(t, dispatcher.Publish(context.Background(), creditedEvent(t, store, i, 1)))
+	}
+
+	// the fast endpoint receives every event while the slow one still holds the first
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&fastCalls) == 3
+	}, time.Second, 5*time.Millisecond)
+
+	cancel()
+	require.ErrorIs(t, <-done, context.Canceled)
+}
+
+func TestDispatcher_PublishFiltersEventTypes(t *testing.T) {
+	store := newFakeStore()
+	store.accounts[1] = db.Account{ID: 1, Owner: "alice"}
+
+	_, err := CreateSubscription(context.Background(), store, "alice", "https://example.com/debits", []string{db.EventAccountDebited})
+	require.NoError(t, err)
+
+	dispatcher := newTestDispatcher(store)
+	require.NoError(t, dispatcher.Publish(context.Background(), creditedEvent(t, store, 1, 1)))
+	require.Empty(t, store.pendingJobs())
+}
+
+func TestCreateSubscriptionValidation(t *testing.T) {
+	store := newFakeStore()
+
+	_, err := CreateSubscription(context.Background(), store, "alice", "ftp://example.com", nil)
+	require.ErrorIs(t, err, ErrInvalidURL)
+
+	_, err = CreateSubscription(context.Background(), store, "alice", "/relative", nil)
+	require.ErrorIs(t, err, ErrInvalidURL)
+
+	for _, internal := range []string{
+		"http://example.com/hook",
+		"https://localhost/hook",
+		"https://127.0.0.1/hook",
+		"https://10.0.0.1/hook",
+		"https://[::1]/hook",
+		"https://169.254.169.254/latest/meta-data",
+		"https://100.100.100.200/latest/meta-data",
+	} {
+		_, err = CreateSubscription(context.Background(), store, "alice", internal, nil)
+		require.ErrorIs(t, err, ErrInvalidURL, internal)
+	}
+
+	_, err = CreateSubscription(context.Background(), store, "alice", "https://example.com/hook", []string{"AccountClosed"})
+	require.ErrorIs(t, err, ErrInvalidEventType)
+}
+
+func TestDispatcher_ClientRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	// the url passed the validation, but the host resolves to the loopback address when delivering
+	_, err := NewDispatcher(newFakeStore()).Client.Post(server.URL, "application/json", nil)
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+}
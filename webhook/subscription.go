@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	db "simple_bank/db/sqlc"
+)
+
+var (
+	ErrInvalidURL       = errors.New("webhook url must be an absolute https url with a public host")
+	ErrInvalidEventType = errors.New("unknown webhook event type")
+)
+
+// EventTypes are the event types a subscription can filter on
+var EventTypes = []string{
+	db.EventTransferCreated,
+	db.EventAccountCredited,
+	db.EventAccountDebited,
+}
+
+// SubscriptionStore is the part of db.Store used to manage subscriptions
+type SubscriptionStore interface {
+	CreateWebhookSubscription(ctx context.Context, arg db.CreateWebhookSubscriptionParams) (db.WebhookSubscription, error)
+}
+
+// CreateSubscription validates the url and the event filter, and creates a subscription with a new random secret.
+// An empty eventTypes subscribes to every event type.
+func CreateSubscription(
+	ctx context.Context,
+	store SubscriptionStore,
+	owner string,
+	rawURL string,
+	eventTypes []string,
+) (db.WebhookSubscription, error) {
+	if err := validateURL(rawURL); err != nil {
+		return db.WebhookSubscription{}, err
+	}
+
+	for _, eventType := range eventTypes {
+		if !isEventType(eventType) {
+			return db.WebhookSubscription{}, fmt.Errorf("%w: %q", ErrInvalidEventType, eventType)
+		}
+	}
+
+	secret, err := newSecret()
+	if err != nil {
+		return db.WebhookSubscription{}, err
+	}
+
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+
+	return store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        rawURL,
+		Secret:     secret,
+		EventTypes: eventTypes,
+	})
+}
+
+// validateURL refuses the urls that are obviously internal, the addresses a host name resolves to
+// are checked by the dispatcher on every connection
+func validateURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+
+	host := u.Hostname()
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return fmt.Errorf("%w: %q", ErrInvalidURL, rawURL)
+	}
+	return nil
+}
+
+func isEventType(eventType string) bool {
+	for _, known := range EventTypes {
+		if eventType == known {
+			return true
+		}
+	}
+	return false
+}
+
+func newSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}